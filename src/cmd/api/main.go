@@ -24,30 +24,41 @@ import (
 	"github.com/nburunova/taxi-backend-sample/src/regionsinfo"
 	"github.com/nburunova/taxi-backend-sample/src/taxi"
 	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/taxipb"
 	"github.com/nburunova/taxi-backend-sample/src/webapi"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/robfig/cron"
+	"google.golang.org/grpc"
 
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 )
 
 var (
-	errEmptySettings = errors.New("Empty settings json")
 	errReloadDB      = errors.New("Cannot reload products from DB")
 	errReloadRegions = errors.New("Cannot reload regions list")
+	errServiceNotOK  = errors.New("Taxi service has no providers or products")
 	idleConnTimeout  = 5 * time.Second
 )
 
+const (
+	serveCmd           = "serve"
+	checkConfigCmd     = "check-config"
+	checkConfigTimeout = 5 * time.Second
+)
+
 func main() {
 	ctx := context.Background()
 	cfg := parseFlags()
 	logger := log.New(cfg.log.format, cfg.log.level, os.Stdout)
+	if cfg.command == checkConfigCmd {
+		os.Exit(checkConfig(ctx, cfg, logger))
+	}
 	settings, errSett := initSettings(cfg.settings, logger)
 	if errSett != nil {
 		logger.Fatal(errors.Wrap(errSett, "Cannot read settings.json"))
 	}
-	logger.Infof("started with config: %+v %+v", cfg, settings)
+	logger.Infof("started with config: %v", settings.Summary())
 	connStr := fmt.Sprintf("postgres://%v:%v@%v?sslmode=disable", cfg.db.login, cfg.db.password, cfg.db.url)
 	db, errDB := initDatabase(connStr, logger)
 	if errDB != nil {
@@ -66,12 +77,21 @@ func main() {
 	}
 	statCollector.UpdateCacheReload()
 
+	apis, errAPIs := settings.Providers.APIGetters()
+	if errAPIs != nil {
+		logger.Fatal(errors.Wrap(errAPIs, "Cannot init taxi providers"))
+	}
+	logger.Infof("taxi providers: %v", apiNames(apis))
+
+	// Пул простаивающих соединений ограничиваем только на хост: у каждого провайдера свой хост, поэтому провайдеры,
+	// добавленные при перечитывании настроек, получают такой же пул. В режиме моков все провайдеры ходят на один хост,
+	// и его пул считается по провайдерам на старте; перечитывание настроек его не меняет
 	idleConnPerHost := cfg.http.maxIdleConnectionsPerHost
-	idleConn := idleConnPerHost * len(settings.Providers.APIGetters())
+	idleConn := 0
 
 	if cfg.mock.enabled {
-		idleConnPerHost = cfg.http.maxIdleConnectionsPerHost * len(settings.Providers.APIGetters())
-		idleConn = cfg.http.maxIdleConnectionsPerHost * len(settings.Providers.APIGetters())
+		idleConnPerHost = cfg.http.maxIdleConnectionsPerHost * len(apis)
+		idleConn = cfg.http.maxIdleConnectionsPerHost * len(apis)
 	}
 
 	transport := &http.Transport{
@@ -101,7 +121,6 @@ func main() {
 	if errWebAPI != nil {
 		logger.Fatal(errors.Wrap(errWebAPI, "Cannot creat webAPI client"))
 	}
-	apis := settings.Providers.APIGetters()
 
 	regInfo := regionsinfo.NewRegionsInfo()
 	newRegList, errWebAPIRegList := webAPIclient.GetRegionsList(ctx)
@@ -122,6 +141,7 @@ func main() {
 	if errAddrServ != nil {
 		logger.Fatal(errors.Wrap(errAddrServ, "Cannot init WebAPI client"))
 	}
+	addrSrv = addrSrv.WithCache(settings.AddressCache, statCollector)
 
 	c := cron.New()
 	c.AddFunc(settings.ReloadDBSchedule, func() {
@@ -148,18 +168,38 @@ func main() {
 	c.Start()
 	defer c.Stop()
 
-	tService := service.NewBuilder().
-		WithAPIs(apis).
-		WithProductCache(dbCache).
-		WithRequester(requester).
-		WithDistanceTimeSrv(distTimeSrv).
-		WithAddressSrv(addrSrv).
-		WithStatCollector(statCollector).
-		WithLogger(logger).
-		Build()
+	deps := taxiDeps{
+		prodCache:   dbCache,
+		requester:   requester,
+		distTimeSrv: distTimeSrv,
+		addrSrv:     addrSrv,
+		regions:     regInfo,
+		collector:   statCollector,
+		logger:      logger,
+		// и в режиме моков: пул к общему хосту там больше ровно в число провайдеров раз
+		idleConnPerHost: cfg.http.maxIdleConnectionsPerHost,
+	}
+	srv, errSrv := buildService(settings, deps)
+	if errSrv != nil {
+		logger.Fatal(errors.Wrap(errSrv, "Cannot init taxi service"))
+	}
+	tService := service.NewReloadable(srv)
+	handlerCfg := taxi.NewHandlerConfig(handlerSettings(settings, cfg.debugErrors))
+	statCollector.UpdateSettingsReload()
+	reload := func() error {
+		errReload := reloadSettings(cfg.settings, deps, tService, handlerCfg)
+		if errReload != nil {
+			statCollector.UpdateSettingsReloadFailure()
+			return errReload
+		}
+		statCollector.UpdateSettingsReload()
+		return nil
+	}
 
 	taxiRouter := api.NewTaxiRouter(logger)
-	taxiRouter.Post("/calculate", taxi.SomeHandler(tService, settings.PriceCoeff, settings.RegPriceCoeff, settings.WaitTime, taxi.Handler))
+	taxiRouter.Post("/calculate", taxi.ReloadableHandler(tService, handlerCfg, taxi.Handler))
+	taxiRouter.Post("/calculate/stream", taxi.ReloadableHandler(tService, handlerCfg, taxi.StreamHandler))
+	taxiRouter.Post("/calculate/batch", taxi.ReloadableHandler(tService, handlerCfg, taxi.BatchHandler))
 
 	r := api.NewCommonRouter(logger)
 	r.Mount("/taksa/api/1.0/route", taxiRouter)
@@ -168,24 +208,40 @@ func main() {
 			w.Write([]byte("OK"))
 		}
 	})
-	info, _ := json.MarshalIndent(map[string]string{
-		"mock enabled":    strconv.FormatBool(cfg.mock.enabled),
-		"idleConnPerHost": strconv.Itoa(idleConnPerHost),
-		"idleConn":        strconv.Itoa(idleConn),
-		"ideConnTimeout":  idleConnTimeout.String(),
-	}, "", " ")
 	r.Get("/info", func(w http.ResponseWriter, r *http.Request) {
+		info, _ := json.MarshalIndent(map[string]interface{}{
+			"mock enabled":     strconv.FormatBool(cfg.mock.enabled),
+			"idleConnPerHost":  strconv.Itoa(idleConnPerHost),
+			"idleConn":         strconv.Itoa(idleConn),
+			"ideConnTimeout":   idleConnTimeout.String(),
+			"circuit breakers": tService.BreakerStates(),
+		}, "", " ")
 		w.Write(info)
 	})
+	r.Get("/openapi.json", taxi.OpenAPIHandler)
 	r.Handle("/metrics", promhttp.Handler())
 
-	srv := api.NewServer(cfg.http.address, r)
+	httpSrv := api.NewServer(cfg.http.address, r)
+	grpcSrv := api.NewGRPCServer(cfg.grpc.address, func(s *grpc.Server) {
+		taxipb.RegisterTaxiServer(s, taxi.NewGRPCHandler(tService, handlerCfg))
+	})
+
+	signals.BindSignals(logger, httpSrv, grpcSrv)
+	signals.BindReload(logger, reload)
+	if cfg.watchPeriod > 0 {
+		watchSettings(cfg.settings, cfg.watchPeriod, reload, logger)
+	}
 
-	signals.BindSignals(logger, srv)
+	go func() {
+		logger.Infof("grpc listening on %s", cfg.grpc.address)
+		if err := grpcSrv.Start(); err != nil {
+			logger.WithError(err).Fatal()
+		}
+	}()
 
 	logger.Info("starting http service...")
 	logger.Infof("listening on %s", cfg.http.address)
-	if err := srv.Start(); err != nil {
+	if err := httpSrv.Start(); err != nil {
 		logger.WithError(err).Fatal()
 	}
 }
@@ -201,6 +257,144 @@ func initDatabase(dsn string, logger log.Logger) (*database.Database, error) {
 	return db, nil
 }
 
+func apiNames(apis []service.APIDataGetter) []string {
+	names := make([]string, len(apis))
+	for i, api := range apis {
+		names[i] = api.APIName()
+	}
+	return names
+}
+
+// taxiDeps - зависимости сервиса Таксы, которые не меняются при перечитывании настроек
+type taxiDeps struct {
+	prodCache   service.ProductsCache
+	requester   *httprequester.Requester
+	distTimeSrv service.DistanceTimeService
+	addrSrv     service.AddressService
+	regions     *regionsinfo.RegionsInfo
+	collector   *collector.Collector
+	logger      *log.StructuredLogger
+	// idleConnPerHost - простаивающих соединений к хосту провайдера в пуле, по умолчанию это лимит
+	// одновременных запросов пакетов к одному провайдеру
+	idleConnPerHost int
+}
+
+// buildService - проверяем настройки и создаем по ним сервис Таксы
+func buildService(s settings.Settings, deps taxiDeps) (*service.Service, error) {
+	if errValidate := s.Validate(deps.regions); errValidate != nil {
+		return nil, errors.Wrap(errValidate, "Invalid settings")
+	}
+	apis, errAPIs := s.Providers.APIGetters()
+	if errAPIs != nil {
+		return nil, errors.Wrap(errAPIs, "Cannot init taxi providers")
+	}
+	policies, errPolicies := s.Providers.RequestPolicies()
+	if errPolicies != nil {
+		return nil, errors.Wrap(errPolicies, "Cannot read providers request policies")
+	}
+	srv := service.NewBuilder().
+		WithAPIs(apis).
+		WithProductCache(deps.prodCache).
+		WithRequester(deps.requester).
+		WithDistanceTimeSrv(deps.distTimeSrv).
+		WithAddressSrv(deps.addrSrv).
+		WithStatCollector(deps.collector).
+		WithLogger(deps.logger).
+		WithBreakerSettings(s.Breaker).
+		WithRequestPolicies(policies).
+		WithQuoteCache(s.QuoteCache).
+		WithRouteSettings(s.Route).
+		WithAddressPlaceholder(s.AddressPlaceholder).
+		WithMaxSurge(s.OptimalMaxSurge).
+		WithRanking(s.Ranking).
+		WithExperiment(s.Experiment).
+		WithBatchSettings(s.Batch.WithDefaults(deps.idleConnPerHost)).
+		Build()
+	if srv == nil {
+		return nil, errServiceNotOK
+	}
+	return srv, nil
+}
+
+// handlerSettings - настройки хендлеров; debugErrors задается флагом командной строки, а не settings.json
+func handlerSettings(s settings.Settings, debugErrors bool) taxi.HandlerSettings {
+	return taxi.HandlerSettings{
+		PriceCoeff:    s.PriceCoeff,
+		RegPriceCoeff: s.RegPriceCoeff,
+		WaitTime:      s.WaitTime.Duration(),
+		DebugErrors:   debugErrors,
+	}
+}
+
+// reloadSettings - перечитываем настройки и заменяем сервис Таксы и настройки хендлеров.
+// Если настройки не прочитались или не прошли проверку, остаются прежние.
+// Строка подключения к БД, расписания cron и кэш адресов перечитыванием не меняются.
+// Состояние сервиса, настройки которого не изменились, переносится в новый сервис (см. service.Reloadable.Replace)
+func reloadSettings(confPath string, deps taxiDeps, tService *service.Reloadable, handlerCfg *taxi.HandlerConfig) error {
+	s, errSett := initSettings(confPath, deps.logger)
+	if errSett != nil {
+		return errors.Wrap(errSett, "Cannot read settings.json")
+	}
+	srv, errSrv := buildService(s, deps)
+	if errSrv != nil {
+		return errSrv
+	}
+	tService.Replace(srv)
+	handlerCfg.Store(handlerSettings(s, handlerCfg.Load().DebugErrors))
+	deps.logger.Infof("reloaded config: %v", s.Summary())
+	return nil
+}
+
+// checkConfig - проверяем settings.json без подключения к БД и выводим все найденные проблемы.
+// Коды регионов сверяем со списком WebAPI, а если он недоступен - только проверяем на положительность
+func checkConfig(ctx context.Context, cfg *cliFlags, logger *log.StructuredLogger) int {
+	s, errSett := initSettings(cfg.settings, logger)
+	if errSett != nil {
+		fmt.Fprintln(os.Stderr, errors.Wrap(errSett, "Cannot read settings.json"))
+		return 1
+	}
+	var regions settings.RegionChecker
+	regInfo, errRegions := loadRegions(ctx, logger)
+	if errRegions != nil {
+		logger.Warning(errors.Wrap(errRegions, "Region ids are not checked against WebAPI"))
+	} else {
+		regions = regInfo
+	}
+	if errValidate := s.Validate(regions); errValidate != nil {
+		fmt.Fprintf(os.Stderr, "%v: invalid settings:%v\n", cfg.settings, errValidate)
+		return 1
+	}
+	fmt.Printf("%v: OK\n", cfg.settings)
+	return 0
+}
+
+// loadRegions - список регионов WebAPI для проверки настроек
+func loadRegions(ctx context.Context, logger *log.StructuredLogger) (*regionsinfo.RegionsInfo, error) {
+	requester := httprequester.NewRequester(&http.Client{Timeout: checkConfigTimeout}, logger, collector.NewCollector(logger.Logger))
+	webAPIclient, errWebAPI := webapi.NewClient(requester)
+	if errWebAPI != nil {
+		return nil, errWebAPI
+	}
+	regList, errRegList := webAPIclient.GetRegionsList(ctx)
+	if errRegList != nil {
+		return nil, errRegList
+	}
+	regInfo := regionsinfo.NewRegionsInfo()
+	return regInfo, regInfo.Load(regList)
+}
+
+// watchSettings - перечитываем настройки, когда меняется файл
+func watchSettings(confPath string, interval time.Duration, reload func() error, logger log.Logger) {
+	settings.Watch(confPath, interval, func() {
+		logger.Info("Settings file changed. Reloading settings...")
+		if errReload := reload(); errReload != nil {
+			logger.WithError(errReload).Error("Settings are not reloaded, keep the previous ones")
+		}
+	})
+}
+
+// initSettings - читаем settings.json. Значения не проверяются: это делает Settings.Validate,
+// чтобы все проблемы показывались вместе
 func initSettings(confPath string, logger log.Logger) (settings.Settings, error) {
 	var s settings.Settings
 	file, errIO := ioutil.ReadFile(confPath)
@@ -211,10 +405,6 @@ func initSettings(confPath string, logger log.Logger) (settings.Settings, error)
 	if errReadConf != nil {
 		return s, errors.Wrap(errReadConf, "Cannot parse settings json")
 	}
-	if s.IsEmply() {
-		return s, errors.Wrap(errEmptySettings, "Empty settings json")
-	}
-	s.WaitTime = time.Duration(time.Duration(s.WaitTime) * time.Millisecond)
 	return s, nil
 }
 
@@ -228,6 +418,10 @@ type httpFlags struct {
 	maxIdleConnectionsPerHost int
 }
 
+type grpcFlags struct {
+	address string
+}
+
 type mockFlags struct {
 	enabled bool
 	params  map[string]string
@@ -241,12 +435,16 @@ type dbParams struct {
 
 // cliFlags is a union of the fields, which application could parse from CLI args
 type cliFlags struct {
-	log      logFlags
-	http     httpFlags
-	mock     mockFlags
-	db       dbParams
-	useCache bool
-	settings string
+	log         logFlags
+	http        httpFlags
+	grpc        grpcFlags
+	mock        mockFlags
+	db          dbParams
+	useCache    bool
+	settings    string
+	watchPeriod time.Duration
+	debugErrors bool
+	command     string
 }
 
 // parseFlags maps CLI flags to struct
@@ -292,6 +490,11 @@ func parseFlags() *cliFlags {
 		Envar("ADDRESS").
 		StringVar(&cfg.http.address)
 
+	kingpin.Flag("grpc-address", "gRPC service address:port.").
+		Default("0.0.0.0:5001").
+		Envar("GRPC_ADDRESS").
+		StringVar(&cfg.grpc.address)
+
 	kingpin.Flag("maxIdleConnectionsPerHost", "maxIdleConnectionsPerHost").
 		Default("10").
 		Envar("MAXIDLECONNECTIONSPERHOST").
@@ -307,6 +510,19 @@ func parseFlags() *cliFlags {
 		Envar("SETTINGS").
 		StringVar(&cfg.settings)
 
-	kingpin.Parse()
+	kingpin.Flag("settings-watch", "Reload settings json when it changes, check period; 0 - reload on SIGHUP only").
+		Default("0s").
+		Envar("SETTINGS_WATCH").
+		DurationVar(&cfg.watchPeriod)
+
+	kingpin.Flag("debug-errors", "Include internal error text in API error responses").
+		Default("false").
+		Envar("DEBUG_ERRORS").
+		BoolVar(&cfg.debugErrors)
+
+	kingpin.Command(serveCmd, "Run the HTTP and gRPC services.").Default()
+	kingpin.Command(checkConfigCmd, "Validate the settings json and exit; does not connect to the DB.")
+
+	cfg.command = kingpin.Parse()
 	return &cfg
 }
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// GRPCServer - gRPC сервер, запускается и останавливается как Server
+type GRPCServer struct {
+	addr   string
+	server *grpc.Server
+}
+
+// NewGRPCServer инициализация gRPC сервера; register регистрирует на нем сервисы
+func NewGRPCServer(addr string, register func(s *grpc.Server)) *GRPCServer {
+	s := grpc.NewServer()
+	register(s)
+	return &GRPCServer{addr: addr, server: s}
+}
+
+// Start taxa gRPC сервера
+func (s *GRPCServer) Start() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return errors.Wrap(err, "Taxa gRPC server error")
+	}
+	if err := s.server.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+		return errors.Wrap(err, "Taxa gRPC server error")
+	}
+	return nil
+}
+
+// Stop taxa gRPC сервера c плавным завершением всех входящих запросов
+func (s *GRPCServer) Stop() error {
+	s.server.GracefulStop()
+	return nil
+}
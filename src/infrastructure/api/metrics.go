@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	requestsName = "chi_requests_total"
+	latencyName  = "chi_request_duration_milliseconds"
+	// defaultMode - режим запроса, если хендлер не указал другой
+	defaultMode = "full"
+)
+
+// ctxKeyMode - ключ контекста для режима запроса
+type ctxKeyMode struct{}
+
+// metricsMiddleware - то же, что chiprometheus, но с меткой mode: хендлер узнает режим только из тела запроса
+// и сообщает его через SetRequestMode
+type metricsMiddleware struct {
+	reqs    *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewMetricsMiddleware - middleware с метриками количества и времени обработки запросов
+// по коду ответа, методу, пути и режиму запроса
+func NewMetricsMiddleware(name string, buckets ...float64) func(next http.Handler) http.Handler {
+	var m metricsMiddleware
+	m.reqs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        requestsName,
+			Help:        "How many HTTP requests processed, partitioned by status code, method, HTTP path and request mode.",
+			ConstLabels: prometheus.Labels{"service": name},
+		},
+		[]string{"code", "method", "path", "mode"},
+	)
+	prometheus.MustRegister(m.reqs)
+	m.latency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        latencyName,
+		Help:        "How long it took to process the request, partitioned by status code, method, HTTP path and request mode.",
+		ConstLabels: prometheus.Labels{"service": name},
+		Buckets:     buckets,
+	},
+		[]string{"code", "method", "path", "mode"},
+	)
+	prometheus.MustRegister(m.latency)
+	return m.handler
+}
+
+func (m metricsMiddleware) handler(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		mode := defaultMode
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), ctxKeyMode{}, &mode)))
+		status := http.StatusText(ww.Status())
+		m.reqs.WithLabelValues(status, r.Method, r.URL.Path, mode).Inc()
+		m.latency.WithLabelValues(status, r.Method, r.URL.Path, mode).Observe(float64(time.Since(start).Nanoseconds()) / 1000000)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// SetRequestMode - режим запроса для метрик middleware. Вне NewMetricsMiddleware ничего не делает
+func SetRequestMode(ctx context.Context, mode string) {
+	if holder, ok := ctx.Value(ctxKeyMode{}).(*string); ok {
+		*holder = mode
+	}
+}
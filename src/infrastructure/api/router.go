@@ -1,7 +1,6 @@
 package api
 
 import (
-	chiprometheus "github.com/766b/chi-prometheus"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
@@ -15,7 +14,7 @@ type Router struct {
 // NewTaxiRouter возвращает роутер со всеми middleware
 func NewTaxiRouter(logger *log.StructuredLogger) *Router {
 	router := &Router{chi.NewRouter()}
-	router.Use(chiprometheus.NewMiddleware("taxa", 500, 1000, 2000, 4000))
+	router.Use(NewMetricsMiddleware("taxa", 500, 1000, 2000, 4000))
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RequestLogger(logger))
 	router.Use(middleware.Recoverer)
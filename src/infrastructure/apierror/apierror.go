@@ -0,0 +1,144 @@
+package apierror
+
+import (
+	"fmt"
+)
+
+// Kind - вид ошибки запроса к внешнему API
+type Kind string
+
+const (
+	// KindUnknown - ошибка без типа
+	KindUnknown Kind = "unknown"
+	// KindRequest - не смогли собрать запрос
+	KindRequest Kind = "request"
+	// KindTimeout - запрос прерван по дедлайну или отменен
+	KindTimeout Kind = "timeout"
+	// KindTransport - не смогли выполнить запрос: соединение, DNS, TLS
+	KindTransport Kind = "transport"
+	// KindRead - не смогли прочитать тело ответа
+	KindRead Kind = "read"
+	// KindStatus - код ответа не 200
+	KindStatus Kind = "status"
+	// KindParse - не смогли разобрать ответ
+	KindParse Kind = "parse"
+	// KindInvalidPrice - в ответе нет цен или они невалидны
+	KindInvalidPrice Kind = "invalid_price"
+	// KindInvalidTime - в ответе нет времени подачи или оно невалидно
+	KindInvalidTime Kind = "invalid_time"
+)
+
+// Error - ошибка запроса к внешнему API: кто, на каком этапе, что случилось и имеет ли смысл повторять.
+// Текст и Cause - как у исходной ошибки, поэтому errors.Cause и проверки sentinel-ошибок продолжают работать
+type Error struct {
+	// Provider - имя провайдера или сервиса
+	Provider string
+	// Stage - этап запроса: метод API провайдера или шаг обработки ответа (price, time)
+	Stage string
+	// StatusCode - код ответа, если ответ был
+	StatusCode int
+	Kind       Kind
+	// Retryable - повтор запроса может помочь
+	Retryable bool
+	Err       error
+}
+
+// New - ошибка вида kind на этапе stage запроса к провайдеру
+func New(provider, stage string, kind Kind, err error) *Error {
+	return &Error{
+		Provider: provider,
+		Stage:    stage,
+		Kind:     kind,
+		Err:      err,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%v %v: %v", e.Provider, e.Stage, e.Kind)
+	}
+	return e.Err.Error()
+}
+
+// Cause - исходная ошибка, для errors.Cause
+func (e *Error) Cause() error {
+	return e.Err
+}
+
+type causer interface {
+	Cause() error
+}
+
+// multi - ошибка, которая объединяет несколько ошибок (errorswrapper.MultiError)
+type multi interface {
+	Errors() []error
+}
+
+// All - все типизированные ошибки внутри err: по цепочке Cause и внутри составных ошибок, в порядке обхода
+func All(err error) []*Error {
+	var found []*Error
+	walk(err, func(e *Error) {
+		found = append(found, e)
+	})
+	return found
+}
+
+// As - первая типизированная ошибка внутри err
+func As(err error) (*Error, bool) {
+	all := All(err)
+	if len(all) == 0 {
+		return nil, false
+	}
+	return all[0], true
+}
+
+// KindOf - вид первой типизированной ошибки внутри err, KindUnknown - если таких нет
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return KindUnknown
+}
+
+// HasKind - есть ли внутри err ошибка вида kind
+func HasKind(err error, kind Kind) bool {
+	for _, e := range All(err) {
+		if e.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryable - все типизированные ошибки внутри err можно повторить; ошибки без типа повторять не стоит
+func IsRetryable(err error) bool {
+	all := All(err)
+	if len(all) == 0 {
+		return false
+	}
+	for _, e := range all {
+		if !e.Retryable {
+			return false
+		}
+	}
+	return true
+}
+
+func walk(err error, visit func(*Error)) {
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			visit(e)
+		}
+		if m, ok := err.(multi); ok {
+			for _, inner := range m.Errors() {
+				walk(inner, visit)
+			}
+			return
+		}
+		c, ok := err.(causer)
+		if !ok {
+			return
+		}
+		err = c.Cause()
+	}
+}
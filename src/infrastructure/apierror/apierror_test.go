@@ -0,0 +1,47 @@
+package apierror
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/errorswrapper"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = errors.New("Test error")
+
+func TestWrappedError(t *testing.T) {
+	apiErr := &Error{Provider: "uber", Stage: "/v1.2/estimates/price", StatusCode: http.StatusBadGateway, Kind: KindStatus, Retryable: true, Err: errors.Wrap(errTest, "502")}
+	err := errors.Wrap(errors.Wrap(apiErr, "Uber: Cannot request prices"), "uber")
+
+	found, ok := As(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, apiErr, found)
+		assert.Equal(t, http.StatusBadGateway, found.StatusCode)
+	}
+	assert.Equal(t, errTest, errors.Cause(err), "sentinel is still reachable through the typed error")
+	assert.Equal(t, KindStatus, KindOf(err))
+	assert.True(t, HasKind(err, KindStatus))
+	assert.False(t, HasKind(err, KindTimeout))
+	assert.True(t, IsRetryable(err))
+	assert.Contains(t, err.Error(), "502: Test error")
+
+	_, ok = As(errors.Wrap(errTest, "plain"))
+	assert.False(t, ok)
+	assert.Equal(t, KindUnknown, KindOf(errTest))
+	assert.False(t, IsRetryable(errTest))
+	assert.Equal(t, 0, len(All(nil)))
+}
+
+func TestMultiError(t *testing.T) {
+	timeout := &Error{Provider: "gett", Stage: "/v1/availability/price", Kind: KindTimeout, Retryable: true, Err: errTest}
+	price := New("gett", "price", KindInvalidPrice, errTest)
+	err := errors.Wrap(errorswrapper.Append(errors.Wrap(price, "Gett: Cannot request prices"), errors.Wrap(timeout, "Gett: Cannot request eta")), "gett")
+
+	assert.Equal(t, []*Error{price, timeout}, All(err))
+	assert.Equal(t, KindInvalidPrice, KindOf(err))
+	assert.True(t, HasKind(err, KindTimeout), "timeout of the second request is not lost")
+	assert.False(t, IsRetryable(err), "invalid price is not retryable")
+	assert.True(t, IsRetryable(errorswrapper.Append(timeout, timeout)))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
 )
 
@@ -28,15 +29,39 @@ type Collector struct {
 	providerRespTime *prometheus.HistogramVec
 	// Количество запросов, отвалившихся по таймауту
 	timeout *prometheus.CounterVec
+	// Количество неудачных запросов к провайдерам по виду ошибки и этапу
+	providerFailure *prometheus.CounterVec
+	// Количество повторных запросов к провайдерам
+	providerRetry *prometheus.CounterVec
+	// Количество подстраховочных (hedged) запросов к провайдерам
+	providerHedge *prometheus.CounterVec
 	// Количество ошибочных ответов от сервиса - нет данных от провайдера или от Моисея \ вебапи
 	serviceError *prometheus.CounterVec
 	// Количество случаев, когда отфильтровали все результаты из ответа провайдера (не подошел тариф)
 	filterError *prometheus.CounterVec
+	// Состояние circuit breaker провайдера: 0 - закрыт, 1 - полуоткрыт, 2 - открыт
+	providerBreakerState *prometheus.GaugeVec
+	// Количество попаданий в кэш ответов провайдеров
+	quoteCacheHit *prometheus.CounterVec
+	// Количество промахов кэша ответов провайдеров
+	quoteCacheMiss *prometheus.CounterVec
+	// Количество вытеснений из кэша ответов провайдеров: по TTL или из-за размера
+	quoteCacheEviction *prometheus.CounterVec
+	// Количество обращений к кэшу адресов: hit, negative_hit, miss
+	addressCacheLookup *prometheus.CounterVec
+	// Доля попаданий в кэш адресов
+	addressCacheHitRatio prometheus.Gauge
+	// Количество запросов, попавших в вариант эксперимента
+	experimentRequest *prometheus.CounterVec
 	// Последнее успешное обновление кэша
 	cacheReload prometheus.Gauge
 	// Последнее успешное обновление кодов регионов
 	regionsReload prometheus.Gauge
-	logger        log.Logger
+	// Последнее успешное перечитывание настроек
+	settingsReload prometheus.Gauge
+	// Последняя неудачная попытка перечитать настройки
+	settingsReloadFailure prometheus.Gauge
+	logger                log.Logger
 }
 
 // NewCollector - создать собиратель информации о прометее
@@ -79,18 +104,79 @@ func NewCollector(logger log.Logger) *Collector {
 				Name:      "request_timeout",
 				Help:      "Количество запросов, прервавшихся по таймауту",
 			}, []string{"name", "region"}),
+		providerRetry: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: "navi_taxa_providers",
+				Name:      "retry",
+				Help:      "Количество повторных запросов к провайдерам",
+			}, []string{"name", "region"}),
+		providerFailure: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: "navi_taxa_providers",
+				Name:      "failure",
+				Help:      "Количество неудачных запросов к провайдерам по виду ошибки и этапу",
+			}, []string{"name", "kind", "stage", "retryable", "region"}),
+		providerHedge: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: "navi_taxa_providers",
+				Name:      "hedge",
+				Help:      "Количество подстраховочных запросов к провайдерам",
+			}, []string{"name", "region"}),
 		serviceError: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Subsystem: "navi_taxa_service",
 				Name:      "error_response",
 				Help:      "Количество плохих ответов от сервиса",
-			}, []string{"cause", "region"}),
+			}, []string{"cause", "mode", "region"}),
 		filterError: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Subsystem: "navi_taxa_service",
 				Name:      "filter_empty",
 				Help:      "Количество случаев, когда отфильтровали все результаты из ответа провайдера (не подошел тариф)",
 			}, []string{"name", "region"}),
+		providerBreakerState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Subsystem: "navi_taxa_providers",
+				Name:      "breaker_state",
+				Help:      "Состояние circuit breaker провайдера: 0 - закрыт, 1 - полуоткрыт, 2 - открыт",
+			}, []string{"name"}),
+		quoteCacheHit: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: "navi_taxa_service",
+				Name:      "quote_cache_hit",
+				Help:      "Количество попаданий в кэш ответов провайдеров",
+			}, []string{"name"}),
+		quoteCacheMiss: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: "navi_taxa_service",
+				Name:      "quote_cache_miss",
+				Help:      "Количество промахов кэша ответов провайдеров",
+			}, []string{"name"}),
+		quoteCacheEviction: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: "navi_taxa_service",
+				Name:      "quote_cache_eviction",
+				Help:      "Количество вытеснений из кэша ответов провайдеров",
+			}, []string{"name", "cause"}),
+		addressCacheLookup: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: "navi_taxa_service",
+				Name:      "address_cache_lookup",
+				Help:      "Количество обращений к кэшу адресов",
+			}, []string{"result"}),
+		experimentRequest: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Subsystem: "navi_taxa_service",
+				Name:      "experiment_request",
+				Help:      "Количество запросов, попавших в вариант эксперимента",
+			}, []string{"experiment", "variant", "region"}),
+		addressCacheHitRatio: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Subsystem: "navi_taxa_service",
+				Name:      "address_cache_hit_ratio",
+				Help:      "Доля попаданий в кэш адресов",
+			},
+		),
 		cacheReload: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Subsystem: "navi_taxa_service",
@@ -105,6 +191,20 @@ func NewCollector(logger log.Logger) *Collector {
 				Help:      "Дата последнего обновления списка кодов регионов",
 			},
 		),
+		settingsReload: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Subsystem: "navi_taxa_service",
+				Name:      "settings_reload",
+				Help:      "Дата последнего успешного перечитывания настроек",
+			},
+		),
+		settingsReloadFailure: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Subsystem: "navi_taxa_service",
+				Name:      "settings_reload_failure",
+				Help:      "Дата последней неудачной попытки перечитать настройки",
+			},
+		),
 		logger: logger,
 	}
 	return &col
@@ -127,6 +227,15 @@ func (c *Collector) RegisterCollections() error {
 	if err := prometheus.Register(c.timeout); err != nil {
 		return errors.Wrap(err, "timeout")
 	}
+	if err := prometheus.Register(c.providerFailure); err != nil {
+		return errors.Wrap(err, "providerFailure")
+	}
+	if err := prometheus.Register(c.providerRetry); err != nil {
+		return errors.Wrap(err, "providerRetry")
+	}
+	if err := prometheus.Register(c.providerHedge); err != nil {
+		return errors.Wrap(err, "providerHedge")
+	}
 	if err := prometheus.Register(c.providerRespTime); err != nil {
 		return errors.Wrap(err, "providerRespTime")
 	}
@@ -136,12 +245,39 @@ func (c *Collector) RegisterCollections() error {
 	if err := prometheus.Register(c.filterError); err != nil {
 		return errors.Wrap(err, "filterError")
 	}
+	if err := prometheus.Register(c.providerBreakerState); err != nil {
+		return errors.Wrap(err, "providerBreakerState")
+	}
+	if err := prometheus.Register(c.quoteCacheHit); err != nil {
+		return errors.Wrap(err, "quoteCacheHit")
+	}
+	if err := prometheus.Register(c.quoteCacheMiss); err != nil {
+		return errors.Wrap(err, "quoteCacheMiss")
+	}
+	if err := prometheus.Register(c.quoteCacheEviction); err != nil {
+		return errors.Wrap(err, "quoteCacheEviction")
+	}
+	if err := prometheus.Register(c.addressCacheLookup); err != nil {
+		return errors.Wrap(err, "addressCacheLookup")
+	}
+	if err := prometheus.Register(c.addressCacheHitRatio); err != nil {
+		return errors.Wrap(err, "addressCacheHitRatio")
+	}
+	if err := prometheus.Register(c.experimentRequest); err != nil {
+		return errors.Wrap(err, "experimentRequest")
+	}
 	if err := prometheus.Register(c.cacheReload); err != nil {
 		return errors.Wrap(err, "cacheReload")
 	}
 	if err := prometheus.Register(c.regionsReload); err != nil {
 		return errors.Wrap(err, "regionsReload")
 	}
+	if err := prometheus.Register(c.settingsReload); err != nil {
+		return errors.Wrap(err, "settingsReload")
+	}
+	if err := prometheus.Register(c.settingsReloadFailure); err != nil {
+		return errors.Wrap(err, "settingsReloadFailure")
+	}
 	return nil
 }
 
@@ -235,9 +371,64 @@ func (c *Collector) AddRequestTimeout(providerName string, region int) error {
 	return nil
 }
 
-// AddServiceError - зарегистрировать ошибку сервиса
-func (c *Collector) AddServiceError(causeName string, region int) error {
-	counter, err := c.serviceError.GetMetricWithLabelValues(causeName, strconv.Itoa(region))
+// AddProviderFailure - зарегистрировать неудачный запрос к провайдеру. Каждая типизированная ошибка внутри err
+// считается отдельно по своему виду и этапу, ошибка без типа - как unknown
+func (c *Collector) AddProviderFailure(providerName string, err error, region int) error {
+	if err == nil {
+		return nil
+	}
+	apiErrs := apierror.All(err)
+	if len(apiErrs) == 0 {
+		apiErrs = []*apierror.Error{apierror.New(providerName, "", apierror.KindUnknown, err)}
+	}
+	for _, apiErr := range apiErrs {
+		counter, errCounter := c.providerFailure.GetMetricWithLabelValues(providerName, string(apiErr.Kind), apiErr.Stage, strconv.FormatBool(apiErr.Retryable), strconv.Itoa(region))
+		if errCounter != nil {
+			c.logger.Error("Provider failure collector not found:", errCounter)
+			return errCounter
+		}
+		if counter == nil {
+			c.logger.Error("Provider failure collector is nil.")
+			return ErrCollectorNotFound
+		}
+		counter.Inc()
+	}
+	return nil
+}
+
+// AddProviderRetry - зарегистрировать повторный запрос к провайдеру
+func (c *Collector) AddProviderRetry(providerName string, region int) error {
+	counter, err := c.providerRetry.GetMetricWithLabelValues(providerName, strconv.Itoa(region))
+	if err != nil {
+		c.logger.Error("Provider retry collector not found:", err)
+		return err
+	}
+	if counter == nil {
+		c.logger.Error("Provider retry collector is nil.")
+		return ErrCollectorNotFound
+	}
+	counter.Inc()
+	return nil
+}
+
+// AddProviderHedge - зарегистрировать подстраховочный запрос к провайдеру
+func (c *Collector) AddProviderHedge(providerName string, region int) error {
+	counter, err := c.providerHedge.GetMetricWithLabelValues(providerName, strconv.Itoa(region))
+	if err != nil {
+		c.logger.Error("Provider hedge collector not found:", err)
+		return err
+	}
+	if counter == nil {
+		c.logger.Error("Provider hedge collector is nil.")
+		return ErrCollectorNotFound
+	}
+	counter.Inc()
+	return nil
+}
+
+// AddServiceError - зарегистрировать ошибку сервиса для запроса в режиме mode
+func (c *Collector) AddServiceError(causeName, mode string, region int) error {
+	counter, err := c.serviceError.GetMetricWithLabelValues(causeName, mode, strconv.Itoa(region))
 	if err != nil {
 		c.logger.Error("Service errors collector not found:", err)
 		return err
@@ -265,6 +456,26 @@ func (c *Collector) AddProviderResponseTime(providerName, method string, ms floa
 	return nil
 }
 
+// SetProviderBreakerState - обновить состояние circuit breaker провайдера
+func (c *Collector) SetProviderBreakerState(providerName string, state int) error {
+	gauge, err := c.providerBreakerState.GetMetricWithLabelValues(providerName)
+	if err != nil {
+		c.logger.Error("Provider breaker state collector not found:", err)
+		return err
+	}
+	if gauge == nil {
+		c.logger.Error("Provider breaker state collector is nil.")
+		return ErrCollectorNotFound
+	}
+	gauge.Set(float64(state))
+	return nil
+}
+
+// DeleteProviderBreakerState - убрать состояние circuit breaker провайдера, которого больше нет в настройках
+func (c *Collector) DeleteProviderBreakerState(providerName string) {
+	c.providerBreakerState.DeleteLabelValues(providerName)
+}
+
 // UpdateCacheReload - обновить время обновления кэша на текущее
 func (c *Collector) UpdateCacheReload() error {
 	if c.cacheReload == nil {
@@ -284,3 +495,108 @@ func (c *Collector) UpdateRegionsReload() error {
 	c.regionsReload.Set(float64(time.Now().UnixNano()) / 1e9)
 	return nil
 }
+
+// UpdateSettingsReload - обновить время успешного перечитывания настроек на текущее
+func (c *Collector) UpdateSettingsReload() error {
+	if c.settingsReload == nil {
+		c.logger.Error("Settings reload collector is nil.")
+		return ErrCollectorNotFound
+	}
+	c.settingsReload.Set(float64(time.Now().UnixNano()) / 1e9)
+	return nil
+}
+
+// UpdateSettingsReloadFailure - обновить время неудачной попытки перечитать настройки на текущее
+func (c *Collector) UpdateSettingsReloadFailure() error {
+	if c.settingsReloadFailure == nil {
+		c.logger.Error("Settings reload failure collector is nil.")
+		return ErrCollectorNotFound
+	}
+	c.settingsReloadFailure.Set(float64(time.Now().UnixNano()) / 1e9)
+	return nil
+}
+
+// AddQuoteCacheHit - зарегистрировать попадание в кэш ответов провайдера
+func (c *Collector) AddQuoteCacheHit(providerName string) error {
+	counter, err := c.quoteCacheHit.GetMetricWithLabelValues(providerName)
+	if err != nil {
+		c.logger.Error("Quote cache hit collector not found:", err)
+		return err
+	}
+	if counter == nil {
+		c.logger.Error("Quote cache hit collector is nil.")
+		return ErrCollectorNotFound
+	}
+	counter.Inc()
+	return nil
+}
+
+// AddQuoteCacheMiss - зарегистрировать промах кэша ответов провайдера
+func (c *Collector) AddQuoteCacheMiss(providerName string) error {
+	counter, err := c.quoteCacheMiss.GetMetricWithLabelValues(providerName)
+	if err != nil {
+		c.logger.Error("Quote cache miss collector not found:", err)
+		return err
+	}
+	if counter == nil {
+		c.logger.Error("Quote cache miss collector is nil.")
+		return ErrCollectorNotFound
+	}
+	counter.Inc()
+	return nil
+}
+
+// AddQuoteCacheEviction - зарегистрировать вытеснение ответа провайдера из кэша; cause - expired или capacity
+func (c *Collector) AddQuoteCacheEviction(providerName, cause string) error {
+	counter, err := c.quoteCacheEviction.GetMetricWithLabelValues(providerName, cause)
+	if err != nil {
+		c.logger.Error("Quote cache eviction collector not found:", err)
+		return err
+	}
+	if counter == nil {
+		c.logger.Error("Quote cache eviction collector is nil.")
+		return ErrCollectorNotFound
+	}
+	counter.Inc()
+	return nil
+}
+
+// AddAddressCacheLookup - зарегистрировать обращение к кэшу адресов; result - hit, negative_hit или miss
+func (c *Collector) AddAddressCacheLookup(result string) error {
+	counter, err := c.addressCacheLookup.GetMetricWithLabelValues(result)
+	if err != nil {
+		c.logger.Error("Address cache lookup collector not found:", err)
+		return err
+	}
+	if counter == nil {
+		c.logger.Error("Address cache lookup collector is nil.")
+		return ErrCollectorNotFound
+	}
+	counter.Inc()
+	return nil
+}
+
+// SetAddressCacheHitRatio - обновить долю попаданий в кэш адресов
+func (c *Collector) SetAddressCacheHitRatio(ratio float64) error {
+	if c.addressCacheHitRatio == nil {
+		c.logger.Error("Address cache hit ratio collector is nil.")
+		return ErrCollectorNotFound
+	}
+	c.addressCacheHitRatio.Set(ratio)
+	return nil
+}
+
+// AddExperimentRequest - зарегистрировать запрос, попавший в вариант эксперимента
+func (c *Collector) AddExperimentRequest(experiment, variant string, region int) error {
+	counter, err := c.experimentRequest.GetMetricWithLabelValues(experiment, variant, strconv.Itoa(region))
+	if err != nil {
+		c.logger.Error("Experiment request collector not found:", err)
+		return err
+	}
+	if counter == nil {
+		c.logger.Error("Experiment request collector is nil.")
+		return ErrCollectorNotFound
+	}
+	counter.Inc()
+	return nil
+}
@@ -0,0 +1,29 @@
+package duration
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Milliseconds - длительность, которая в settings.json записана целым числом миллисекунд
+type Milliseconds time.Duration
+
+// Duration - длительность как time.Duration
+func (ms Milliseconds) Duration() time.Duration {
+	return time.Duration(ms)
+}
+
+// UnmarshalJSON - число миллисекунд в длительность
+func (ms *Milliseconds) UnmarshalJSON(data []byte) error {
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*ms = Milliseconds(time.Duration(n) * time.Millisecond)
+	return nil
+}
+
+// MarshalJSON - длительность в число миллисекунд
+func (ms Milliseconds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(time.Duration(ms) / time.Millisecond))
+}
@@ -0,0 +1,26 @@
+package duration
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMilliseconds(t *testing.T) {
+	var s struct {
+		TTL   Milliseconds            `json:"ttl_ms"`
+		ByKey map[string]Milliseconds `json:"by_key_ms"`
+	}
+	err := json.Unmarshal([]byte(`{"ttl_ms": 1500, "by_key_ms": {"a": 20}}`), &s)
+	assert.Nil(t, err)
+	assert.Equal(t, 1500*time.Millisecond, s.TTL.Duration())
+	assert.Equal(t, 20*time.Millisecond, s.ByKey["a"].Duration())
+
+	data, err := json.Marshal(s)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"ttl_ms": 1500, "by_key_ms": {"a": 20}}`, string(data))
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"ttl_ms": "1s"}`), &s))
+}
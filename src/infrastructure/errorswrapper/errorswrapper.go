@@ -1,22 +1,63 @@
 package errorswrapper
 
 import (
-	"fmt"
-
-	"github.com/pkg/errors"
+	"bytes"
 )
 
-// WrapErrorSlice - собирает сообщения об ошибках из всего ошибок в slice и создает из них один error
+// MultiError - несколько ошибок одной ошибкой. Исходные ошибки сохраняются, их можно получить через Errors
+// и проверить каждую через errors.Cause
+type MultiError struct {
+	errs []error
+}
+
+// Error - сообщения всех ошибок, каждое с новой строки
+func (m *MultiError) Error() string {
+	var buf bytes.Buffer
+	for _, err := range m.errs {
+		buf.WriteString("\n")
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+// Errors - исходные ошибки в порядке добавления
+func (m *MultiError) Errors() []error {
+	return m.errs
+}
+
+// Append - добавляем к err ошибки errs; nil пропускаем, вложенные MultiError раскрываем.
+// Если в итоге ошибок нет - nil, если одна - она сама
+func Append(err error, errs ...error) error {
+	all := make([]error, 0, len(errs)+1)
+	for _, e := range append([]error{err}, errs...) {
+		if e == nil {
+			continue
+		}
+		if m, ok := e.(*MultiError); ok {
+			all = append(all, m.errs...)
+			continue
+		}
+		all = append(all, e)
+	}
+	switch len(all) {
+	case 0:
+		return nil
+	case 1:
+		return all[0]
+	}
+	return &MultiError{errs: all}
+}
+
+// WrapErrorSlice - собирает ошибки из slice в одну MultiError, nil пропускает; если ошибок нет - nil
 func WrapErrorSlice(errs []error) error {
-	message := ""
+	all := make([]error, 0, len(errs))
 	for _, err := range errs {
-		if err == nil {
-			continue
+		if err != nil {
+			all = append(all, err)
 		}
-		message = fmt.Sprintf("%v\n%v", message, err.Error())
 	}
-	if message == "" {
+	if len(all) == 0 {
 		return nil
 	}
-	return errors.New(message)
+	return &MultiError{errs: all}
 }
@@ -0,0 +1,40 @@
+package errorswrapper
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errFirst  = errors.New("First")
+	errSecond = errors.New("Second")
+)
+
+func TestAppend(t *testing.T) {
+	assert.Nil(t, Append(nil))
+	assert.Nil(t, Append(nil, nil, nil))
+	assert.Equal(t, errFirst, Append(nil, errFirst, nil))
+
+	err := Append(errors.Wrap(errFirst, "a"), nil, Append(errSecond, errors.Wrap(errFirst, "b")))
+	multi, ok := err.(*MultiError)
+	if !assert.True(t, ok) {
+		return
+	}
+	causes := make([]error, 0)
+	for _, e := range multi.Errors() {
+		causes = append(causes, errors.Cause(e))
+	}
+	assert.Equal(t, []error{errFirst, errSecond, errFirst}, causes, "nested multi errors are flattened, causes are kept")
+	assert.Equal(t, "\na: First\nSecond\nb: First", err.Error())
+}
+
+func TestWrapErrorSlice(t *testing.T) {
+	assert.Nil(t, WrapErrorSlice(nil))
+	assert.Nil(t, WrapErrorSlice([]error{nil, nil}))
+	err := WrapErrorSlice([]error{nil, errFirst})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, []error{errFirst}, err.(*MultiError).Errors())
+	}
+}
@@ -6,15 +6,15 @@ import (
 	_ "crypto/sha512"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"mime/multipart"
 	"net/http"
-	"strings"
+	"net/url"
 	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/moul/http2curl"
 	"github.com/pkg/errors"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/collector"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
 )
@@ -55,6 +55,7 @@ type Requester struct {
 	mockEnabled bool
 	mockParams  mock
 	coll        *collector.Collector
+	policy      Policy
 }
 
 // NewRequester - новый реквестер HTTP
@@ -65,6 +66,7 @@ func NewRequester(client *http.Client, logger *log.StructuredLogger, coll *colle
 		false,
 		mock{},
 		coll,
+		Policy{},
 	}
 }
 
@@ -80,9 +82,17 @@ func NewMockRequester(client *http.Client, logger *log.StructuredLogger, coll *c
 			port,
 		},
 		coll,
+		Policy{},
 	}
 }
 
+// WithPolicy - копия реквестера, которая делает запросы по заданной политике
+func (r *Requester) WithPolicy(policy Policy) *Requester {
+	withPolicy := *r
+	withPolicy.policy = policy
+	return &withPolicy
+}
+
 func (r *Requester) createRequest(ctx context.Context, url, method string, headers, params []Dict, data []byte) (*http.Request, error) {
 	var req *http.Request
 	var err error
@@ -124,7 +134,7 @@ func (r *Requester) createRequest(ctx context.Context, url, method string, heade
 	return req, nil
 }
 
-func (r *Requester) do(req *http.Request, holder interface{}) error {
+func (r *Requester) do(req *http.Request, data []byte, holder interface{}) error {
 	var elapsed float64
 	var regionID int
 	var providerName string
@@ -139,34 +149,39 @@ func (r *Requester) do(req *http.Request, holder interface{}) error {
 	r.coll.AddProviderRequest(providerName, regionID)
 	commandForLog, _ := http2curl.GetCurlCommand(req)
 	r.logger.Debug(fmt.Sprintf("requesting %v %v", req.URL, commandForLog))
-	response, errDo := r.client.Do(req)
+	res := r.send(req, data, providerName, regionID)
 	elapsed = float64(time.Since(start).Nanoseconds()) / 1000000
-	if errDo != nil {
-		err := errors.Wrapf(ErrDoRequest, "Error when requesting %v: %v", req.URL, errDo.Error())
-		if strings.Contains(errDo.Error(), "context deadline") || strings.Contains(errDo.Error(), "context canceled") {
-			err = errors.Wrapf(ErrContextDeadline, "Error when requesting %v: %v", req.URL, errDo.Error())
+	if errDo := res.errDo; errDo != nil {
+		err := apierror.New(providerName, req.URL.Path, apierror.KindTransport, errors.Wrapf(ErrDoRequest, "Error when requesting %v: %v", req.URL, errDo.Error()))
+		if isTimeout(req.Context(), errDo) {
+			err = apierror.New(providerName, req.URL.Path, apierror.KindTimeout, errors.Wrapf(ErrContextDeadline, "Error when requesting %v: %v", req.URL, errDo.Error()))
 			r.coll.AddRequestTimeout(providerName, regionID)
 		}
+		err.Retryable = true
 		r.logger.NewAPIWarnLogEntry(req, err, elapsed)
 		return err
 	}
-	content, errRead := ioutil.ReadAll(response.Body)
-	if errRead != nil {
-		err := errors.Wrapf(ErrReadRequest, "GET request: error when reading pesponse body: %v", errRead.Error())
+	content := res.content
+	if errRead := res.errRead; errRead != nil {
+		err := apierror.New(providerName, req.URL.Path, apierror.KindRead, errors.Wrapf(ErrReadRequest, "GET request: error when reading pesponse body: %v", errRead.Error()))
+		err.StatusCode = res.statusCode
+		err.Retryable = true
 		r.logger.NewAPIWarnLogEntry(req, err, elapsed)
 		r.coll.AddProviderInvalidValueResponse(providerName, fmt.Sprintf("read_%v", req.URL.Path), regionID)
 		return err
 	}
-	response.Body.Close()
-	if response.StatusCode != http.StatusOK {
-		err := errors.Wrapf(ErrStatusNotOK, "request %v, response code %v", commandForLog, response.StatusCode)
+	if res.statusCode != http.StatusOK {
+		err := apierror.New(providerName, req.URL.Path, apierror.KindStatus, errors.Wrapf(ErrStatusNotOK, "request %v, response code %v", commandForLog, res.statusCode))
+		err.StatusCode = res.statusCode
+		err.Retryable = r.policy.isRetryStatus(res.statusCode)
 		r.logger.NewAPIWarnLogEntry(req, err, elapsed)
-		r.coll.AddProviderErrorResponse(providerName, req.URL.Path, response.StatusCode, regionID)
+		r.coll.AddProviderErrorResponse(providerName, req.URL.Path, res.statusCode, regionID)
 		return err
 	}
 	errParse := json.NewDecoder(bytes.NewReader(content)).Decode(&holder)
 	if errParse != nil {
-		err := errors.Wrapf(ErrParse, "GET request: error when parsing pesponse: %v, %v", errParse.Error(), string(content))
+		err := apierror.New(providerName, req.URL.Path, apierror.KindParse, errors.Wrapf(ErrParse, "GET request: error when parsing pesponse: %v, %v", errParse.Error(), string(content)))
+		err.StatusCode = res.statusCode
 		r.logger.NewAPIWarnLogEntry(req, err, elapsed)
 		r.coll.AddProviderInvalidValueResponse(providerName, fmt.Sprintf("parse_%v", req.URL.Path), regionID)
 		return err
@@ -176,23 +191,66 @@ func (r *Requester) do(req *http.Request, holder interface{}) error {
 	return nil
 }
 
+// timeoutError - ошибки net/http и net, которые знают, что они таймаут
+type timeoutError interface {
+	Timeout() bool
+}
+
+// isTimeout - запрос прерван дедлайном: общим дедлайном запроса к сервису, таймаутом попытки или отменой
+func isTimeout(ctx context.Context, errDo error) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	if te, ok := errDo.(timeoutError); ok && te.Timeout() {
+		return true
+	}
+	if ue, ok := errDo.(*url.Error); ok {
+		errDo = ue.Err
+	}
+	return errDo == context.DeadlineExceeded || errDo == context.Canceled
+}
+
+// stageRequest - этап ошибки, если из адреса запроса не удалось взять путь
+const stageRequest = "request"
+
+// requestError - не смогли собрать запрос; повторять такой запрос бессмысленно.
+// Этап - путь запроса, как в do: адрес целиком с параметрами сделал бы метки метрик неограниченными
+func requestError(ctx context.Context, rawURL string, err error) error {
+	provName, _ := ctx.Value(log.CtxKeyAPIName).(string)
+	stage := stageRequest
+	if u, errURL := url.Parse(rawURL); errURL == nil && u.Path != "" {
+		stage = u.Path
+	}
+	return apierror.New(provName, stage, apierror.KindRequest, err)
+}
+
 // Get - делаем Get запрос
 func (r *Requester) Get(ctx context.Context, url string, headers []Dict, params []Dict, holder interface{}) error {
 	req, err := r.createRequest(ctx, url, http.MethodGet, headers, params, nil)
 	if err != nil {
-		return errors.Wrapf(ErrCreateRequest, "Cannot create GET request %v", err.Error())
+		return requestError(ctx, url, errors.Wrapf(ErrCreateRequest, "Cannot create GET request %v", err.Error()))
 	}
-	return r.do(req, holder)
+	return r.do(req, nil, holder)
 }
 
 // Post - делаем Post запрос
 func (r *Requester) Post(ctx context.Context, url string, headers []Dict, data []byte, holder interface{}) error {
 	req, err := r.createRequest(ctx, url, http.MethodPost, headers, nil, data)
 	if err != nil {
-		return errors.Wrapf(ErrCreateRequest, "Cannot create POST request: %v", err.Error())
+		return requestError(ctx, url, errors.Wrapf(ErrCreateRequest, "Cannot create POST request: %v", err.Error()))
+	}
+	r.logger.Debugf("POST, %v, data: %v", req.URL.Host, string(data))
+	return r.do(req, data, holder)
+}
+
+// PostWithParams - делаем Post запрос с параметрами в query string
+func (r *Requester) PostWithParams(ctx context.Context, url string, headers []Dict, params []Dict, data []byte, holder interface{}) error {
+	req, err := r.createRequest(ctx, url, http.MethodPost, headers, params, data)
+	if err != nil {
+		return requestError(ctx, url, errors.Wrapf(ErrCreateRequest, "Cannot create POST request: %v", err.Error()))
 	}
 	r.logger.Debugf("POST, %v, data: %v", req.URL.Host, string(data))
-	return r.do(req, holder)
+	return r.do(req, data, holder)
 }
 
 // PostForm - делаем Post запрос с формой
@@ -205,11 +263,11 @@ func (r *Requester) PostForm(ctx context.Context, url string, headers []Dict, fo
 		// Populate fields
 		fieldWriter, err := multiPartWriter.CreateFormField(formParam.Key)
 		if err != nil {
-			return errors.Wrapf(ErrCreateRequest, "Cannot create form field when post form data: %v", err.Error())
+			return requestError(ctx, url, errors.Wrapf(ErrCreateRequest, "Cannot create form field when post form data: %v", err.Error()))
 		}
 		_, err = fieldWriter.Write([]byte(formParam.Value))
 		if err != nil {
-			return errors.Wrapf(ErrCreateRequest, "Cannot write value to form field when post form data: %v", err.Error())
+			return requestError(ctx, url, errors.Wrapf(ErrCreateRequest, "Cannot write value to form field when post form data: %v", err.Error()))
 		}
 	}
 	// We completed adding the file and the fields, let's close the multipart writer
@@ -217,9 +275,9 @@ func (r *Requester) PostForm(ctx context.Context, url string, headers []Dict, fo
 	multiPartWriter.Close()
 	req, err := r.createRequest(ctx, url, http.MethodPost, headers, nil, requestBody.Bytes())
 	if err != nil {
-		return errors.Wrapf(ErrCreateRequest, "Cannot create POST FORM request: %v", err.Error())
+		return requestError(ctx, url, errors.Wrapf(ErrCreateRequest, "Cannot create POST FORM request: %v", err.Error()))
 	}
 	// We need to set the content type from the writer, it includes necessary boundary as well
 	req.Header.Set("Content-Type", multiPartWriter.FormDataContentType())
-	return r.do(req, holder)
+	return r.do(req, requestBody.Bytes(), holder)
 }
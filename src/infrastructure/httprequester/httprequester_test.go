@@ -0,0 +1,67 @@
+package httprequester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+		case "/bad":
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		case "/broken":
+			w.Write([]byte(`{"status": `))
+			return
+		}
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.WithValue(context.Background(), log.CtxKeyAPIName, "test")
+	httpreq := testRequester.WithPolicy(Policy{Timeout: duration.Milliseconds(10 * time.Millisecond)})
+	tests := []struct {
+		path      string
+		kind      apierror.Kind
+		cause     error
+		status    int
+		retryable bool
+	}{
+		{"/slow", apierror.KindTimeout, ErrContextDeadline, 0, true},
+		{"/bad", apierror.KindStatus, ErrStatusNotOK, http.StatusBadRequest, false},
+		{"/broken", apierror.KindParse, ErrParse, http.StatusOK, false},
+	}
+	for _, test := range tests {
+		var holder testHolder
+		err := errors.Wrap(httpreq.Get(ctx, srv.URL+test.path, nil, nil, &holder), "Test: Cannot request")
+		assert.Equal(t, test.cause, errors.Cause(err), test.path)
+		apiErr, ok := apierror.As(err)
+		if !assert.True(t, ok, test.path) {
+			continue
+		}
+		assert.Equal(t, "test", apiErr.Provider, test.path)
+		assert.Equal(t, test.kind, apiErr.Kind, test.path)
+		assert.Equal(t, test.status, apiErr.StatusCode, test.path)
+		assert.Equal(t, test.retryable, apiErr.Retryable, test.path)
+	}
+
+	err := httpreq.Get(ctx, "http://[::1?key=secret", nil, nil, nil)
+	assert.Equal(t, ErrCreateRequest, errors.Cause(err))
+	assert.Equal(t, apierror.KindRequest, apierror.KindOf(err))
+	if apiErr, ok := apierror.As(err); assert.True(t, ok) {
+		assert.Equal(t, stageRequest, apiErr.Stage, "stage is a bounded metric label")
+	}
+	assert.Equal(t, "/v1/price", requestError(ctx, "http://test.com/v1/price?start=1&key=secret", ErrCreateRequest).(*apierror.Error).Stage)
+}
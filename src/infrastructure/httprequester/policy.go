@@ -0,0 +1,165 @@
+package httprequester
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+)
+
+// defaultRetryStatuses - коды ответа, на которых повторяем запрос, если в политике они не заданы
+var defaultRetryStatuses = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// Policy - политика запросов к провайдеру: таймаут попытки, повторы и "подстраховочный" (hedged) запрос.
+// Нулевая политика - один запрос без собственного таймаута, как раньше
+type Policy struct {
+	// Timeout - таймаут одной попытки; общий дедлайн запроса к сервису все равно действует
+	Timeout duration.Milliseconds `json:"timeout_ms"`
+	// MaxRetries - сколько раз повторяем запрос после ошибки соединения или кода из RetryStatuses
+	MaxRetries int `json:"max_retries"`
+	// Backoff - базовая пауза перед повтором, удваивается с каждой попыткой, к ней добавляется случайная добавка
+	Backoff duration.Milliseconds `json:"backoff_ms"`
+	// RetryStatuses - коды ответа, на которых повторяем запрос
+	RetryStatuses []int `json:"retry_statuses"`
+	// HedgeDelay - если ответа нет дольше HedgeDelay, отправляем второй такой же запрос и берем первый успешный
+	HedgeDelay duration.Milliseconds `json:"hedge_ms"`
+}
+
+// IsEmpty - политика не задана
+func (p Policy) IsEmpty() bool {
+	return p.Timeout == 0 && p.MaxRetries == 0 && p.HedgeDelay == 0
+}
+
+func (p Policy) isRetryStatus(code int) bool {
+	statuses := p.RetryStatuses
+	if len(statuses) == 0 {
+		statuses = defaultRetryStatuses
+	}
+	for _, status := range statuses {
+		if status == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff - пауза перед попыткой attempt (начиная с 1): Backoff * 2^(attempt-1) + случайная добавка до Backoff
+func (p Policy) backoff(attempt int) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+	backoff := p.Backoff.Duration()
+	return backoff<<uint(attempt-1) + time.Duration(rand.Int63n(int64(backoff)))
+}
+
+// attemptResult - результат одной попытки запроса
+type attemptResult struct {
+	content    []byte
+	statusCode int
+	errDo      error
+	errRead    error
+}
+
+func (a attemptResult) isOK() bool {
+	return a.errDo == nil && a.errRead == nil && a.statusCode == http.StatusOK
+}
+
+// attempt - одна попытка запроса с таймаутом из политики. Тело ответа читаем, пока жив контекст попытки
+func (r *Requester) attempt(ctx context.Context, req *http.Request, data []byte) attemptResult {
+	attemptCtx := ctx
+	if r.policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, r.policy.Timeout.Duration())
+		defer cancel()
+	}
+	attemptReq := req.WithContext(attemptCtx)
+	if data != nil {
+		attemptReq.Body = ioutil.NopCloser(bytes.NewReader(data))
+		attemptReq.ContentLength = int64(len(data))
+	}
+	response, errDo := r.client.Do(attemptReq)
+	if errDo != nil {
+		return attemptResult{errDo: errDo}
+	}
+	defer response.Body.Close()
+	content, errRead := ioutil.ReadAll(response.Body)
+	return attemptResult{
+		content:    content,
+		statusCode: response.StatusCode,
+		errRead:    errRead,
+	}
+}
+
+// hedgedAttempt - попытка с подстраховкой: если первый запрос не ответил за HedgeDelay, отправляем второй
+// и возвращаем первый успешный результат; оставшийся запрос отменяем
+func (r *Requester) hedgedAttempt(req *http.Request, data []byte, providerName string, regionID int) attemptResult {
+	if r.policy.HedgeDelay <= 0 {
+		return r.attempt(req.Context(), req, data)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	// буфер на оба запроса, чтобы опоздавший не заблокировался после нашего выхода
+	results := make(chan attemptResult, 2)
+	launch := func() {
+		go func() {
+			results <- r.attempt(ctx, req, data)
+		}()
+	}
+	launch()
+	inFlight := 1
+	hedgeTimer := time.NewTimer(r.policy.HedgeDelay.Duration())
+	defer hedgeTimer.Stop()
+	var last attemptResult
+	for inFlight > 0 {
+		select {
+		case <-hedgeTimer.C:
+			if ctx.Err() == nil {
+				r.coll.AddProviderHedge(providerName, regionID)
+				launch()
+				inFlight++
+			}
+		case res := <-results:
+			inFlight--
+			if res.isOK() {
+				return res
+			}
+			last = res
+		}
+	}
+	return last
+}
+
+// send - выполняем запрос по политике: попытки с паузами, пока ошибка повторяемая и не истек общий дедлайн
+func (r *Requester) send(req *http.Request, data []byte, providerName string, regionID int) attemptResult {
+	ctx := req.Context()
+	var res attemptResult
+	for attempt := 0; attempt <= r.policy.MaxRetries; attempt++ {
+		if attempt > 0 {
+			r.coll.AddProviderRetry(providerName, regionID)
+			select {
+			case <-ctx.Done():
+				return res
+			case <-time.After(r.policy.backoff(attempt)):
+			}
+		}
+		res = r.hedgedAttempt(req, data, providerName, regionID)
+		if !r.isRetryable(ctx, res) {
+			return res
+		}
+	}
+	return res
+}
+
+func (r *Requester) isRetryable(ctx context.Context, res attemptResult) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if res.errDo != nil || res.errRead != nil {
+		return true
+	}
+	return res.statusCode != http.StatusOK && r.policy.isRetryStatus(res.statusCode)
+}
@@ -0,0 +1,129 @@
+package httprequester
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/collector"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testLogger    = log.NewEmpty()
+	testRequester = NewRequester(&http.Client{}, testLogger, collector.NewCollector(testLogger))
+)
+
+type testHolder struct {
+	Status string `json:"status"`
+}
+
+func TestPolicyUnmarshal(t *testing.T) {
+	var p Policy
+	err := json.Unmarshal([]byte(`{"timeout_ms": 300, "max_retries": 2, "backoff_ms": 10, "retry_statuses": [500], "hedge_ms": 100}`), &p)
+	assert.Nil(t, err)
+	assert.Equal(t, Policy{
+		Timeout:       duration.Milliseconds(300 * time.Millisecond),
+		MaxRetries:    2,
+		Backoff:       duration.Milliseconds(10 * time.Millisecond),
+		RetryStatuses: []int{500},
+		HedgeDelay:    duration.Milliseconds(100 * time.Millisecond),
+	}, p)
+	assert.True(t, Policy{}.IsEmpty())
+	assert.True(t, Policy{}.isRetryStatus(http.StatusServiceUnavailable))
+	assert.False(t, p.isRetryStatus(http.StatusServiceUnavailable))
+}
+
+func TestPolicyRetryStatus(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer srv.Close()
+
+	var holder testHolder
+	httpreq := testRequester.WithPolicy(Policy{MaxRetries: 2, Backoff: duration.Milliseconds(time.Millisecond)})
+	err := httpreq.Post(context.Background(), srv.URL, nil, []byte(`{}`), &holder)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", holder.Status)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestPolicyNoRetryOnClientError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	var holder testHolder
+	httpreq := testRequester.WithPolicy(Policy{MaxRetries: 2})
+	err := httpreq.Get(context.Background(), srv.URL, nil, nil, &holder)
+	assert.Equal(t, ErrStatusNotOK, errors.Cause(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestPolicyAttemptTimeout(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer srv.Close()
+
+	var holder testHolder
+	httpreq := testRequester.WithPolicy(Policy{Timeout: duration.Milliseconds(50 * time.Millisecond), MaxRetries: 1})
+	err := httpreq.Get(context.Background(), srv.URL, nil, nil, &holder)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", holder.Status)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestPolicyHedge(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(300 * time.Millisecond)
+			w.Write([]byte(`{"status": "slow"}`))
+			return
+		}
+		w.Write([]byte(`{"status": "fast"}`))
+	}))
+	defer srv.Close()
+
+	var holder testHolder
+	httpreq := testRequester.WithPolicy(Policy{HedgeDelay: duration.Milliseconds(30 * time.Millisecond)})
+	start := time.Now()
+	err := httpreq.Get(context.Background(), srv.URL, nil, nil, &holder)
+	assert.Nil(t, err)
+	assert.Equal(t, "fast", holder.Status)
+	assert.True(t, time.Since(start) < 300*time.Millisecond)
+}
+
+func TestPolicyDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	var holder testHolder
+	httpreq := testRequester.WithPolicy(Policy{MaxRetries: 5, HedgeDelay: duration.Milliseconds(10 * time.Millisecond)})
+	err := httpreq.Get(ctx, srv.URL, nil, nil, &holder)
+	assert.Equal(t, ErrContextDeadline, errors.Cause(err))
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/middleware"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -114,7 +115,7 @@ func (l *StructuredLogger) NewAPIWarnLogEntry(r *http.Request, err error, elapse
 		"url":          r.URL,
 		"uri":          r.RequestURI,
 		"elapsed":      elapsed,
-	}).Warningf("API request failed: %v\n", err.Error())
+	}).WithFields(apiErrorFields(err)).Warningf("API request failed: %v\n", err.Error())
 }
 
 // NewAPIDoneLogEntry - логируем окончание запроса к провайдеру такси
@@ -148,7 +149,36 @@ func (l *StructuredLogger) ServiceWarningLogEntry(ctx context.Context, err error
 		"err_cause":    errCause,
 		"req_id":       middleware.GetReqID(ctx),
 		"message":      message,
-	}).Warningln(err.Error())
+	}).WithFields(apiErrorFields(err)).Warningln(err.Error())
+}
+
+// apiErrorFields - поля типизированной ошибки запроса к внешнему API, если она есть внутри err
+func apiErrorFields(err error) logrus.Fields {
+	apiErr, ok := apierror.As(err)
+	if !ok {
+		return logrus.Fields{}
+	}
+	fields := logrus.Fields{
+		"err_kind":      string(apiErr.Kind),
+		"err_provider":  apiErr.Provider,
+		"err_stage":     apiErr.Stage,
+		"err_retryable": apiErr.Retryable,
+	}
+	if apiErr.StatusCode != 0 {
+		fields["err_status"] = apiErr.StatusCode
+	}
+	return fields
+}
+
+// ExperimentLogEntry - записываем вариант эксперимента, в который попал запрос
+func (l *StructuredLogger) ExperimentLogEntry(ctx context.Context, experiment, variant string, regionID int) {
+	l.WithFields(logrus.Fields{
+		"message_type": "experiment",
+		"req_id":       middleware.GetReqID(ctx),
+		"experiment":   experiment,
+		"variant":      variant,
+		"region":       regionID,
+	}).Infoln("experiment variant assigned")
 }
 
 // StructuredLoggerEntry - струтура описывающая запись в логе
@@ -1,21 +1,41 @@
 package settings
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/pointresolver"
 	"github.com/nburunova/taxi-backend-sample/src/taxi"
 	"github.com/nburunova/taxi-backend-sample/src/taxi/provider"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
 )
 
 // Settings - структура, содержащая настройки и данные для АПИ провайдеов такси
 type Settings struct {
-	ReloadDBSchedule      string                 `json:"reload_cache_period_cron"`
-	ReloadRegionsSchedule string                 `json:"reload_regions_period_cron"`
-	WaitTime              time.Duration          `json:"wait_time_ms"`
-	PriceCoeff            float64                `json:"price_coeff"`
-	RegPriceCoeff         taxi.RegionPriceCoeff  `json:"region_price_coeff"`
-	ConnStr               string                 `json:"conn_str"`
-	Providers             provider.ProvidersList `json:"taxi_services"`
+	ReloadDBSchedule      string                      `json:"reload_cache_period_cron"`
+	ReloadRegionsSchedule string                      `json:"reload_regions_period_cron"`
+	WaitTime              duration.Milliseconds       `json:"wait_time_ms"`
+	PriceCoeff            float64                     `json:"price_coeff"`
+	RegPriceCoeff         taxi.RegionPriceCoeff       `json:"region_price_coeff"`
+	AddressPlaceholder    string                      `json:"address_placeholder"`
+	OptimalMaxSurge       float64                     `json:"optimal_max_surge"`
+	Ranking               service.RankingSettings     `json:"ranking"`
+	Experiment            service.ExperimentSettings  `json:"experiment"`
+	ConnStr               string                      `json:"conn_str"`
+	Providers             provider.ProvidersList      `json:"taxi_services"`
+	Breaker               service.BreakerSettings     `json:"circuit_breaker"`
+	AddressCache          pointresolver.CacheSettings `json:"address_cache"`
+	Route                 service.RouteSettings       `json:"route"`
+	QuoteCache            service.QuoteCacheSettings  `json:"quote_cache"`
+	Batch                 service.BatchSettings       `json:"batch"`
+}
+
+// Summary - несекретная сводка настроек для логов. Строка подключения, ключи и заголовки провайдеров
+// в нее не попадают
+func (s Settings) Summary() string {
+	return fmt.Sprintf("providers: %v, wait_time_ms: %v, price_coeff: %v, region_price_coeff: %v, optimal_max_surge: %v",
+		s.Providers.Handlers(), int64(s.WaitTime.Duration()/time.Millisecond), s.PriceCoeff, s.RegPriceCoeff, s.OptimalMaxSurge)
 }
 
 // IsEmply - проверяем, есть ли что-нибудь в настройках
@@ -0,0 +1,31 @@
+package settings
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	s := Settings{
+		ConnStr:    "postgres://user:secret@db",
+		WaitTime:   duration.Milliseconds(1500 * time.Millisecond),
+		PriceCoeff: 1.3,
+		Providers:  provider.ProvidersList{"uber": json.RawMessage(`{"headers": [{"key": "Authorization", "value": "Token secret"}]}`)},
+	}
+	summary := s.Summary()
+	assert.NotContains(t, summary, "secret")
+	assert.Contains(t, summary, "providers: [uber]")
+	assert.Contains(t, summary, "wait_time_ms: 1500")
+}
+
+func TestUnmarshalUnits(t *testing.T) {
+	s := readTestSettings(t)
+	assert.Equal(t, 2500*time.Millisecond, s.WaitTime.Duration())
+	assert.Equal(t, 30*time.Second, s.Breaker.OpenTime.Duration())
+	assert.Equal(t, 10*time.Second, s.Batch.WaitTime.Duration())
+}
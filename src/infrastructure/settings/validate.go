@@ -0,0 +1,162 @@
+package settings
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/errorswrapper"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/ttlcache"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+)
+
+var (
+	// ErrInvalidSettings - значение в settings.json не прошло проверку
+	ErrInvalidSettings = errors.New("Invalid settings")
+)
+
+// RegionChecker - источник известных кодов регионов
+type RegionChecker interface {
+	GetRegionNameByID(regionID int) (string, error)
+}
+
+// Validate - проверяем настройки целиком и возвращаем все найденные проблемы одной ошибкой.
+// regions - известные коды регионов; nil - коды регионов проверяем только на положительность
+func (s *Settings) Validate(regions RegionChecker) error {
+	v := &validator{known: regions}
+	if s.IsEmply() {
+		v.problem("conn_str: empty")
+	}
+	v.cron("reload_cache_period_cron", s.ReloadDBSchedule)
+	v.cron("reload_regions_period_cron", s.ReloadRegionsSchedule)
+	if s.WaitTime <= 0 {
+		v.problem("wait_time_ms: must be > 0")
+	}
+	v.coeff("price_coeff", s.PriceCoeff)
+	regPriceCoeffIDs := make([]int, 0, len(s.RegPriceCoeff))
+	for regionID := range s.RegPriceCoeff {
+		regPriceCoeffIDs = append(regPriceCoeffIDs, regionID)
+	}
+	v.regions("region_price_coeff", regPriceCoeffIDs)
+	for _, regionID := range regPriceCoeffIDs {
+		v.coeff(fmtRegion("region_price_coeff", regionID), s.RegPriceCoeff[regionID])
+	}
+	if s.OptimalMaxSurge < 0 {
+		v.problem("optimal_max_surge: must be >= 0")
+	}
+	if !s.Breaker.IsEmpty() {
+		if s.Breaker.ConsecutiveErrors < 0 || s.Breaker.Window < 0 {
+			v.problem("circuit_breaker: consecutive_errors and window must be >= 0")
+		}
+		if s.Breaker.TimeoutRatio < 0 || s.Breaker.TimeoutRatio > 1 {
+			v.problem("circuit_breaker: timeout_ratio must be in [0, 1]")
+		}
+		if s.Breaker.OpenTime <= 0 {
+			v.problem("circuit_breaker: open_ms must be > 0")
+		}
+	}
+	v.s2Level("address_cache", s.AddressCache.Level)
+	v.s2Level("route", s.Route.Level)
+	v.s2Level("quote_cache", s.QuoteCache.Level)
+	routeIDs := make([]int, 0, len(s.Route.Regions))
+	for regionID := range s.Route.Regions {
+		routeIDs = append(routeIDs, regionID)
+	}
+	v.regions("route.regions", routeIDs)
+	if err := s.Ranking.Validate(); err != nil {
+		v.err("ranking", err)
+	}
+	rankingIDs := make([]int, 0, len(s.Ranking.Regions))
+	for regionID := range s.Ranking.Regions {
+		rankingIDs = append(rankingIDs, regionID)
+	}
+	v.regions("ranking.regions", rankingIDs)
+	if err := s.Experiment.Validate(); err != nil {
+		v.err("experiment", err)
+	}
+	v.variantProviders(s.Experiment, s.Providers.Names())
+	experimentIDs := make([]int, 0, len(s.Experiment.Regions))
+	for regionID := range s.Experiment.Regions {
+		experimentIDs = append(experimentIDs, regionID)
+	}
+	v.regions("experiment.regions", experimentIDs)
+	if err := s.Batch.Validate(); err != nil {
+		v.err("batch", err)
+	}
+	for _, err := range s.Providers.Validate() {
+		v.err("taxi_services", err)
+	}
+	return errorswrapper.WrapErrorSlice(v.problems)
+}
+
+// validator - копит проблемы настроек, чтобы показать их все сразу
+type validator struct {
+	known    RegionChecker
+	problems []error
+}
+
+func (v *validator) problem(message string) {
+	v.problems = append(v.problems, errors.Wrap(ErrInvalidSettings, message))
+}
+
+func (v *validator) err(field string, err error) {
+	v.problems = append(v.problems, errors.Wrap(err, field))
+}
+
+func (v *validator) cron(field, spec string) {
+	if _, err := cron.Parse(spec); err != nil {
+		v.problem(field + ": " + err.Error())
+	}
+}
+
+func (v *validator) coeff(field string, coeff float64) {
+	if coeff <= 0 {
+		v.problem(field + ": must be > 0")
+	}
+}
+
+func (v *validator) s2Level(field string, level int) {
+	if level < 0 || level > ttlcache.MaxCellLevel {
+		v.problem(field + ": s2_level must be in [0, 30]")
+	}
+}
+
+// variantProviders - провайдеры вариантов эксперимента должны быть среди провайдеров taxi_services
+func (v *validator) variantProviders(es service.ExperimentSettings, providerNames []string) {
+	if es.IsEmpty() {
+		return
+	}
+	known := make(map[string]bool, len(providerNames))
+	for _, name := range providerNames {
+		known[name] = true
+	}
+	for _, vs := range es.Variants {
+		for _, name := range vs.Providers {
+			if !known[name] {
+				v.problem(fmt.Sprintf("experiment.variants.%v.providers: unknown provider %q", vs.Name, name))
+			}
+		}
+	}
+}
+
+// regions - коды регионов из ключей настроек должны быть положительными и известными
+func (v *validator) regions(field string, regionIDs []int) {
+	sort.Ints(regionIDs)
+	for _, regionID := range regionIDs {
+		if regionID <= 0 {
+			v.problem(fmtRegion(field, regionID) + ": region id must be > 0")
+			continue
+		}
+		if v.known == nil {
+			continue
+		}
+		if _, err := v.known.GetRegionNameByID(regionID); err != nil {
+			v.problem(fmtRegion(field, regionID) + ": unknown region")
+		}
+	}
+}
+
+func fmtRegion(field string, regionID int) string {
+	return fmt.Sprintf("%v.%v", field, regionID)
+}
@@ -0,0 +1,66 @@
+package settings
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRegions map[int]bool
+
+func (r testRegions) GetRegionNameByID(regionID int) (string, error) {
+	if r[regionID] {
+		return "region", nil
+	}
+	return "", errors.New("Region name not found by ID")
+}
+
+func readTestSettings(t *testing.T) Settings {
+	var s Settings
+	content, err := ioutil.ReadFile("../../cmd/api/settings.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(content, &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestValidateSample(t *testing.T) {
+	s := readTestSettings(t)
+	assert.Nil(t, s.Validate(nil))
+	assert.Nil(t, s.Validate(testRegions{1: true, 32: true, 99: true}))
+}
+
+func TestValidateAllProblems(t *testing.T) {
+	s := readTestSettings(t)
+	s.ConnStr = ""
+	s.ReloadDBSchedule = "every minute"
+	s.WaitTime = 0
+	s.PriceCoeff = 0
+	s.RegPriceCoeff[-1] = 1.2
+	s.Route.Level = 31
+	s.Experiment.Variants[1].Providers = []string{"gett", "gett_main"}
+
+	err := s.Validate(testRegions{1: true, 99: true})
+	if !assert.NotNil(t, err) {
+		return
+	}
+	problems := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	assert.Equal(t, []string{
+		"conn_str: empty: Invalid settings",
+		"reload_cache_period_cron: Expected 5 to 6 fields, found 2: every minute: Invalid settings",
+		"wait_time_ms: must be > 0: Invalid settings",
+		"price_coeff: must be > 0: Invalid settings",
+		"region_price_coeff.-1: region id must be > 0: Invalid settings",
+		"route: s2_level must be in [0, 30]: Invalid settings",
+		"route.regions.32: unknown region: Invalid settings",
+		"ranking.regions.32: unknown region: Invalid settings",
+		"experiment.variants.cheapest.providers: unknown provider \"gett_main\": Invalid settings",
+	}, problems)
+}
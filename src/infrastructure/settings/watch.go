@@ -0,0 +1,27 @@
+package settings
+
+import (
+	"os"
+	"time"
+)
+
+// Watch - раз в interval проверяем время изменения файла настроек и вызываем onChange, если файл изменился.
+// Файл, который не удалось прочитать, пропускаем до следующей проверки
+func Watch(path string, interval time.Duration, onChange func()) {
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			info, err := os.Stat(path)
+			if err != nil || !info.ModTime().After(modTime) {
+				continue
+			}
+			modTime = info.ModTime()
+			onChange()
+		}
+	}()
+}
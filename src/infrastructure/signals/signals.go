@@ -39,3 +39,20 @@ func BindSignals(logger log.Logger, services ...stoppable) {
 		}
 	}()
 }
+
+// BindReload - по SIGHUP вызываем reload, ошибку перечитывания только логируем.
+// Вызывать после BindSignals, иначе SIGHUP останется проигнорированным
+func BindReload(logger log.Logger, reload func() error) {
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for s := range hupChan {
+			logger.Infof("Captured %v. Reloading settings...", s)
+			if err := reload(); err != nil {
+				logger.WithError(err).Error("Settings are not reloaded, keep the previous ones")
+				continue
+			}
+			logger.Info("Settings are reloaded")
+		}
+	}()
+}
@@ -0,0 +1,22 @@
+package ttlcache
+
+import (
+	"github.com/golang/geo/s2"
+)
+
+// MaxCellLevel - самый мелкий уровень S2 ячеек
+const MaxCellLevel = 30
+
+// CellLevel - уровень S2 ячейки из настроек; вне 1..MaxCellLevel - уровень по умолчанию defaultLevel
+func CellLevel(level, defaultLevel int) int {
+	if level <= 0 || level > MaxCellLevel {
+		return defaultLevel
+	}
+	return level
+}
+
+// Cell - S2 ячейка уровня level, в которую попадает точка. Близкие точки попадают в одну ячейку,
+// поэтому ячейка - ключ кэша для ответов по точке
+func Cell(lat, lon float64, level int) s2.CellID {
+	return s2.CellIDFromLatLng(s2.LatLngFromDegrees(lat, lon)).Parent(level)
+}
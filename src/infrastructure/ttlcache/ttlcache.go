@@ -0,0 +1,97 @@
+package ttlcache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Причины вытеснения записи из кэша
+const (
+	// EvictExpired - истекло время жизни записи
+	EvictExpired = "expired"
+	// EvictCapacity - в кэше больше MaxEntries записей, вытесняется самая давно использованная
+	EvictCapacity = "capacity"
+)
+
+// EvictFunc - вызывается при вытеснении записи под мьютексом кэша, поэтому не должна обращаться к кэшу
+type EvictFunc func(key, value interface{}, cause string)
+
+type entry struct {
+	key     interface{}
+	value   interface{}
+	expires time.Time
+}
+
+// Cache - LRU кэш с временем жизни записей. Ключи должны быть сравнимыми. Безопасен для одновременного использования
+type Cache struct {
+	maxEntries int
+	onEvict    EvictFunc
+	mu         sync.Mutex
+	entries    map[interface{}]*list.Element
+	order      *list.List
+	// Now - текущее время, подменяется в тестах
+	Now func() time.Time
+}
+
+// New - кэш не больше чем на maxEntries записей; onEvict может быть nil
+func New(maxEntries int, onEvict EvictFunc) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		onEvict:    onEvict,
+		entries:    make(map[interface{}]*list.Element),
+		order:      list.New(),
+		Now:        time.Now,
+	}
+}
+
+// Get - значение по ключу. Запись с истекшим временем жизни удаляется
+func (c *Cache) Get(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.Now().After(elem.Value.(*entry).expires) {
+		c.remove(elem, EvictExpired)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*entry).value, true
+}
+
+// Put - сохраняем значение на время ttl; ttl <= 0 - не сохраняем
+func (c *Cache) Put(key, value interface{}, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	e := &entry{key: key, value: value, expires: c.Now().Add(ttl)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = e
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(e)
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back(), EvictCapacity)
+	}
+}
+
+// Len - количество записей, включая те, у которых истекло время жизни, но которые еще не удалены
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache) remove(elem *list.Element, cause string) {
+	e := elem.Value.(*entry)
+	c.order.Remove(elem)
+	delete(c.entries, e.key)
+	if c.onEvict != nil {
+		c.onEvict(e.key, e.value, cause)
+	}
+}
@@ -0,0 +1,51 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var evicted []string
+	c := New(2, func(key, value interface{}, cause string) {
+		evicted = append(evicted, key.(string)+" "+cause)
+	})
+	c.Now = func() time.Time { return now }
+
+	c.Put("a", 1, time.Minute)
+	c.Put("zero", 0, 0)
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	_, ok = c.Get("zero")
+	assert.False(t, ok, "ttl <= 0 is not cached")
+
+	now = now.Add(time.Minute + time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "expired")
+	assert.Equal(t, 0, c.Len())
+
+	c.Put("a", 1, time.Minute)
+	c.Put("b", 2, time.Minute)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	c.Put("c", 3, time.Minute)
+	_, ok = c.Get("b")
+	assert.False(t, ok, "least recently used is evicted")
+	c.Put("a", 4, time.Minute)
+	value, _ = c.Get("a")
+	assert.Equal(t, 4, value, "put replaces the value")
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, []string{"a expired", "b capacity"}, evicted)
+}
+
+func TestCell(t *testing.T) {
+	assert.Equal(t, 16, CellLevel(0, 16))
+	assert.Equal(t, 16, CellLevel(MaxCellLevel+1, 16))
+	assert.Equal(t, 12, CellLevel(12, 16))
+	assert.Equal(t, Cell(55.75700, 37.613, 16), Cell(55.75701, 37.613, 16))
+	assert.NotEqual(t, Cell(55.757, 37.613, 16), Cell(55.767, 37.613, 16))
+}
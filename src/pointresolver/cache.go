@@ -0,0 +1,124 @@
+package pointresolver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/collector"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/ttlcache"
+	"github.com/nburunova/taxi-backend-sample/src/webapi"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultCacheLevel - уровень S2 ячейки по умолчанию, ~40 метров
+	defaultCacheLevel = 18
+	// defaultCacheSize - сколько ячеек храним по умолчанию
+	defaultCacheSize = 50000
+)
+
+// CacheSettings - настройки кэша адресов. Адрес точки запоминается для всей S2 ячейки уровня Level,
+// "адрес не найден" от WebAPI запоминается на более короткое время NegativeTTL.
+// Кэш выключен, если TTL не задан
+type CacheSettings struct {
+	// Level - уровень S2 ячейки, к которой привязываем точку
+	Level int `json:"s2_level"`
+	// MaxEntries - максимальное количество ячеек, самые давно использованные вытесняются
+	MaxEntries int `json:"max_entries"`
+	// TTL - время жизни найденного адреса
+	TTL duration.Milliseconds `json:"ttl_ms"`
+	// NegativeTTL - время жизни ответа "адрес не найден"; 0 - такие ответы не кэшируем
+	NegativeTTL duration.Milliseconds `json:"negative_ttl_ms"`
+}
+
+// IsEmpty - кэш не настроен
+func (cs CacheSettings) IsEmpty() bool {
+	return cs.TTL == 0
+}
+
+type addressEntry struct {
+	info webapi.PointInfo
+	err  error
+}
+
+// geocodeFunc - обратное геокодирование точки
+type geocodeFunc func(ctx context.Context, lat, lon float64) (*webapi.PointInfo, error)
+
+// addressCache - LRU кэш ответов WebAPI по S2 ячейкам
+type addressCache struct {
+	settings CacheSettings
+	geocode  geocodeFunc
+	cache    *ttlcache.Cache
+	mu       sync.Mutex
+	hits     int
+	lookups  int
+	coll     *collector.Collector
+}
+
+func newAddressCache(settings CacheSettings, geocode geocodeFunc, coll *collector.Collector) *addressCache {
+	settings.Level = ttlcache.CellLevel(settings.Level, defaultCacheLevel)
+	if settings.MaxEntries <= 0 {
+		settings.MaxEntries = defaultCacheSize
+	}
+	return &addressCache{
+		settings: settings,
+		geocode:  geocode,
+		cache:    ttlcache.New(settings.MaxEntries, nil),
+		coll:     coll,
+	}
+}
+
+// isNegative - ответы WebAPI, которые означают, что адреса у точки нет, а не что WebAPI недоступен
+func isNegative(err error) bool {
+	cause := errors.Cause(err)
+	return cause == webapi.ErrAddressNotFound || cause == webapi.ErrEmptyResult
+}
+
+// Address - адрес точки из кэша или из WebAPI
+func (ac *addressCache) Address(ctx context.Context, lat, lon float64) (*webapi.PointInfo, error) {
+	cellID := ttlcache.Cell(lat, lon, ac.settings.Level)
+	if value, ok := ac.cache.Get(cellID); ok {
+		entry := value.(addressEntry)
+		ac.collect(true, entry.err != nil)
+		info := entry.info
+		return &info, entry.err
+	}
+	ac.collect(false, false)
+	info, err := ac.geocode(ctx, lat, lon)
+	if err == nil {
+		ac.put(cellID, info, nil, ac.settings.TTL.Duration())
+	} else if isNegative(err) {
+		ac.put(cellID, info, err, ac.settings.NegativeTTL.Duration())
+	}
+	return info, err
+}
+
+func (ac *addressCache) put(key interface{}, info *webapi.PointInfo, err error, ttl time.Duration) {
+	entry := addressEntry{err: err}
+	if info != nil {
+		entry.info = *info
+	}
+	ac.cache.Put(key, entry, ttl)
+}
+
+// collect - считаем долю попаданий в кэш
+func (ac *addressCache) collect(hit, negative bool) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.lookups++
+	result := "miss"
+	if hit {
+		ac.hits++
+		result = "hit"
+		if negative {
+			result = "negative_hit"
+		}
+	}
+	if ac.coll == nil {
+		return
+	}
+	ac.coll.AddAddressCacheLookup(result)
+	ac.coll.SetAddressCacheHitRatio(float64(ac.hits) / float64(ac.lookups))
+}
@@ -0,0 +1,95 @@
+package pointresolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/webapi"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testGeocoder struct {
+	calls int
+	info  *webapi.PointInfo
+	err   error
+}
+
+func (g *testGeocoder) geocode(ctx context.Context, lat, lon float64) (*webapi.PointInfo, error) {
+	g.calls++
+	return g.info, g.err
+}
+
+var testCacheSettings = CacheSettings{
+	Level:       18,
+	MaxEntries:  2,
+	TTL:         duration.Milliseconds(time.Hour),
+	NegativeTTL: duration.Milliseconds(time.Minute),
+}
+
+func newTestAddressCache(g *testGeocoder) (*addressCache, *time.Time) {
+	now := time.Unix(1000, 0)
+	ac := newAddressCache(testCacheSettings, g.geocode, nil)
+	ac.cache.Now = func() time.Time { return now }
+	return ac, &now
+}
+
+func TestAddressCacheHit(t *testing.T) {
+	g := &testGeocoder{info: &webapi.PointInfo{Address: "Тверская, 1", Lat: 55.757, Lon: 37.613}}
+	ac, now := newTestAddressCache(g)
+	for _, lat := range []float64{55.75700, 55.75701} {
+		info, err := ac.Address(context.Background(), lat, 37.613)
+		assert.Nil(t, err)
+		assert.Equal(t, "Тверская, 1", info.Address)
+	}
+	assert.Equal(t, 1, g.calls)
+
+	_, err := ac.Address(context.Background(), 55.76, 37.613)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, g.calls, "other cell")
+
+	*now = now.Add(testCacheSettings.TTL.Duration() + time.Second)
+	_, err = ac.Address(context.Background(), 55.757, 37.613)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, g.calls, "expired")
+	assert.Equal(t, 1, ac.hits)
+	assert.Equal(t, 4, ac.lookups)
+}
+
+func TestAddressCacheNegative(t *testing.T) {
+	g := &testGeocoder{info: &webapi.PointInfo{}, err: webapi.ErrAddressNotFound}
+	ac, now := newTestAddressCache(g)
+	for i := 0; i < 2; i++ {
+		_, err := ac.Address(context.Background(), 55.757, 37.613)
+		assert.Equal(t, webapi.ErrAddressNotFound, err)
+	}
+	assert.Equal(t, 1, g.calls)
+
+	*now = now.Add(testCacheSettings.NegativeTTL.Duration() + time.Second)
+	_, err := ac.Address(context.Background(), 55.757, 37.613)
+	assert.Equal(t, webapi.ErrAddressNotFound, err)
+	assert.Equal(t, 2, g.calls, "negative answers expire sooner")
+}
+
+func TestAddressCacheSkipsErrors(t *testing.T) {
+	g := &testGeocoder{info: &webapi.PointInfo{}, err: errors.New("webAPI: Cannot request webAPI geo point info")}
+	ac, _ := newTestAddressCache(g)
+	for i := 0; i < 2; i++ {
+		_, err := ac.Address(context.Background(), 55.757, 37.613)
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, 2, g.calls)
+}
+
+func TestAddressCacheCapacity(t *testing.T) {
+	g := &testGeocoder{info: &webapi.PointInfo{Address: "адрес"}}
+	ac, _ := newTestAddressCache(g)
+	for _, lat := range []float64{55.1, 55.2, 55.3} {
+		ac.Address(context.Background(), lat, 37.613)
+	}
+	assert.Equal(t, 2, ac.cache.Len())
+	ac.Address(context.Background(), 55.1, 37.613)
+	assert.Equal(t, 4, g.calls, "oldest cell is evicted")
+}
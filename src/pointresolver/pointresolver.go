@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/collector"
 	"github.com/nburunova/taxi-backend-sample/src/webapi"
 )
 
@@ -11,6 +12,7 @@ import (
 type PointResolver struct {
 	webAPIcl *webapi.Client
 	ars      *areas
+	cache    *addressCache
 }
 
 // NewPointResolver - создает объект типа PointResolver, который отдает строку адреса и область точки (lat, lon)
@@ -22,11 +24,24 @@ func NewPointResolver(wAPIcl *webapi.Client) (*PointResolver, error) {
 	return &PointResolver{
 		wAPIcl,
 		ar,
+		nil,
 	}, nil
 }
 
+// WithCache - включаем кэш адресов по S2 ячейкам; пустые настройки кэш не включают
+func (pr *PointResolver) WithCache(settings CacheSettings, coll *collector.Collector) *PointResolver {
+	if settings.IsEmpty() {
+		return pr
+	}
+	pr.cache = newAddressCache(settings, pr.webAPIcl.GetPointInfo, coll)
+	return pr
+}
+
 // Address - возвращает ближайший адрес к точке в виде строки
 func (pr *PointResolver) Address(ctx context.Context, lat, lon float64) (*webapi.PointInfo, error) {
+	if pr.cache != nil {
+		return pr.cache.Address(ctx, lat, lon)
+	}
 	return pr.webAPIcl.GetPointInfo(ctx, lat, lon)
 }
 
@@ -121,6 +121,25 @@ func (p *Product) GetOperator(displayName string, urlReplace map[string]string)
 	}, errorswrapper.WrapErrorSlice(errorsOperator)
 }
 
+// GetBareOperator - оператор без ссылок на приложение и сторы: для ответа только с ценами провайдеров
+func (p *Product) GetBareOperator(displayName string) Operator {
+	apiID := strconv.FormatInt(p.APIID, 10)
+	apiOrgID := strconv.FormatInt(p.APIOrgID, 10)
+	if displayName == "" {
+		displayName = p.Title
+	}
+	return Operator{
+		IsOptimal:       p.IsOptimal,
+		BranchID:        &apiID,
+		OrgID:           &apiOrgID,
+		BackgroundColor: backgroundColor,
+		TextColor:       textColor,
+		ShortTitle:      p.ShortTitle,
+		ID:              &p.ID,
+		Title:           &displayName,
+	}
+}
+
 // IsGoodTariff - проверяет, находится ли тариф в белом списке
 func (p *Product) IsGoodTariff(tariff string) bool {
 	// если в списке тарифов одно значение -
@@ -0,0 +1,68 @@
+package taxi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/api"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrBatchUnsupported - сервис не поддерживает пакетные запросы
+	ErrBatchUnsupported = errors.New("Batch is not supported")
+)
+
+// BatchService - сервис такси, который умеет выполнять пакет запросов
+type BatchService interface {
+	Service
+	ParseBatchRequest(ctx context.Context, r *http.Request) (service.BatchRequest, error)
+	BatchResponse(ctx context.Context, batch service.BatchRequest, itemWaitTime time.Duration, priceCoeff func(regionID int) float64) []service.BatchItem
+}
+
+// batchResponse - ответ на пакет запросов: результаты в порядке запросов
+type batchResponse struct {
+	Results []batchResult `json:"results"`
+}
+
+// batchResult - результат одного запроса пакета: ответ как у /calculate или ошибка как у /calculate
+type batchResult struct {
+	Response *service.Response `json:"response,omitempty"`
+	Error    *errResponse      `json:"error,omitempty"`
+}
+
+func newBatchResponse(ctx context.Context, items []service.BatchItem) batchResponse {
+	results := make([]batchResult, len(items))
+	for i, item := range items {
+		if item.Err != nil {
+			results[i].Error = newErrResponse(ctx, item.Err, service.ErrClassInternal)
+			continue
+		}
+		results[i].Response = item.Response
+	}
+	return batchResponse{Results: results}
+}
+
+// BatchHandler - хендлер пакета запросов данных такси. Пакет целиком отвечает 200, если его удалось разобрать;
+// ошибки отдельных запросов лежат в их результатах. waitTime - дедлайн каждого запроса пакета
+func BatchHandler(srv Service, basicPriceCoeff float64, regPriceCoeff RegionPriceCoeff, waitTime time.Duration, w http.ResponseWriter, r *http.Request) {
+	batchSrv, ok := srv.(BatchService)
+	if !ok {
+		render.Render(w, r, errServerError(r.Context(), ErrBatchUnsupported))
+		return
+	}
+	api.SetRequestMode(r.Context(), service.ModeBatch)
+	batch, errParse := batchSrv.ParseBatchRequest(r.Context(), r)
+	if errParse != nil {
+		render.Render(w, r, errInvalidRequest(r.Context(), errParse))
+		return
+	}
+	priceCoeff := func(regionID int) float64 {
+		return regPriceCoeff.GetByRegionOrElse(regionID, basicPriceCoeff)
+	}
+	items := batchSrv.BatchResponse(r.Context(), batch, waitTime, priceCoeff)
+	render.JSON(w, r, newBatchResponse(r.Context(), items))
+}
@@ -1,18 +1,42 @@
 package taxi
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/go-chi/render"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"google.golang.org/grpc/codes"
 )
 
+// ctxKeyDebugErrors - ключ контекста: отдавать ли клиенту текст внутренней ошибки (HandlerSettings.DebugErrors)
+type ctxKeyDebugErrors struct{}
+
+func withDebugErrors(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, ctxKeyDebugErrors{}, enabled)
+}
+
+func isDebugErrors(ctx context.Context) bool {
+	enabled, _ := ctx.Value(ctxKeyDebugErrors{}).(bool)
+	return enabled
+}
+
 type errResponse struct {
-	Err            error `json:"-"` // low-level runtime error
-	HTTPStatusCode int   `json:"-"` // http response status code
+	Err            error      `json:"-"` // low-level runtime error
+	HTTPStatusCode int        `json:"-"` // http response status code
+	GRPCCode       codes.Code `json:"-"` // grpc status code
 
 	StatusText string `json:"status"`          // user-level status message
 	AppCode    int64  `json:"code,omitempty"`  // application-specific error code
 	ErrorText  string `json:"error,omitempty"` // application-level error message, for debugging
+
+	Fields []fieldMessage `json:"fields,omitempty"` // invalid request fields, always shown
+}
+
+// fieldMessage - ошибка поля запроса в ответе API
+type fieldMessage struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func (e *errResponse) Render(w http.ResponseWriter, r *http.Request) error {
@@ -20,20 +44,55 @@ func (e *errResponse) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
-func errInvalidRequest(err error) render.Renderer {
-	return &errResponse{
-		Err:            err,
-		HTTPStatusCode: http.StatusBadRequest,
-		StatusText:     "Invalid request",
-		ErrorText:      err.Error(),
-	}
+// errClass - HTTP код, gRPC код, статус и код ошибки приложения для класса ошибки сервиса.
+// Коды ошибок приложения стабильны, клиенты на них опираются
+type errClass struct {
+	httpStatusCode int
+	grpcCode       codes.Code
+	statusText     string
+	appCode        int64
 }
 
-func errServerError(err error) render.Renderer {
-	return &errResponse{
+var errClasses = map[service.ErrorClass]errClass{
+	service.ErrClassInternal:        {http.StatusInternalServerError, codes.Internal, "Internal error", 50001},
+	service.ErrClassBadRequest:      {http.StatusBadRequest, codes.InvalidArgument, "Invalid request", 40001},
+	service.ErrClassRegionNotServed: {http.StatusUnprocessableEntity, codes.FailedPrecondition, "Region is not served", 42201},
+	service.ErrClassNoProviders:     {http.StatusNotFound, codes.NotFound, "Not found", 40401},
+	service.ErrClassUpstreamTimeout: {http.StatusGatewayTimeout, codes.DeadlineExceeded, "Providers timeout", 50401},
+	service.ErrClassGeocodingFailed: {http.StatusBadGateway, codes.Unavailable, "Geocoding failed", 50201},
+}
+
+// errServiceError - ответ на ошибку сервиса по ее классу; ошибки без класса получают класс fallback
+func errServiceError(ctx context.Context, err error, fallback service.ErrorClass) render.Renderer {
+	return newErrResponse(ctx, err, fallback)
+}
+
+func newErrResponse(ctx context.Context, err error, fallback service.ErrorClass) *errResponse {
+	class := service.ClassOf(err)
+	if class == service.ErrClassInternal {
+		class = fallback
+	}
+	ec := errClasses[class]
+	resp := &errResponse{
 		Err:            err,
-		HTTPStatusCode: http.StatusNotFound,
-		StatusText:     "Not found",
-		ErrorText:      err.Error(),
+		HTTPStatusCode: ec.httpStatusCode,
+		GRPCCode:       ec.grpcCode,
+		StatusText:     ec.statusText,
+		AppCode:        ec.appCode,
+	}
+	if isDebugErrors(ctx) {
+		resp.ErrorText = err.Error()
 	}
+	for _, fe := range service.FieldErrors(err) {
+		resp.Fields = append(resp.Fields, fieldMessage{Field: fe.Field, Message: fe.Message})
+	}
+	return resp
+}
+
+func errInvalidRequest(ctx context.Context, err error) render.Renderer {
+	return errServiceError(ctx, err, service.ErrClassBadRequest)
+}
+
+func errServerError(ctx context.Context, err error) render.Renderer {
+	return errServiceError(ctx, err, service.ErrClassInternal)
 }
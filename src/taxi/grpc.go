@@ -0,0 +1,150 @@
+package taxi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/taxipb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrUnknownStreamEvent - у события потокового ответа данные, которые gRPC API не умеет передать
+	ErrUnknownStreamEvent = errors.New("Unknown stream event")
+)
+
+// GRPCService - сервис такси для gRPC API: запрос приходит уже разобранным, сервис его проверяет
+type GRPCService interface {
+	StreamService
+	CheckTaxiRequest(ctx context.Context, clientHeader string, taxiReq *service.Request) error
+	IsOK() bool
+}
+
+// GRPCHandler - gRPC API Таксы (taxipb.TaxiServer) поверх того же сервиса и настроек, что и REST хендлеры.
+// Ошибки - gRPC статус по классу ошибки сервиса с taxipb.Error в деталях
+type GRPCHandler struct {
+	srv GRPCService
+	cfg *HandlerConfig
+}
+
+// NewGRPCHandler - создаем gRPC API; настройки берутся из cfg на каждый запрос
+func NewGRPCHandler(srv GRPCService, cfg *HandlerConfig) *GRPCHandler {
+	return &GRPCHandler{srv: srv, cfg: cfg}
+}
+
+// Calculate - то же, что Handler
+func (h *GRPCHandler) Calculate(ctx context.Context, req *taxipb.CalculateRequest) (*taxipb.CalculateResponse, error) {
+	hs := h.cfg.Load()
+	ctxTaxi, cancelTaxi := context.WithTimeout(grpcRequestContext(ctx, hs), hs.WaitTime)
+	defer cancelTaxi()
+	taxiReq, errReq := h.taxiRequest(ctxTaxi, req)
+	if errReq != nil {
+		return nil, errReq
+	}
+	ctxTaxi = context.WithValue(ctxTaxi, log.CtxKeyRegionID, taxiReq.RegionID)
+	coeff := hs.RegPriceCoeff.GetByRegionOrElse(taxiReq.RegionID, hs.PriceCoeff)
+	response, errResponse := h.srv.Response(ctxTaxi, taxiReq, coeff)
+	if errResponse != nil {
+		return nil, grpcError(ctxTaxi, errResponse, service.ErrClassInternal)
+	}
+	return responsePB(response), nil
+}
+
+// CalculateStream - то же, что StreamHandler: события приходят в том же порядке.
+// Ошибки до начала потока возвращаются статусом, после - последним событием error
+func (h *GRPCHandler) CalculateStream(req *taxipb.CalculateRequest, stream taxipb.Taxi_CalculateStreamServer) error {
+	hs := h.cfg.Load()
+	ctxTaxi, cancelTaxi := context.WithTimeout(grpcRequestContext(stream.Context(), hs), hs.WaitTime)
+	defer cancelTaxi()
+	taxiReq, errReq := h.taxiRequest(ctxTaxi, req)
+	if errReq != nil {
+		return errReq
+	}
+	ctxTaxi = context.WithValue(ctxTaxi, log.CtxKeyRegionID, taxiReq.RegionID)
+	coeff := hs.RegPriceCoeff.GetByRegionOrElse(taxiReq.RegionID, hs.PriceCoeff)
+	var errSend error
+	emit := func(event service.StreamEvent) {
+		if errSend == nil {
+			errSend = stream.Send(eventPB(ctxTaxi, event))
+		}
+	}
+	if errResponse := h.srv.StreamResponse(ctxTaxi, taxiReq, coeff, emit); errResponse != nil {
+		resp := newErrResponse(ctxTaxi, errResponse, service.ErrClassInternal)
+		emit(service.StreamEvent{Name: service.EventError, Data: resp})
+	}
+	return errSend
+}
+
+// Health - то же, что /healthcheck
+func (h *GRPCHandler) Health(ctx context.Context, req *taxipb.HealthRequest) (*taxipb.HealthResponse, error) {
+	return &taxipb.HealthResponse{Ok: h.srv.IsOK()}, nil
+}
+
+// taxiRequest - проверяем и обогащаем запрос, как Handler до опроса провайдеров
+func (h *GRPCHandler) taxiRequest(ctx context.Context, req *taxipb.CalculateRequest) (service.Request, error) {
+	taxiReq, errParse := requestFromPB(req)
+	if errParse != nil {
+		return taxiReq, grpcError(ctx, errParse, service.ErrClassBadRequest)
+	}
+	taxiReq.ReqID = middleware.GetReqID(ctx)
+	if errCheck := h.srv.CheckTaxiRequest(ctx, req.ClientId, &taxiReq); errCheck != nil {
+		return taxiReq, grpcError(ctx, errCheck, service.ErrClassBadRequest)
+	}
+	ctx = context.WithValue(ctx, log.CtxKeyRegionID, taxiReq.RegionID)
+	if errEval := h.srv.EvaluateTaxiRequest(ctx, &taxiReq); errEval != nil {
+		return taxiReq, grpcError(ctx, errEval, service.ErrClassInternal)
+	}
+	return taxiReq, nil
+}
+
+// grpcRequestContext - идентификатор запроса для логов и запросов к провайдерам, как у middleware.RequestID,
+// и настройки ответов на ошибки, как у ReloadableHandler
+func grpcRequestContext(ctx context.Context, hs HandlerSettings) context.Context {
+	ctx = withDebugErrors(ctx, hs.DebugErrors)
+	return context.WithValue(ctx, middleware.RequestIDKey, fmt.Sprintf("grpc-%06d", middleware.NextRequestID()))
+}
+
+// grpcError - gRPC статус для ошибки сервиса по ее классу; ошибки без класса получают класс fallback
+func grpcError(ctx context.Context, err error, fallback service.ErrorClass) error {
+	resp := newErrResponse(ctx, err, fallback)
+	st := status.New(resp.GRPCCode, resp.StatusText)
+	if withDetails, errDetails := st.WithDetails(errorPB(resp)); errDetails == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// requestFromPB - service.Request из запроса gRPC API; значения проверяет сервис
+func requestFromPB(req *taxipb.CalculateRequest) (service.Request, error) {
+	taxiReq := service.Request{
+		RegionID:      int(req.RegionId),
+		Point1:        pointFromPB(req.Point1),
+		Point2:        pointFromPB(req.Point2),
+		OnlyAPI:       req.OnlyApi,
+		UserID:        req.UserId,
+		WithProviders: req.WithProviders,
+	}
+	for _, p := range req.Waypoints {
+		taxiReq.Waypoints = append(taxiReq.Waypoints, pointFromPB(p))
+	}
+	if req.PickupTime != nil {
+		pickup, errTime := ptypes.Timestamp(req.PickupTime)
+		if errTime != nil {
+			return taxiReq, service.WithErrorClass(errors.Wrap(errTime, "Invalid pickup_time"), service.ErrClassBadRequest)
+		}
+		taxiReq.PickupTime = &pickup
+	}
+	return taxiReq, nil
+}
+
+func pointFromPB(p *taxipb.Point) service.Point {
+	if p == nil {
+		return service.Point{}
+	}
+	return service.Point{Lat: p.Lat, Lon: p.Lon, Address: p.Address}
+}
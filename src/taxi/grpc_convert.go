@@ -0,0 +1,188 @@
+package taxi
+
+import (
+	"context"
+
+	"github.com/nburunova/taxi-backend-sample/src/product"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/taxipb"
+	"github.com/pkg/errors"
+)
+
+// Ответ сервиса в сообщениях taxipb. Поля, которые в JSON могут быть null, - в обертках или пустые
+
+// eventPB - событие потокового ответа; данные неизвестного типа отдаем событием error
+func eventPB(ctx context.Context, event service.StreamEvent) *taxipb.CalculateEvent {
+	switch data := event.Data.(type) {
+	case service.ProviderRecords:
+		return &taxipb.CalculateEvent{Event: &taxipb.CalculateEvent_Records{Records: &taxipb.ProviderRecords{
+			Provider: data.Provider,
+			Results:  recordsPB(data.Results),
+		}}}
+	case *service.Meta:
+		return &taxipb.CalculateEvent{Event: &taxipb.CalculateEvent_Meta{Meta: metaPB(data)}}
+	case *service.Response:
+		return &taxipb.CalculateEvent{Event: &taxipb.CalculateEvent_Optimal{Optimal: responsePB(data)}}
+	case *errResponse:
+		return &taxipb.CalculateEvent{Event: &taxipb.CalculateEvent_Error{Error: errorPB(data)}}
+	}
+	return &taxipb.CalculateEvent{Event: &taxipb.CalculateEvent_Error{Error: errorPB(newErrResponse(ctx, errors.Wrap(ErrUnknownStreamEvent, event.Name), service.ErrClassInternal))}}
+}
+
+func responsePB(resp *service.Response) *taxipb.CalculateResponse {
+	return &taxipb.CalculateResponse{
+		Results: &taxipb.Results{
+			Id:      int32(resp.Result.ID),
+			Optimal: resultBlockPB(resp.Result.Optimal),
+			Else:    resultBlockPB(resp.Result.Else),
+		},
+		Meta:      metaPB(resp.Meta),
+		Providers: statusesPB(resp.Providers),
+	}
+}
+
+func resultBlockPB(block *service.ResultBlock) *taxipb.ResultBlock {
+	if block == nil {
+		return nil
+	}
+	return &taxipb.ResultBlock{
+		Title:   block.Title,
+		Summary: block.Summary,
+		Results: recordsPB(block.Results),
+	}
+}
+
+func recordsPB(records []service.Record) []*taxipb.Record {
+	pbs := make([]*taxipb.Record, len(records))
+	for i, r := range records {
+		pbs[i] = &taxipb.Record{
+			AvgEta:         int32PB(r.AvgEta),
+			Price:          int32(r.Price),
+			PriceRanges:    priceRangesPB(r.PriceRanges),
+			Rating:         floatPB(r.Rating),
+			Operator:       operatorPB(r.Operator),
+			Eta:            int32PB(r.Eta),
+			CurrencyCode:   stringPB(r.CurrencyCode),
+			PriceBreakdown: breakdownPB(r.Breakdown),
+		}
+	}
+	return pbs
+}
+
+func priceRangesPB(pr *service.PriceRanges) *taxipb.PriceRanges {
+	if pr == nil {
+		return nil
+	}
+	return &taxipb.PriceRanges{Min: int32PB(pr.Min), Max: int32PB(pr.Max)}
+}
+
+func breakdownPB(b *service.PriceBreakdown) *taxipb.PriceBreakdown {
+	if b == nil {
+		return nil
+	}
+	pb := &taxipb.PriceBreakdown{
+		MinimumFare:  int32PB(b.MinimumFare),
+		CurrencyCode: stringPB(b.CurrencyCode),
+		Distance:     int32PB(b.Distance),
+		Duration:     int32PB(b.Duration),
+	}
+	if b.SurgeMultiplier != nil {
+		pb.SurgeMultiplier = &taxipb.FloatValue{Value: float32(*b.SurgeMultiplier)}
+	}
+	return pb
+}
+
+func operatorPB(op product.Operator) *taxipb.Operator {
+	pb := &taxipb.Operator{
+		BranchId:        stringPB(op.BranchID),
+		Url:             stringPB(op.URL),
+		Image:           stringPB(op.Image),
+		BackgroundColor: op.BackgroundColor,
+		ShortTitle:      stringPB(op.ShortTitle),
+		TextColor:       op.TextColor,
+		Title:           stringPB(op.Title),
+		OrgId:           stringPB(op.OrgID),
+	}
+	if op.ID != nil {
+		pb.Id = int32(*op.ID)
+	}
+	if op.Site != nil {
+		pb.Site = linkPB(op.Site.Value, op.Site.Text)
+	}
+	if op.Phone != nil {
+		pb.Phone = linkPB(op.Phone.Value, op.Phone.Text)
+	}
+	if op.StoreURLs != nil {
+		pb.StoreUrls = &taxipb.StoreURLs{}
+		if ios := op.StoreURLs.Ios; ios != nil {
+			pb.StoreUrls.Ios = &taxipb.StoreURL{Id: ios.ID, Url: ios.URL}
+		}
+		if android := op.StoreURLs.Android; android != nil {
+			pb.StoreUrls.Android = &taxipb.StoreURL{Id: android.ID, Url: android.URL}
+		}
+	}
+	return pb
+}
+
+func linkPB(value, text *string) *taxipb.Link {
+	return &taxipb.Link{Value: stringPB(value), Text: stringPB(text)}
+}
+
+func metaPB(m *service.Meta) *taxipb.Meta {
+	if m == nil {
+		return nil
+	}
+	return &taxipb.Meta{
+		Distance:    int32PB(m.Distance),
+		Time:        int32PB(m.Time),
+		Source:      m.Source,
+		Warnings:    m.Warnings,
+		Experiment:  m.Experiment,
+		Variant:     m.Variant,
+		Unsupported: statusesPB(m.Unsupported),
+	}
+}
+
+func statusesPB(statuses []service.ProviderStatus) []*taxipb.ProviderStatus {
+	if len(statuses) == 0 {
+		return nil
+	}
+	pbs := make([]*taxipb.ProviderStatus, len(statuses))
+	for i, s := range statuses {
+		pbs[i] = &taxipb.ProviderStatus{Name: s.Name, Status: s.Status, LatencyMs: int32(s.Latency)}
+	}
+	return pbs
+}
+
+func errorPB(resp *errResponse) *taxipb.Error {
+	pb := &taxipb.Error{
+		Status: resp.StatusText,
+		Code:   resp.AppCode,
+		Error:  resp.ErrorText,
+	}
+	for _, f := range resp.Fields {
+		pb.Fields = append(pb.Fields, &taxipb.FieldError{Field: f.Field, Message: f.Message})
+	}
+	return pb
+}
+
+func int32PB(v *int) *taxipb.Int32Value {
+	if v == nil {
+		return nil
+	}
+	return &taxipb.Int32Value{Value: int32(*v)}
+}
+
+func floatPB(v *float32) *taxipb.FloatValue {
+	if v == nil {
+		return nil
+	}
+	return &taxipb.FloatValue{Value: *v}
+}
+
+func stringPB(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
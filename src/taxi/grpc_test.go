@@ -0,0 +1,231 @@
+package taxi
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/taxipb"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockGRPCService struct {
+	errCheck    error
+	errResponse error
+	// clientHeader, req, coeff - с чем сервис вызван последний раз
+	clientHeader string
+	req          service.Request
+	coeff        float64
+}
+
+func (m *mockGRPCService) ParseTaxiRequest(ctx context.Context, r *http.Request) (service.Request, error) {
+	return service.Request{}, errors.New("Not an HTTP request")
+}
+
+func (m *mockGRPCService) CheckTaxiRequest(ctx context.Context, clientHeader string, taxiReq *service.Request) error {
+	m.clientHeader = clientHeader
+	return m.errCheck
+}
+
+func (m *mockGRPCService) EvaluateTaxiRequest(ctx context.Context, taxiReq *service.Request) error {
+	taxiReq.Point1.Address = "evaluated"
+	return nil
+}
+
+func (m *mockGRPCService) Response(ctx context.Context, req service.Request, priceCoeff float64) (*service.Response, error) {
+	m.req, m.coeff = req, priceCoeff
+	if m.errResponse != nil {
+		return nil, m.errResponse
+	}
+	return testGRPCResponse(), nil
+}
+
+func (m *mockGRPCService) StreamResponse(ctx context.Context, req service.Request, priceCoeff float64, emit func(service.StreamEvent)) error {
+	m.req, m.coeff = req, priceCoeff
+	resp := testGRPCResponse()
+	emit(service.StreamEvent{Name: service.EventRecords, Data: service.ProviderRecords{Provider: "test1", Results: resp.Result.Optimal.Results}})
+	if m.errResponse != nil {
+		return m.errResponse
+	}
+	emit(service.StreamEvent{Name: service.EventMeta, Data: resp.Meta})
+	emit(service.StreamEvent{Name: service.EventOptimal, Data: resp})
+	return nil
+}
+
+func (m *mockGRPCService) IsOK() bool {
+	return true
+}
+
+func testGRPCResponse() *service.Response {
+	eta, distance, surge := 5, 1200, 1.5
+	resp := &service.Response{
+		Meta:      &service.Meta{Distance: &distance, Warnings: []string{service.WarningRouting}},
+		Providers: []service.ProviderStatus{{Name: "test1", Status: service.ProviderOK, Latency: 12}},
+	}
+	resp.Result.ID = -1
+	resp.Result.Optimal = &service.ResultBlock{Results: []service.Record{
+		{Price: 100, Eta: &eta, Breakdown: &service.PriceBreakdown{SurgeMultiplier: &surge}},
+	}}
+	return resp
+}
+
+func testGRPCRequest() *taxipb.CalculateRequest {
+	return &taxipb.CalculateRequest{
+		RegionId:  99,
+		Point1:    &taxipb.Point{Lat: 55.1, Lon: 37.1},
+		Point2:    &taxipb.Point{Lat: 55.2, Lon: 37.2, Address: "address2"},
+		Waypoints: []*taxipb.Point{{Lat: 55.15, Lon: 37.15}},
+		ClientId:  "device-1",
+	}
+}
+
+// serveGRPC - gRPC API на свободном порту и клиент к нему
+func serveGRPC(t *testing.T, h *GRPCHandler) (taxipb.TaxiClient, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	taxipb.RegisterTaxiServer(srv, h)
+	go srv.Serve(lis)
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return taxipb.NewTaxiClient(conn), func() {
+		conn.Close()
+		srv.Stop()
+	}
+}
+
+func TestGRPCCalculate(t *testing.T) {
+	srv := &mockGRPCService{}
+	cfg := NewHandlerConfig(HandlerSettings{PriceCoeff: 1.3, RegPriceCoeff: map[int]float64{99: 1.0}, WaitTime: time.Second})
+	client, stop := serveGRPC(t, NewGRPCHandler(srv, cfg))
+	defer stop()
+
+	req := testGRPCRequest()
+	pickup := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	req.PickupTime, _ = ptypes.TimestampProto(pickup)
+	resp, err := client.Calculate(context.Background(), req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "device-1", srv.clientHeader)
+	assert.Equal(t, 1.0, srv.coeff)
+	assert.Equal(t, 99, srv.req.RegionID)
+	assert.Equal(t, "evaluated", srv.req.Point1.Address, "request is evaluated before the response")
+	assert.Equal(t, service.Point{Lat: 55.2, Lon: 37.2, Address: "address2"}, srv.req.Point2)
+	assert.Equal(t, []service.Point{{Lat: 55.15, Lon: 37.15}}, srv.req.Waypoints)
+	if assert.NotNil(t, srv.req.PickupTime) {
+		assert.True(t, pickup.Equal(*srv.req.PickupTime))
+	}
+	assert.NotEmpty(t, srv.req.ReqID)
+
+	assert.Equal(t, int32(-1), resp.Results.Id)
+	assert.Nil(t, resp.Results.Else)
+	if assert.Len(t, resp.Results.Optimal.Results, 1) {
+		record := resp.Results.Optimal.Results[0]
+		assert.Equal(t, int32(100), record.Price)
+		assert.Equal(t, &taxipb.Int32Value{Value: 5}, record.Eta)
+		assert.Nil(t, record.AvgEta, "null in JSON is an empty wrapper")
+		assert.Equal(t, &taxipb.FloatValue{Value: 1.5}, record.PriceBreakdown.SurgeMultiplier)
+	}
+	assert.Equal(t, &taxipb.Int32Value{Value: 1200}, resp.Meta.Distance)
+	assert.Nil(t, resp.Meta.Time)
+	assert.Equal(t, []string{service.WarningRouting}, resp.Meta.Warnings)
+	assert.Equal(t, []*taxipb.ProviderStatus{{Name: "test1", Status: service.ProviderOK, LatencyMs: 12}}, resp.Providers)
+
+	hs, err := client.Health(context.Background(), &taxipb.HealthRequest{})
+	assert.Nil(t, err)
+	assert.True(t, hs.Ok)
+}
+
+func TestGRPCErrors(t *testing.T) {
+	errMocked := errors.New("Mocked Service Fail")
+	fieldErr := &service.FieldError{Field: "point1", Message: "lat and lon are required", Err: service.ErrTaxiReqEmpty}
+	cases := []struct {
+		name    string
+		srv     *mockGRPCService
+		code    codes.Code
+		appCode int64
+		fields  int
+	}{
+		{"invalid request", &mockGRPCService{errCheck: service.WithErrorClass(fieldErr, service.ErrClassBadRequest)}, codes.InvalidArgument, 40001, 1},
+		{"unclassified check error", &mockGRPCService{errCheck: errMocked}, codes.InvalidArgument, 40001, 0},
+		{"region not served", &mockGRPCService{errResponse: service.WithErrorClass(errMocked, service.ErrClassRegionNotServed)}, codes.FailedPrecondition, 42201, 0},
+		{"upstream timeout", &mockGRPCService{errResponse: service.WithErrorClass(errMocked, service.ErrClassUpstreamTimeout)}, codes.DeadlineExceeded, 50401, 0},
+		{"internal", &mockGRPCService{errResponse: errMocked}, codes.Internal, 50001, 0},
+	}
+	cfg := NewHandlerConfig(HandlerSettings{PriceCoeff: 1.3, WaitTime: time.Second})
+	for _, c := range cases {
+		_, err := NewGRPCHandler(c.srv, cfg).Calculate(context.Background(), testGRPCRequest())
+		st, ok := status.FromError(err)
+		if !assert.True(t, ok, c.name) {
+			continue
+		}
+		assert.Equal(t, c.code, st.Code(), c.name)
+		if assert.Len(t, st.Details(), 1, c.name) {
+			pbErr := st.Details()[0].(*taxipb.Error)
+			assert.Equal(t, c.appCode, pbErr.Code, c.name)
+			assert.Equal(t, st.Message(), pbErr.Status, c.name)
+			assert.Empty(t, pbErr.Error, c.name)
+			assert.Len(t, pbErr.Fields, c.fields, c.name)
+		}
+	}
+}
+
+func TestGRPCCalculateStream(t *testing.T) {
+	cfg := NewHandlerConfig(HandlerSettings{PriceCoeff: 1.3, WaitTime: time.Second})
+	events := func(srv *mockGRPCService) ([]*taxipb.CalculateEvent, error) {
+		client, stop := serveGRPC(t, NewGRPCHandler(srv, cfg))
+		defer stop()
+		stream, err := client.CalculateStream(context.Background(), testGRPCRequest())
+		if err != nil {
+			return nil, err
+		}
+		var got []*taxipb.CalculateEvent
+		for {
+			event, err := stream.Recv()
+			if err == io.EOF {
+				return got, nil
+			}
+			if err != nil {
+				return got, err
+			}
+			got = append(got, event)
+		}
+	}
+
+	got, err := events(&mockGRPCService{})
+	assert.Nil(t, err)
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, "test1", got[0].GetRecords().Provider)
+		assert.Len(t, got[0].GetRecords().Results, 1)
+		assert.Equal(t, &taxipb.Int32Value{Value: 1200}, got[1].GetMeta().Distance)
+		assert.Equal(t, int32(-1), got[2].GetOptimal().Results.Id)
+	}
+
+	got, err = events(&mockGRPCService{errResponse: service.WithErrorClass(errors.New("Mocked Service Fail"), service.ErrClassNoProviders)})
+	assert.Nil(t, err, "errors after the stream started are events")
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, &taxipb.Error{Status: "Not found", Code: 40401}, got[1].GetError())
+	}
+
+	_, err = events(&mockGRPCService{errCheck: service.WithErrorClass(errors.New("Mocked Check Fail"), service.ErrClassBadRequest)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "errors before the stream are statuses")
+}
+
+func TestEventPBUnknown(t *testing.T) {
+	event := eventPB(context.Background(), service.StreamEvent{Name: "custom", Data: map[string]string{}})
+	assert.Equal(t, int64(50001), event.GetError().Code)
+}
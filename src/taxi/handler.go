@@ -3,9 +3,11 @@ package taxi
 import (
 	"context"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/render"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/api"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
 	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
 )
@@ -38,25 +40,67 @@ func SomeHandler(service Service, basicPriceCoeff float64, regPriceCoeff RegionP
 	return http.HandlerFunc(fn)
 }
 
+// HandlerSettings - коэффициенты цен и время ожидания ответа, с которыми работают хендлеры
+type HandlerSettings struct {
+	PriceCoeff    float64
+	RegPriceCoeff RegionPriceCoeff
+	WaitTime      time.Duration
+	// DebugErrors - отдавать ли клиенту текст внутренней ошибки
+	DebugErrors bool
+}
+
+// HandlerConfig - настройки хендлеров, которые можно заменить без перезапуска
+type HandlerConfig struct {
+	current atomic.Value
+}
+
+// NewHandlerConfig - создаем настройки хендлеров
+func NewHandlerConfig(hs HandlerSettings) *HandlerConfig {
+	cfg := new(HandlerConfig)
+	cfg.Store(hs)
+	return cfg
+}
+
+// Load - текущие настройки
+func (c *HandlerConfig) Load() HandlerSettings {
+	return c.current.Load().(HandlerSettings)
+}
+
+// Store - заменяем настройки; запросы, которые уже выполняются, дорабатывают со старыми
+func (c *HandlerConfig) Store(hs HandlerSettings) {
+	c.current.Store(hs)
+}
+
+// ReloadableHandler - обертка над хэндлером запроса данных такси, которая берет настройки из cfg на каждый запрос
+func ReloadableHandler(service Service, cfg *HandlerConfig, handler handlerFunc) http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		hs := cfg.Load()
+		r = r.WithContext(withDebugErrors(r.Context(), hs.DebugErrors))
+		handler(service, hs.PriceCoeff, hs.RegPriceCoeff, hs.WaitTime, w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
 // Handler - хендлер запроса данных такси
 func Handler(service Service, basicPriceCoeff float64, regPriceCoeff RegionPriceCoeff, waitTime time.Duration, w http.ResponseWriter, r *http.Request) {
 	ctxTaxi, cancelTaxi := context.WithTimeout(r.Context(), waitTime)
 	defer cancelTaxi()
 	taxiReq, taxiReqParseErr := service.ParseTaxiRequest(ctxTaxi, r)
 	if taxiReqParseErr != nil {
-		render.Render(w, r, errInvalidRequest(taxiReqParseErr))
+		render.Render(w, r, errInvalidRequest(r.Context(), taxiReqParseErr))
 		return
 	}
+	api.SetRequestMode(r.Context(), taxiReq.Mode())
 	ctxTaxi = context.WithValue(ctxTaxi, log.CtxKeyRegionID, taxiReq.RegionID)
 	errEval := service.EvaluateTaxiRequest(ctxTaxi, &taxiReq)
 	if errEval != nil {
-		render.Render(w, r, errServerError(errEval))
+		render.Render(w, r, errServerError(r.Context(), errEval))
 		return
 	}
 	coeff := regPriceCoeff.GetByRegionOrElse(taxiReq.RegionID, basicPriceCoeff)
 	response, errResponse := service.Response(ctxTaxi, taxiReq, coeff)
 	if errResponse != nil {
-		render.Render(w, r, errServerError(errResponse))
+		render.Render(w, r, errServerError(r.Context(), errResponse))
 		return
 	}
 	render.JSON(w, r, response)
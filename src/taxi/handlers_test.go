@@ -1,14 +1,17 @@
 package taxi
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"context"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
 )
 
@@ -41,16 +44,16 @@ func TestResponseError(t *testing.T) {
 	s := mockServiceResponseError{}
 	testHandler := SomeHandler(s, 1.3, map[int]float64{99: 1.0}, 4, Handler)
 
-	payload := strings.NewReader("{\r\n    \"region_id\": 14, \r\n    \"point1\": { \r\n        \"lon\": 30.723449,\r\n        \"lat\": 46.441982\r\n    },\r\n    \"point2\": { \r\n        \"lon\": 30.759315,\r\n        \"lat\": 46.453352\r\n    },\r\n    \"only_api\": true \r\n}")
+	payload := strings.NewReader("{\r\n    \"region_id\": 14, \r\n    \"point1\": { \r\n        \"lon\": 30.723449,\r\n        \"lat\": 46.441982\r\n    },\r\n    \"point2\": { \r\n        \"lon\": 30.759315,\r\n        \"lat\": 46.453352\r\n    }\r\n}")
 	req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate", payload)
 	if err != nil {
 		t.Fatal(err)
 	}
 	rr := httptest.NewRecorder()
 	testHandler.ServeHTTP(rr, req)
-	if status := rr.Code; status != http.StatusNotFound {
+	if status := rr.Code; status != http.StatusInternalServerError {
 		t.Errorf("handler returned wrong status code: got %v want %v",
-			status, http.StatusNotFound)
+			status, http.StatusInternalServerError)
 	}
 }
 
@@ -58,15 +61,223 @@ func TestRequestError(t *testing.T) {
 	s := mockServiceResponseError{}
 	testHandler := SomeHandler(s, 1.3, map[int]float64{99: 1.0}, 4, Handler)
 
-	payload := strings.NewReader("{\r\n    \"region_id\": 14, \r\n    \"point1\": { \r\n        \"lon\": 30.723449,\r\n        \"lat\": 46.441982\r\n    },\r\n    \"point2\": { \r\n        \"lon\": 30.759315,\r\n        \"lat\": 46.453352\r\n    },\r\n    \"only_api\": true \r\n}")
+	payload := strings.NewReader("{\r\n    \"region_id\": 14, \r\n    \"point1\": { \r\n        \"lon\": 30.723449,\r\n        \"lat\": 46.441982\r\n    },\r\n    \"point2\": { \r\n        \"lon\": 30.759315,\r\n        \"lat\": 46.453352\r\n    }\r\n}")
 	req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate", payload)
 	if err != nil {
 		t.Fatal(err)
 	}
 	rr := httptest.NewRecorder()
 	testHandler.ServeHTTP(rr, req)
-	if status := rr.Code; status != http.StatusNotFound {
+	if status := rr.Code; status != http.StatusInternalServerError {
 		t.Errorf("handler returned wrong status code: got %v want %v",
-			status, http.StatusNotFound)
+			status, http.StatusInternalServerError)
 	}
 }
+
+type mockStreamService struct {
+	mockServiceResponseError
+	err error
+}
+
+func (m mockStreamService) StreamResponse(ctx context.Context, req service.Request, priceCoeff float64, emit func(service.StreamEvent)) error {
+	emit(service.StreamEvent{Name: service.EventRecords, Data: map[string]string{"provider": "test1"}})
+	if m.err != nil {
+		return m.err
+	}
+	emit(service.StreamEvent{Name: service.EventOptimal, Data: map[string]int{"id": -1}})
+	return nil
+}
+
+func TestStreamHandler(t *testing.T) {
+	testHandler := SomeHandler(mockStreamService{}, 1.3, map[int]float64{99: 1.0}, time.Second, StreamHandler)
+	req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate/stream", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	testHandler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "event: records\ndata: {\"provider\":\"test1\"}\n\nevent: optimal\ndata: {\"id\":-1}\n\n", rr.Body.String())
+	assert.True(t, rr.Flushed)
+}
+
+func TestStreamHandlerError(t *testing.T) {
+	s := mockStreamService{err: errors.New("Mocked Service Response Fail")}
+	testHandler := SomeHandler(s, 1.3, map[int]float64{99: 1.0}, time.Second, StreamHandler)
+	req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate/stream", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	testHandler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "event: error\ndata: {\"status\":\"Internal error\",\"code\":50001}\n\n")
+
+	s = mockStreamService{err: errors.Wrap(service.WithErrorClass(errors.New("Mocked Service Response Fail"), service.ErrClassNoProviders), "wrapped")}
+	testHandler = SomeHandler(s, 1.3, map[int]float64{99: 1.0}, time.Second, StreamHandler)
+	req, err = http.NewRequest("POST", "/taksa/api/1.0/route/calculate/stream", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	testHandler.ServeHTTP(rr, req)
+	assert.Contains(t, rr.Body.String(), "event: error\ndata: {\"status\":\"Not found\",\"code\":40401}\n\n")
+}
+
+func TestStreamHandlerUnsupported(t *testing.T) {
+	testHandler := SomeHandler(mockServiceResponseError{}, 1.3, map[int]float64{99: 1.0}, time.Second, StreamHandler)
+	req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate/stream", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	testHandler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+type mockServiceClassError struct {
+	errParse    error
+	errEval     error
+	errResponse error
+}
+
+func (m mockServiceClassError) ParseTaxiRequest(ctx context.Context, r *http.Request) (service.Request, error) {
+	return service.Request{}, m.errParse
+}
+func (m mockServiceClassError) Response(ctx context.Context, req service.Request, priceCoeff float64) (*service.Response, error) {
+	return &service.Response{}, m.errResponse
+}
+func (m mockServiceClassError) EvaluateTaxiRequest(ctx context.Context, taxiReq *service.Request) error {
+	return m.errEval
+}
+
+func TestErrorClasses(t *testing.T) {
+	errMocked := errors.New("Mocked Service Fail")
+	cases := []struct {
+		name    string
+		srv     mockServiceClassError
+		status  int
+		appCode int64
+	}{
+		{"bad request", mockServiceClassError{errParse: service.WithErrorClass(errMocked, service.ErrClassBadRequest)}, http.StatusBadRequest, 40001},
+		{"unclassified parse error", mockServiceClassError{errParse: errMocked}, http.StatusBadRequest, 40001},
+		{"geocoding failed", mockServiceClassError{errEval: service.WithErrorClass(errMocked, service.ErrClassGeocodingFailed)}, http.StatusBadGateway, 50201},
+		{"unclassified evaluate error", mockServiceClassError{errEval: errMocked}, http.StatusInternalServerError, 50001},
+		{"region not served", mockServiceClassError{errResponse: errors.Wrap(service.WithErrorClass(errMocked, service.ErrClassRegionNotServed), "wrapped")}, http.StatusUnprocessableEntity, 42201},
+		{"no providers", mockServiceClassError{errResponse: service.WithErrorClass(errMocked, service.ErrClassNoProviders)}, http.StatusNotFound, 40401},
+		{"upstream timeout", mockServiceClassError{errResponse: service.WithErrorClass(errMocked, service.ErrClassUpstreamTimeout)}, http.StatusGatewayTimeout, 50401},
+		{"internal", mockServiceClassError{errResponse: errMocked}, http.StatusInternalServerError, 50001},
+	}
+	for _, c := range cases {
+		testHandler := SomeHandler(c.srv, 1.3, map[int]float64{99: 1.0}, time.Second, Handler)
+		req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		testHandler.ServeHTTP(rr, req)
+		assert.Equal(t, c.status, rr.Code, c.name)
+		var resp errResponse
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &resp), c.name)
+		assert.Equal(t, c.appCode, resp.AppCode, c.name)
+		assert.Empty(t, resp.ErrorText, c.name)
+	}
+}
+
+func TestErrorTextDebug(t *testing.T) {
+	s := mockServiceClassError{errResponse: errors.Wrap(service.WithErrorClass(errors.New("Mocked Service Fail"), service.ErrClassNoProviders), "wrapped")}
+	cfg := NewHandlerConfig(HandlerSettings{PriceCoeff: 1.3, WaitTime: time.Second, DebugErrors: true})
+	testHandler := ReloadableHandler(s, cfg, Handler)
+	req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	testHandler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.JSONEq(t, `{"status":"Not found","code":40401,"error":"wrapped: Mocked Service Fail"}`, rr.Body.String())
+}
+
+type mockServiceCoeff struct {
+	regionID int
+	coeff    *float64
+}
+
+func (m mockServiceCoeff) ParseTaxiRequest(ctx context.Context, r *http.Request) (service.Request, error) {
+	return service.Request{RegionID: m.regionID}, nil
+}
+func (m mockServiceCoeff) Response(ctx context.Context, req service.Request, priceCoeff float64) (*service.Response, error) {
+	*m.coeff = priceCoeff
+	return &service.Response{}, nil
+}
+func (m mockServiceCoeff) EvaluateTaxiRequest(ctx context.Context, taxiReq *service.Request) error {
+	return nil
+}
+
+func TestReloadableHandler(t *testing.T) {
+	var coeff float64
+	cfg := NewHandlerConfig(HandlerSettings{PriceCoeff: 1.3, RegPriceCoeff: map[int]float64{99: 1.0}, WaitTime: time.Second})
+	serve := func(regionID int) {
+		testHandler := ReloadableHandler(mockServiceCoeff{regionID: regionID, coeff: &coeff}, cfg, Handler)
+		req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		testHandler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+	serve(99)
+	assert.Equal(t, 1.0, coeff)
+	serve(32)
+	assert.Equal(t, 1.3, coeff)
+
+	cfg.Store(HandlerSettings{PriceCoeff: 1.1, RegPriceCoeff: map[int]float64{32: 1.2}, WaitTime: time.Second})
+	serve(99)
+	assert.Equal(t, 1.1, coeff)
+	serve(32)
+	assert.Equal(t, 1.2, coeff)
+}
+
+type mockBatchService struct {
+	mockServiceResponseError
+	errParse error
+}
+
+func (m mockBatchService) ParseBatchRequest(ctx context.Context, r *http.Request) (service.BatchRequest, error) {
+	return service.BatchRequest{Requests: []service.Request{{RegionID: 99}, {RegionID: 1}}}, m.errParse
+}
+
+func (m mockBatchService) BatchResponse(ctx context.Context, batch service.BatchRequest, itemWaitTime time.Duration, priceCoeff func(regionID int) float64) []service.BatchItem {
+	return []service.BatchItem{
+		{Response: &service.Response{}},
+		{Err: service.WithErrorClass(errors.Wrapf(errors.New("coeff"), "%v", priceCoeff(batch.Requests[1].RegionID)), service.ErrClassNoProviders)},
+	}
+}
+
+func TestBatchHandler(t *testing.T) {
+	cfg := NewHandlerConfig(HandlerSettings{PriceCoeff: 1.3, RegPriceCoeff: map[int]float64{99: 1.0}, WaitTime: time.Second, DebugErrors: true})
+	serve := func(srv Service) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate/batch", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		ReloadableHandler(srv, cfg, BatchHandler).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(mockBatchService{})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"results": [
+		{"response": {"results": {"id": 0}}},
+		{"error": {"status": "Not found", "code": 40401, "error": "1.3: coeff"}}
+	]}`, rr.Body.String())
+
+	rr = serve(mockBatchService{errParse: service.WithErrorClass(service.ErrBatchEmpty, service.ErrClassBadRequest)})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = serve(mockServiceResponseError{})
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
@@ -0,0 +1,14 @@
+package taxi
+
+import (
+	"net/http"
+
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+)
+
+// OpenAPIHandler - отдает контракт REST API. Источник контракта - service/openapi.json,
+// по его схемам service проверяет запросы
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(service.OpenAPISpec))
+}
@@ -0,0 +1,118 @@
+package taxi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/product"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type openAPISchema struct {
+	Properties map[string]struct {
+		Minimum  *float64 `json:"minimum"`
+		Maximum  *float64 `json:"maximum"`
+		MaxItems *int     `json:"maxItems"`
+		// MaxAheadHours - расширение x-max-ahead-hours
+		MaxAheadHours *int `json:"x-max-ahead-hours"`
+	} `json:"properties"`
+	AdditionalProperties *bool `json:"additionalProperties"`
+}
+
+func loadOpenAPISchemas(t *testing.T) map[string]openAPISchema {
+	var spec struct {
+		Components struct {
+			Schemas map[string]openAPISchema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(service.OpenAPISpec), &spec); err != nil {
+		t.Fatal(err)
+	}
+	return spec.Components.Schemas
+}
+
+// jsonFields - имена полей структуры в JSON, без скрытых через "-"
+func jsonFields(v interface{}) []string {
+	var names []string
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func schemaFields(s openAPISchema) []string {
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestOpenAPIMatchesTypes(t *testing.T) {
+	schemas := loadOpenAPISchemas(t)
+	cases := []struct {
+		schema string
+		v      interface{}
+	}{
+		{"Request", service.Request{}},
+		{"Point", service.Point{}},
+		{"Operator", product.Operator{}},
+		{"Error", errResponse{}},
+		{"FieldError", fieldMessage{}},
+		{"BatchResult", batchResult{}},
+	}
+	for _, c := range cases {
+		assert.Equal(t, jsonFields(c.v), schemaFields(schemas[c.schema]), c.schema)
+	}
+	for _, name := range []string{"Request", "Point", "BatchRequest"} {
+		if assert.NotNil(t, schemas[name].AdditionalProperties, name) {
+			assert.False(t, *schemas[name].AdditionalProperties, "unknown fields are rejected: %v", name)
+		}
+	}
+
+	point := schemas["Point"].Properties
+	assert.Equal(t, float64(-service.MaxLat), *point["lat"].Minimum)
+	assert.Equal(t, float64(service.MaxLat), *point["lat"].Maximum)
+	assert.Equal(t, float64(-service.MaxLon), *point["lon"].Minimum)
+	assert.Equal(t, float64(service.MaxLon), *point["lon"].Maximum)
+	assert.Equal(t, service.MaxWaypoints, *schemas["Request"].Properties["waypoints"].MaxItems)
+	assert.Equal(t, service.MaxPreOrderHorizon, time.Duration(*schemas["Request"].Properties["pickup_time"].MaxAheadHours)*time.Hour)
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	OpenAPIHandler(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var spec map[string]interface{}
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec["openapi"])
+}
+
+func TestFieldErrorsResponse(t *testing.T) {
+	errFields := service.WithErrorClass(errors.Wrap(&service.FieldError{Field: "point1.lat", Message: "must be in [-90, 90]", Err: service.ErrCoordinateOutOfRange}, "bad request"), service.ErrClassBadRequest)
+	testHandler := SomeHandler(mockServiceClassError{errParse: errFields}, 1.3, map[int]float64{99: 1.0}, time.Second, Handler)
+	req, err := http.NewRequest("POST", "/taksa/api/1.0/route/calculate", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	testHandler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.JSONEq(t, `{"status":"Invalid request","code":40001,"fields":[{"field":"point1.lat","message":"must be in [-90, 90]"}]}`, rr.Body.String())
+}
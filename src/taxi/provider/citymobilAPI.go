@@ -10,6 +10,9 @@ import (
 	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
 )
 
+// citymobilNoHurry - значение hurry для заказа не на ближайшее время
+const citymobilNoHurry = "0"
+
 type tariffGroup struct {
 	ID   int
 	Name string
@@ -25,15 +28,22 @@ type citymobilAPI struct {
 	Hurry           string
 }
 
+type citymobilWaypoint struct {
+	Latitude  string `json:"latitude"`
+	Longitude string `json:"longitude"`
+}
+
 type citymobilOrder struct {
-	Latitude     string `json:"latitude"`
-	Longitude    string `json:"longitude"`
-	DelLatitude  string `json:"del_latitude"`
-	DelLongitude string `json:"del_longitude"`
-	TariffGroup  []int  `json:"tariff_group"`
-	Method       string `json:"method"`
-	Ver          string `json:"ver"`
-	Hurry        string `json:"hurry"`
+	Latitude     string              `json:"latitude"`
+	Longitude    string              `json:"longitude"`
+	DelLatitude  string              `json:"del_latitude"`
+	DelLongitude string              `json:"del_longitude"`
+	Waypoints    []citymobilWaypoint `json:"waypoints,omitempty"`
+	OrderTime    int64               `json:"order_time,omitempty"`
+	TariffGroup  []int               `json:"tariff_group"`
+	Method       string              `json:"method"`
+	Ver          string              `json:"ver"`
+	Hurry        string              `json:"hurry"`
 }
 
 type citymobilPrice struct {
@@ -45,24 +55,49 @@ type citymobilPriceResponse struct {
 	Prices []citymobilPrice `json:"prices"`
 }
 
+func init() {
+	Register("citymobil", newCitymobilAPI)
+}
+
+func newCitymobilAPI(handler string, config json.RawMessage) (service.APIDataGetter, error) {
+	var h citymobilAPI
+	if err := decodeConfig(config, &h); err != nil {
+		return nil, err
+	}
+	if h.Name == "" {
+		h.Name = handler
+	}
+	return h, nil
+}
+
 func (h citymobilAPI) APIName() string {
 	return h.Name
 }
 
+// SupportsWaypoints - Ситимобил считает цену заказа с промежуточными остановками
+func (h citymobilAPI) SupportsWaypoints() bool {
+	return true
+}
+
+// SupportsPreOrder - Ситимобил считает цену заказа на время подачи
+func (h citymobilAPI) SupportsPreOrder() bool {
+	return true
+}
+
 func (h citymobilAPI) GetAPIData(ctx context.Context, httpreq *httprequester.Requester, taxiReq service.Request) ([]service.APIData, error) {
 	tariffGrps := make([]int, len(h.TariffGroups))
 	for i, gr := range h.TariffGroups {
 		tariffGrps[i] = gr.ID
 	}
-	priceResp, errPrices := h.price(ctx, httpreq, taxiReq.Point1, taxiReq.Point2, tariffGrps)
+	priceResp, errPrices := h.price(ctx, httpreq, taxiReq, tariffGrps)
 	if errPrices != nil {
 		return nil, errors.Wrap(errPrices, "citymobil: Cannot request prices")
 	}
-	apiDatas := h.makeAPIDatas(*priceResp, taxiReq.Point1, taxiReq.Point2)
+	apiDatas := h.makeAPIDatas(*priceResp, taxiReq.Point1, taxiReq.Point2, taxiReq.WaypointTemplateVars())
 	return apiDatas, nil
 }
 
-func (h citymobilAPI) makeAPIDatas(priceResp citymobilPriceResponse, p1 service.Point, p2 service.Point) []service.APIData {
+func (h citymobilAPI) makeAPIDatas(priceResp citymobilPriceResponse, p1 service.Point, p2 service.Point, waypointVars map[string]string) []service.APIData {
 	result := make([]service.APIData, len(priceResp.Prices))
 	for i, priceItem := range priceResp.Prices {
 		displayName := "Ситимобил"
@@ -71,34 +106,49 @@ func (h citymobilAPI) makeAPIDatas(priceResp citymobilPriceResponse, p1 service.
 				displayName = "Ситимобил " + gr.Name
 			}
 		}
+		templateVars := map[string]string{
+			"%from.lat%":     p1.LatStr,
+			"%from.lon%":     p1.LonStr,
+			"%from.address%": p1.Address,
+			"%to.lat%":       p2.LatStr,
+			"%to.lon%":       p2.LonStr,
+			"%to.address%":   p2.Address,
+		}
+		for name, value := range waypointVars {
+			templateVars[name] = value
+		}
 		result[i] = service.APIData{
-			DisplayName: displayName,
-			PriceMean:   priceItem.TotalPrice,
-			TemplateVars: map[string]string{
-				"%from.lat%":     p1.LatStr,
-				"%from.lon%":     p1.LonStr,
-				"%from.address%": p1.Address,
-				"%to.lat%":       p2.LatStr,
-				"%to.lon%":       p2.LonStr,
-				"%to.address%":   p2.Address,
-			},
+			DisplayName:  displayName,
+			PriceMean:    priceItem.TotalPrice,
+			TemplateVars: templateVars,
 		}
 	}
 	return result
 }
 
-func (h citymobilAPI) price(ctx context.Context, httpreq *httprequester.Requester, p1, p2 service.Point, tariffGrps []int) (*citymobilPriceResponse, error) {
+func (h citymobilAPI) price(ctx context.Context, httpreq *httprequester.Requester, taxiReq service.Request, tariffGrps []int) (*citymobilPriceResponse, error) {
 	var p = new(citymobilPriceResponse)
 	orderData := citymobilOrder{
-		Latitude:     p1.LatStr,
-		Longitude:    p1.LonStr,
-		DelLatitude:  p2.LatStr,
-		DelLongitude: p2.LonStr,
+		Latitude:     taxiReq.Point1.LatStr,
+		Longitude:    taxiReq.Point1.LonStr,
+		DelLatitude:  taxiReq.Point2.LatStr,
+		DelLongitude: taxiReq.Point2.LonStr,
 		TariffGroup:  tariffGrps,
 		Method:       h.PriceMethodName,
 		Ver:          h.Ver,
 		Hurry:        h.Hurry,
 	}
+	// предварительный заказ не срочный
+	if taxiReq.IsScheduled() {
+		orderData.OrderTime = taxiReq.PickupTime.Unix()
+		orderData.Hurry = citymobilNoHurry
+	}
+	for _, wp := range taxiReq.Waypoints {
+		orderData.Waypoints = append(orderData.Waypoints, citymobilWaypoint{
+			Latitude:  wp.LatStr,
+			Longitude: wp.LonStr,
+		})
+	}
 	bData, errMarsh := json.Marshal(orderData)
 	if errMarsh != nil {
 		return p, errMarsh
@@ -109,11 +159,11 @@ func (h citymobilAPI) price(ctx context.Context, httpreq *httprequester.Requeste
 		return p, errPrice
 	}
 	if len(p.Prices) == 0 {
-		return p, errors.Wrap(service.ErrInvalidPrice, "Price list empty")
+		return p, service.InvalidPrice(h.Name, "Price list empty")
 	}
 	for _, priceItem := range p.Prices {
 		if priceItem.TotalPrice <= 0 {
-			return p, errors.Wrap(service.ErrInvalidPrice, "Price <= 0")
+			return p, service.InvalidPrice(h.Name, "Price <= 0")
 		}
 	}
 	return p, nil
@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"encoding/json"
+	"net/http"
 	"sort"
 	"testing"
 
@@ -114,3 +116,54 @@ func TestCitymobilAPINoPrice(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, 0, len(res))
 }
+
+func TestCitymobilAPIWaypoints(t *testing.T) {
+	defer gock.Off()
+	var order citymobilOrder
+	bodyMatcher := gock.NewBasicMatcher()
+	bodyMatcher.Add(func(req *http.Request, ereq *gock.Request) (bool, error) {
+		return true, json.NewDecoder(req.Body).Decode(&order)
+	})
+	gock.New(tCitymobilAPI.Host).
+		Post(tCitymobilAPI.PriceMethod).
+		SetMatcher(bodyMatcher).
+		Reply(200).
+		File("_test_jsons/citymobil.json")
+
+	gock.InterceptClient(testHttpClient)
+	assert.True(t, tCitymobilAPI.SupportsWaypoints())
+	res, err := tCitymobilAPI.GetAPIData(testContext, testHTTPRequester, testTaxiRequestWaypoints)
+	assert.Nil(t, err)
+	assert.Equal(t, []citymobilWaypoint{{Latitude: testWaypoint.LatStr, Longitude: testWaypoint.LonStr}}, order.Waypoints)
+	assert.Equal(t, testTaxiRequestMoscow.Point2.LatStr, order.DelLatitude)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, testWaypoint.LatStr, res[0].TemplateVars["%waypoint1.lat%"])
+	assert.Equal(t, testWaypoint.LonStr, res[0].TemplateVars["%waypoint1.lon%"])
+	assert.Equal(t, testWaypoint.Address, res[0].TemplateVars["%waypoint1.address%"])
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestCitymobilAPIPreOrder(t *testing.T) {
+	defer gock.Off()
+	var order citymobilOrder
+	bodyMatcher := gock.NewBasicMatcher()
+	bodyMatcher.Add(func(req *http.Request, ereq *gock.Request) (bool, error) {
+		return true, json.NewDecoder(req.Body).Decode(&order)
+	})
+	gock.New(tCitymobilAPI.Host).
+		Post(tCitymobilAPI.PriceMethod).
+		SetMatcher(bodyMatcher).
+		Reply(200).
+		File("_test_jsons/citymobil.preorder.json")
+
+	gock.InterceptClient(testHttpClient)
+	assert.True(t, tCitymobilAPI.SupportsPreOrder())
+	res, err := tCitymobilAPI.GetAPIData(testContext, testHTTPRequester, testTaxiRequestScheduled)
+	assert.Nil(t, err)
+	assert.Equal(t, testPickupTime.Unix(), order.OrderTime)
+	assert.Equal(t, citymobilNoHurry, order.Hurry)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, 520.0, res[0].PriceMean)
+	assert.Equal(t, 0, res[0].Eta)
+	assert.Equal(t, gock.IsDone(), true)
+}
@@ -0,0 +1,319 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrGenericMethod - в настройках указан неподдерживаемый HTTP метод
+	ErrGenericMethod = errors.New("Unsupported HTTP method")
+	// ErrGenericPath - в ответе провайдера нет значения по указанному пути
+	ErrGenericPath = errors.New("Value not found by path")
+	// ErrGenericValue - значение по указанному пути нельзя привести к нужному типу
+	ErrGenericValue = errors.New("Value has unexpected type")
+)
+
+// genericRequestParams - имена параметров запроса для координат точек
+type genericRequestParams struct {
+	PickupLat      string `json:"pickupLat"`
+	PickupLon      string `json:"pickupLon"`
+	DestinationLat string `json:"destinationLat"`
+	DestinationLon string `json:"destinationLon"`
+}
+
+// genericResponseFields - пути к полям в ответе провайдера, разделенные точками ("data.items", "price.min").
+// List - путь к списку тарифов, остальные пути - относительно элемента списка
+type genericResponseFields struct {
+	List        string `json:"list"`
+	PriceMin    string `json:"priceMin"`
+	PriceMax    string `json:"priceMax"`
+	PriceMean   string `json:"priceMean"`
+	Eta         string `json:"eta"`
+	EtaUnit     string `json:"etaUnit"`
+	ProductID   string `json:"productID"`
+	DisplayName string `json:"displayName"`
+	TariffName  string `json:"tariffName"`
+}
+
+// genericAPI - провайдер, который целиком описывается настройками в settings.json
+type genericAPI struct {
+	Name         string
+	Headers      []httprequester.Dict
+	Host         string
+	PriceMethod  string
+	HTTPMethod   string `json:"httpMethod"`
+	Params       genericRequestParams
+	StaticParams []httprequester.Dict `json:"staticParams"`
+	BodyTemplate string               `json:"bodyTemplate"`
+	Response     genericResponseFields
+	DisplayName  string            `json:"displayName"`
+	TariffMap    map[string]string `json:"tariffMap"`
+}
+
+func init() {
+	Register("generic", newGenericAPI)
+}
+
+func newGenericAPI(handler string, config json.RawMessage) (service.APIDataGetter, error) {
+	var h genericAPI
+	if err := decodeConfig(config, &h); err != nil {
+		return nil, err
+	}
+	if h.Name == "" {
+		h.Name = handler
+	}
+	h.HTTPMethod = strings.ToUpper(h.HTTPMethod)
+	if h.HTTPMethod == "" {
+		h.HTTPMethod = http.MethodGet
+	}
+	if h.HTTPMethod != http.MethodGet && h.HTTPMethod != http.MethodPost {
+		return nil, errors.Wrap(ErrGenericMethod, h.HTTPMethod)
+	}
+	return h, nil
+}
+
+func (h genericAPI) APIName() string {
+	return h.Name
+}
+
+func (h genericAPI) GetAPIData(ctx context.Context, httpreq *httprequester.Requester, taxiReq service.Request) ([]service.APIData, error) {
+	items, errPrices := h.price(ctx, httpreq, taxiReq.Point1, taxiReq.Point2)
+	if errPrices != nil {
+		return nil, errors.Wrapf(errPrices, "%v: Cannot request prices", h.Name)
+	}
+	apiDatas, errData := h.makeAPIDatas(items, taxiReq.Point1, taxiReq.Point2)
+	if errData != nil {
+		return nil, service.InvalidResponse(h.Name, errors.Wrapf(errData, "%v: Cannot parse prices", h.Name))
+	}
+	for _, apiData := range apiDatas {
+		if apiData.Eta <= 0 {
+			return apiDatas, service.InvalidTime(h.Name, fmt.Sprintf("%v: Time <= 0, product %v", h.Name, apiData.ProductID))
+		}
+	}
+	return apiDatas, nil
+}
+
+func (h genericAPI) templateVars(p1, p2 service.Point) map[string]string {
+	return map[string]string{
+		"%from.lat%":     p1.LatStr,
+		"%from.lon%":     p1.LonStr,
+		"%from.address%": p1.Address,
+		"%to.lat%":       p2.LatStr,
+		"%to.lon%":       p2.LonStr,
+		"%to.address%":   p2.Address,
+	}
+}
+
+func (h genericAPI) price(ctx context.Context, httpreq *httprequester.Requester, p1, p2 service.Point) ([]interface{}, error) {
+	params := make([]httprequester.Dict, 0)
+	for _, param := range []httprequester.Dict{
+		{Key: h.Params.PickupLat, Value: p1.LatStr},
+		{Key: h.Params.PickupLon, Value: p1.LonStr},
+		{Key: h.Params.DestinationLat, Value: p2.LatStr},
+		{Key: h.Params.DestinationLon, Value: p2.LonStr},
+	} {
+		if param.Key != "" {
+			params = append(params, param)
+		}
+	}
+	params = append(params, h.StaticParams...)
+
+	var raw interface{}
+	priceURL := fmt.Sprintf("%v%v", h.Host, h.PriceMethod)
+	var errPrice error
+	switch h.HTTPMethod {
+	case http.MethodPost:
+		body := replaceVars(h.BodyTemplate, jsonEscapeVars(h.templateVars(p1, p2)))
+		errPrice = httpreq.PostWithParams(ctx, priceURL, h.Headers, params, []byte(body), &raw)
+	default:
+		errPrice = httpreq.Get(ctx, priceURL, h.Headers, params, &raw)
+	}
+	if errPrice != nil {
+		return nil, errPrice
+	}
+	list, errList := lookupPath(raw, h.Response.List)
+	if errList != nil {
+		return nil, service.InvalidPrice(h.Name, errList.Error())
+	}
+	items, ok := list.([]interface{})
+	if !ok {
+		return nil, service.InvalidPrice(h.Name, fmt.Sprintf("%v: %v is not a list", ErrGenericValue.Error(), h.Response.List))
+	}
+	if len(items) == 0 {
+		return nil, service.InvalidPrice(h.Name, "Price list is empty")
+	}
+	return items, nil
+}
+
+func (h genericAPI) makeAPIDatas(items []interface{}, p1, p2 service.Point) ([]service.APIData, error) {
+	result := make([]service.APIData, 0, len(items))
+	for _, item := range items {
+		priceMin, errMin := lookupInt(item, h.Response.PriceMin)
+		if errMin != nil {
+			return nil, errMin
+		}
+		priceMax, errMax := lookupInt(item, h.Response.PriceMax)
+		if errMax != nil {
+			return nil, errMax
+		}
+		priceMean := float64(priceMin+priceMax) / 2
+		if h.Response.PriceMean != "" {
+			var errMean error
+			priceMean, errMean = lookupFloat(item, h.Response.PriceMean)
+			if errMean != nil {
+				return nil, errMean
+			}
+		}
+		if priceMean <= 0 {
+			return nil, service.InvalidPrice(h.Name, "Price <= 0")
+		}
+		// нет времени подачи - не ошибка разбора: тариф отдаем, GetAPIData вернет ErrInvalidTime
+		eta, errEta := lookupInt(item, h.Response.Eta)
+		if errors.Cause(errEta) == ErrGenericPath {
+			eta, errEta = 0, nil
+		}
+		if errEta != nil {
+			return nil, errEta
+		}
+		if h.Response.EtaUnit != "minutes" {
+			eta = secondsToMins(eta)
+		}
+		productID, errID := lookupString(item, h.Response.ProductID)
+		if errID != nil {
+			return nil, errID
+		}
+		displayName, errName := lookupString(item, h.Response.DisplayName)
+		if errName != nil {
+			return nil, errName
+		}
+		tariffName, errTariff := lookupString(item, h.Response.TariffName)
+		if errTariff != nil {
+			return nil, errTariff
+		}
+		if mapped, ok := h.TariffMap[tariffName]; ok {
+			tariffName = mapped
+		}
+		if h.DisplayName != "" {
+			displayName = strings.TrimSpace(fmt.Sprintf("%v %v", h.DisplayName, displayName))
+		}
+		templateVars := h.templateVars(p1, p2)
+		templateVars["%product.id%"] = productID
+		result = append(result, service.APIData{
+			DisplayName:  displayName,
+			PriceMax:     priceMax,
+			PriceMin:     priceMin,
+			PriceMean:    priceMean,
+			ProductID:    productID,
+			TariffName:   tariffName,
+			Eta:          eta,
+			TemplateVars: templateVars,
+		})
+	}
+	return result, nil
+}
+
+// jsonEscapeVars - значения переменных для подстановки внутрь строк JSON шаблона тела запроса:
+// кавычки и обратные слэши в адресах не должны ломать JSON
+func jsonEscapeVars(vars map[string]string) map[string]string {
+	escaped := make(map[string]string, len(vars))
+	for key, value := range vars {
+		quoted, _ := json.Marshal(value)
+		escaped[key] = string(quoted[1 : len(quoted)-1])
+	}
+	return escaped
+}
+
+func replaceVars(template string, vars map[string]string) string {
+	for oldStr, newStr := range vars {
+		template = strings.Replace(template, oldStr, newStr, -1)
+	}
+	return template
+}
+
+// lookupPath - ищет значение в разобранном json по пути вида "a.b.0.c". Пустой путь - само значение
+func lookupPath(value interface{}, path string) (interface{}, error) {
+	if path == "" {
+		return value, nil
+	}
+	current := value
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[key]
+			if !ok {
+				return nil, errors.Wrap(ErrGenericPath, path)
+			}
+			current = next
+		case []interface{}:
+			ind, err := strconv.Atoi(key)
+			if err != nil || ind < 0 || ind >= len(node) {
+				return nil, errors.Wrap(ErrGenericPath, path)
+			}
+			current = node[ind]
+		default:
+			return nil, errors.Wrap(ErrGenericPath, path)
+		}
+	}
+	return current, nil
+}
+
+// lookupFloat - число по пути; пустой путь или null - 0. Если пути в ответе нет - ErrGenericPath:
+// ошибка в настройках не должна превращаться в нулевую цену
+func lookupFloat(value interface{}, path string) (float64, error) {
+	if path == "" {
+		return 0, nil
+	}
+	found, err := lookupPath(value, path)
+	if err != nil {
+		return 0, err
+	}
+	if found == nil {
+		return 0, nil
+	}
+	switch v := found.(type) {
+	case float64:
+		return v, nil
+	case string:
+		f, errParse := strconv.ParseFloat(v, 64)
+		if errParse != nil {
+			return 0, errors.Wrapf(ErrGenericValue, "%v: %v", path, v)
+		}
+		return f, nil
+	}
+	return 0, errors.Wrapf(ErrGenericValue, "%v: %v", path, found)
+}
+
+func lookupInt(value interface{}, path string) (int, error) {
+	f, err := lookupFloat(value, path)
+	return int(f), err
+}
+
+// lookupString - строка по пути; числа приводятся к строке, пустой путь или null - "". Если пути в ответе нет - ErrGenericPath
+func lookupString(value interface{}, path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	found, err := lookupPath(value, path)
+	if err != nil {
+		return "", err
+	}
+	if found == nil {
+		return "", nil
+	}
+	switch v := found.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return "", errors.Wrapf(ErrGenericValue, "%v: %v", path, found)
+}
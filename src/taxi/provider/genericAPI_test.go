@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var tGenericAPIConfig = json.RawMessage(`{
+	"type": "generic",
+	"host": "https://api.aggregator.test",
+	"priceMethod": "/v2/estimate",
+	"headers": [{"key": "Authorization", "value": "Token xxx"}],
+	"params": {
+		"pickupLat": "from_lat",
+		"pickupLon": "from_lon",
+		"destinationLat": "to_lat",
+		"destinationLon": "to_lon"
+	},
+	"staticParams": [{"key": "lang", "value": "ru"}],
+	"response": {
+		"list": "data.options",
+		"priceMin": "price.min",
+		"priceMax": "price.max",
+		"eta": "waiting.seconds",
+		"productID": "id",
+		"displayName": "title",
+		"tariffName": "class"
+	},
+	"displayName": "Агрегатор",
+	"tariffMap": {"econom": "aggregator_econom"}
+}`)
+
+func newTestGenericAPI(t *testing.T, config json.RawMessage) genericAPI {
+	getter, err := newGenericAPI("aggregator", config)
+	assert.Nil(t, err)
+	return getter.(genericAPI)
+}
+
+var tGenericAPIData = []service.APIData{
+	service.APIData{
+		DisplayName: "Агрегатор Эконом",
+		PriceMin:    200,
+		PriceMax:    300,
+		PriceMean:   250,
+		ProductID:   "a1b2c3",
+		TariffName:  "aggregator_econom",
+		Eta:         4,
+		TemplateVars: map[string]string{
+			"%from.lat%":     testTaxiRequestMoscow.Point1.LatStr,
+			"%from.lon%":     testTaxiRequestMoscow.Point1.LonStr,
+			"%from.address%": testTaxiRequestMoscow.Point1.Address,
+			"%to.lat%":       testTaxiRequestMoscow.Point2.LatStr,
+			"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
+			"%to.address%":   testTaxiRequestMoscow.Point2.Address,
+			"%product.id%":   "a1b2c3",
+		},
+	},
+	service.APIData{
+		DisplayName: "Агрегатор Бизнес",
+		PriceMin:    500,
+		PriceMax:    700,
+		PriceMean:   600,
+		ProductID:   "42",
+		TariffName:  "business",
+		Eta:         0,
+		TemplateVars: map[string]string{
+			"%from.lat%":     testTaxiRequestMoscow.Point1.LatStr,
+			"%from.lon%":     testTaxiRequestMoscow.Point1.LonStr,
+			"%from.address%": testTaxiRequestMoscow.Point1.Address,
+			"%to.lat%":       testTaxiRequestMoscow.Point2.LatStr,
+			"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
+			"%to.address%":   testTaxiRequestMoscow.Point2.Address,
+			"%product.id%":   "42",
+		},
+	},
+}
+
+func TestGenericAPIGet(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.aggregator.test").
+		Get("/v2/estimate").
+		MatchParam("from_lat", testTaxiRequestMoscow.Point1.LatStr).
+		MatchParam("from_lon", testTaxiRequestMoscow.Point1.LonStr).
+		MatchParam("to_lat", testTaxiRequestMoscow.Point2.LatStr).
+		MatchParam("to_lon", testTaxiRequestMoscow.Point2.LonStr).
+		MatchParam("lang", "ru").
+		MatchHeader("Authorization", "Token xxx").
+		Reply(200).
+		File("_test_jsons/generic.json")
+
+	gock.InterceptClient(testHttpClient)
+	h := newTestGenericAPI(t, tGenericAPIConfig)
+	assert.Equal(t, "aggregator", h.APIName())
+	res, err := h.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.Equal(t, service.ErrInvalidTime, errors.Cause(err), "business has no eta")
+	assert.Equal(t, tGenericAPIData, res)
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestGenericAPIPost(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.aggregator.test").
+		Post("/v2/estimate").
+		MatchParam("lang", "ru").
+		JSON(map[string]interface{}{
+			"route": []string{
+				testTaxiRequestMoscow.Point1.LonStr + "," + testTaxiRequestMoscow.Point1.LatStr,
+				testTaxiRequestMoscow.Point2.LonStr + "," + testTaxiRequestMoscow.Point2.LatStr,
+			},
+		}).
+		Reply(200).
+		File("_test_jsons/generic.json")
+
+	gock.InterceptClient(testHttpClient)
+	h := newTestGenericAPI(t, tGenericAPIConfig)
+	h.HTTPMethod = "POST"
+	h.Params = genericRequestParams{}
+	h.BodyTemplate = `{"route": ["%from.lon%,%from.lat%", "%to.lon%,%to.lat%"]}`
+	h.Headers = []httprequester.Dict{
+		{
+			Key:   "Content-Type",
+			Value: "application/json",
+		},
+	}
+	res, err := h.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.Equal(t, service.ErrInvalidTime, errors.Cause(err), "business has no eta")
+	assert.Equal(t, tGenericAPIData, res)
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestGenericAPIPrice0(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.aggregator.test").
+		Get("/v2/estimate").
+		Reply(200).
+		File("_test_jsons/generic.0.json")
+
+	gock.InterceptClient(testHttpClient)
+	h := newTestGenericAPI(t, tGenericAPIConfig)
+	res, err := h.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), service.ErrInvalidPrice.Error())
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestGenericAPIPriceEmpty(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.aggregator.test").
+		Get("/v2/estimate").
+		Reply(200).
+		File("_test_jsons/generic.Empty.json")
+
+	gock.InterceptClient(testHttpClient)
+	h := newTestGenericAPI(t, tGenericAPIConfig)
+	res, err := h.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), service.ErrInvalidPrice.Error())
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestGenericAPIPrice500(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.aggregator.test").
+		Get("/v2/estimate").
+		Reply(500)
+
+	gock.InterceptClient(testHttpClient)
+	h := newTestGenericAPI(t, tGenericAPIConfig)
+	res, err := h.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestGenericAPIPostEscapesBody(t *testing.T) {
+	defer gock.Off()
+	req := testTaxiRequestMoscow
+	req.Point1.Address = `Тверская, 1 "А"\2`
+	gock.New("https://api.aggregator.test").
+		Post("/v2/estimate").
+		JSON(map[string]interface{}{"from": req.Point1.Address, "to": req.Point2.Address}).
+		Reply(200).
+		File("_test_jsons/generic.json")
+
+	gock.InterceptClient(testHttpClient)
+	h := newTestGenericAPI(t, tGenericAPIConfig)
+	h.HTTPMethod = "POST"
+	h.Params = genericRequestParams{}
+	h.StaticParams = nil
+	h.BodyTemplate = `{"from": "%from.address%", "to": "%to.address%"}`
+	h.Headers = []httprequester.Dict{{Key: "Content-Type", Value: "application/json"}}
+	res, _ := h.GetAPIData(testContext, testHTTPRequester, req)
+	assert.Equal(t, 2, len(res))
+	assert.Equal(t, req.Point1.Address, res[0].TemplateVars["%from.address%"], "deeplink vars are not escaped")
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestGenericAPIBadPath(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.aggregator.test").
+		Get("/v2/estimate").
+		Reply(200).
+		File("_test_jsons/generic.json")
+
+	gock.InterceptClient(testHttpClient)
+	h := newTestGenericAPI(t, tGenericAPIConfig)
+	h.Response.PriceMax = "price.maximum"
+	res, err := h.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.Equal(t, ErrGenericPath, errors.Cause(err))
+	assert.Equal(t, apierror.KindParse, apierror.KindOf(err))
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestGenericAPIFromSettings(t *testing.T) {
+	pl := ProvidersList{
+		"aggregator": tGenericAPIConfig,
+		"broken":     json.RawMessage(`{"type": "nosuchtype"}`),
+	}
+	_, err := pl.APIGetters()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "broken (type nosuchtype)")
+
+	delete(pl, "broken")
+	getters, err := pl.APIGetters()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(getters))
+	assert.Equal(t, "aggregator", getters[0].APIName())
+}
+
+func TestGenericAPIBadMethod(t *testing.T) {
+	_, err := newGenericAPI("aggregator", json.RawMessage(`{"httpMethod": "delete"}`))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrGenericMethod.Error())
+}
+
+func TestLookupPath(t *testing.T) {
+	var raw interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"a": {"b": [{"c": 1.5}, {"c": "2"}]}}`), &raw))
+	v, err := lookupFloat(raw, "a.b.0.c")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5, v)
+	v, err = lookupFloat(raw, "a.b.1.c")
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, v)
+	_, err = lookupPath(raw, "a.b.2.c")
+	assert.NotNil(t, err)
+	_, err = lookupString(raw, "a.b")
+	assert.NotNil(t, err)
+	_, err = lookupFloat(raw, "a.c")
+	assert.Equal(t, ErrGenericPath, errors.Cause(err))
+	_, err = lookupString(raw, "a.b.0.d")
+	assert.Equal(t, ErrGenericPath, errors.Cause(err))
+}
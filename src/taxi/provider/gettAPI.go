@@ -2,10 +2,13 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/errorswrapper"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
 
 	"github.com/pkg/errors"
@@ -39,29 +42,54 @@ type gettEta struct {
 	} `json:"etas"`
 }
 
+func init() {
+	Register("gett", newGettAPI)
+}
+
+func newGettAPI(handler string, config json.RawMessage) (service.APIDataGetter, error) {
+	var h gettAPI
+	if err := decodeConfig(config, &h); err != nil {
+		return nil, err
+	}
+	if h.Name == "" {
+		h.Name = handler
+	}
+	return h, nil
+}
+
 func (h gettAPI) APIName() string {
 	return h.Name
 }
 
+// SupportsPreOrder - Gett считает цену поездки на время подачи
+func (h gettAPI) SupportsPreOrder() bool {
+	return true
+}
+
 func (h gettAPI) GetAPIData(ctx context.Context, httpreq *httprequester.Requester, taxiReq service.Request) ([]service.APIData, error) {
 	var errPrices, errEtas error
 	var price *gettPrice
-	var eta *gettEta
+	var eta = new(gettEta)
 	var wg sync.WaitGroup
 
-	wg.Add(2)
-	go func() {
-		defer wg.Done()
-		price, errPrices = h.price(ctx, httpreq, taxiReq.Point1, taxiReq.Point2)
-	}()
+	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		eta, errEtas = h.time(ctx, httpreq, taxiReq.Point1)
+		price, errPrices = h.price(ctx, httpreq, taxiReq)
 	}()
+	// время подачи машины имеет смысл только для поездки сейчас
+	if !taxiReq.IsScheduled() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eta, errEtas = h.time(ctx, httpreq, taxiReq.Point1)
+		}()
+	}
 	wg.Wait()
 
 	if errPrices != nil {
-		return nil, errors.Wrap(errPrices, "Gett: Cannot request prices")
+		// ошибку второго запроса не теряем: по ней видно, был ли провайдер недоступен целиком
+		return nil, errorswrapper.Append(errors.Wrap(errPrices, "Gett: Cannot request prices"), errors.Wrap(errEtas, "Gett: Cannot request eta"))
 	}
 	apiDatas := h.makeAPIDatas(*price, *eta, taxiReq.Point1, taxiReq.Point2)
 	if errEtas != nil {
@@ -111,25 +139,31 @@ var gettTariffMap = map[string]string{
 	"Стандарт":    "gett_standart",
 }
 
-func (h gettAPI) price(ctx context.Context, httpreq *httprequester.Requester, p1 service.Point, p2 service.Point) (*gettPrice, error) {
+func (h gettAPI) price(ctx context.Context, httpreq *httprequester.Requester, taxiReq service.Request) (*gettPrice, error) {
 	params := []httprequester.Dict{
 		{
 			Key:   "pickup_latitude",
-			Value: p1.LatStr,
+			Value: taxiReq.Point1.LatStr,
 		},
 		{
 			Key:   "pickup_longitude",
-			Value: p1.LonStr,
+			Value: taxiReq.Point1.LonStr,
 		},
 		{
 			Key:   "destination_latitude",
-			Value: p2.LatStr,
+			Value: taxiReq.Point2.LatStr,
 		},
 		{
 			Key:   "destination_longitude",
-			Value: p2.LonStr,
+			Value: taxiReq.Point2.LonStr,
 		},
 	}
+	if taxiReq.IsScheduled() {
+		params = append(params, httprequester.Dict{
+			Key:   "scheduled_at",
+			Value: taxiReq.PickupTime.Format(time.RFC3339),
+		})
+	}
 	var p = new(gettPrice)
 	priceURL := fmt.Sprintf("%v%v", h.Host, h.PriceMethod)
 	errPrice := httpreq.Get(ctx, priceURL, h.Headers, params, p)
@@ -137,11 +171,11 @@ func (h gettAPI) price(ctx context.Context, httpreq *httprequester.Requester, p1
 		return p, errPrice
 	}
 	if len(p.Prices) == 0 {
-		return p, errors.Wrap(service.ErrInvalidPrice, "Price list is empty")
+		return p, service.InvalidPrice(h.Name, "Price list is empty")
 	}
 	for _, price := range p.Prices {
 		if float64(price.HighEstimate+price.LowEstimate)/2 <= 0 {
-			return p, errors.Wrap(service.ErrInvalidPrice, "Price <= 0")
+			return p, service.InvalidPrice(h.Name, "Price <= 0")
 		}
 	}
 	return p, nil
@@ -165,11 +199,11 @@ func (h gettAPI) time(ctx context.Context, httpreq *httprequester.Requester, p1
 		return eta, errTime
 	}
 	if len(eta.Etas) == 0 {
-		return eta, errors.Wrap(service.ErrInvalidTime, "Time list is empty")
+		return eta, service.InvalidTime(h.Name, "Time list is empty")
 	}
 	for _, e := range eta.Etas {
 		if e.Eta <= 0 {
-			return eta, errors.Wrap(service.ErrInvalidTime, "Time <= 0")
+			return eta, service.InvalidTime(h.Name, "Time <= 0")
 		}
 	}
 	return eta, nil
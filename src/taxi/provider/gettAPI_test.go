@@ -286,3 +286,37 @@ func TestGettAPIPrice0(t *testing.T) {
 	assert.Equal(t, 0, len(res))
 	assert.Equal(t, gock.IsDone(), true)
 }
+
+func TestGettAPIPreOrder(t *testing.T) {
+	defer gock.Off()
+	// ETA для предварительного заказа не запрашиваем, поэтому мок для /eta не нужен
+	gock.New("https://api.gett.com").
+		Get("/v1/availability/price").
+		MatchParam("scheduled_at", "2026-10-17T09:30:00\\+03:00").
+		Reply(200).
+		File("_test_jsons/gettPrice.preorder.json")
+
+	gock.InterceptClient(testHttpClient)
+	assert.True(t, tGettAPI.SupportsPreOrder())
+	res, err := tGettAPI.GetAPIData(testContext, testHTTPRequester, testTaxiRequestScheduled)
+	assert.Nil(t, err)
+	assert.Equal(t, []service.APIData{
+		service.APIData{
+			DisplayName: "Gett Комфорт",
+			PriceMax:    400,
+			PriceMin:    350,
+			PriceMean:   375,
+			ProductID:   "e9e71379-7d5a-4930-899c-996b83616f87",
+			TariffName:  "gett_comfort",
+			Eta:         0,
+			TemplateVars: map[string]string{
+				"%from.lat%":   testTaxiRequestMoscow.Point1.LatStr,
+				"%from.lon%":   testTaxiRequestMoscow.Point1.LonStr,
+				"%to.lat%":     testTaxiRequestMoscow.Point2.LatStr,
+				"%to.lon%":     testTaxiRequestMoscow.Point2.LonStr,
+				"%product.id%": "e9e71379-7d5a-4930-899c-996b83616f87",
+			},
+		},
+	}, res)
+	assert.Equal(t, gock.IsDone(), true)
+}
@@ -1,23 +1,247 @@
 package provider
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
 	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrUnknownHandler - в настройках указан провайдер, для которого не зарегистрирована фабрика
+	ErrUnknownHandler = errors.New("Unknown provider handler")
+	// ErrProviderConfig - не смогли создать провайдера из его настроек
+	ErrProviderConfig = errors.New("Cannot create provider from config")
+)
+
+// Factory - создает провайдера из его настроек; handler - имя хэндлера из settings.json и таблицы provider
+type Factory func(handler string, config json.RawMessage) (service.APIDataGetter, error)
+
+var (
+	factories   = map[string]Factory{}
+	factoriesMu = &sync.Mutex{}
 )
 
-// ProvidersList - список провайдеров
-type ProvidersList struct {
-	Gett      gettAPI      `json:"gett"`
-	Uber      uberAPI      `json:"uber"`
-	Citymobil citymobilAPI `json:"citymobil"`
+// Register - регистрирует фабрику провайдера под именем хэндлера.
+// Повторная регистрация одного и того же хэндлера - ошибка программиста, поэтому паникуем
+func Register(handler string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if factory == nil {
+		panic("provider: Register factory is nil for " + handler)
+	}
+	if _, ok := factories[handler]; ok {
+		panic("provider: Register called twice for " + handler)
+	}
+	factories[handler] = factory
+}
+
+// Handlers - возвращает отсортированный список зарегистрированных хэндлеров
+func Handlers() []string {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	handlers := make([]string, 0, len(factories))
+	for handler := range factories {
+		handlers = append(handlers, handler)
+	}
+	sort.Strings(handlers)
+	return handlers
+}
+
+func factoryByHandler(handler string) (Factory, bool) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factory, ok := factories[handler]
+	return factory, ok
+}
+
+// ProvidersList - настройки провайдеров: имя хэндлера -> сырой json с настройками провайдера.
+// Если в настройках указано поле "type", провайдер создается фабрикой этого типа (например, "generic"),
+// иначе - фабрикой, зарегистрированной под именем хэндлера
+type ProvidersList map[string]json.RawMessage
+
+type providerKind struct {
+	Type string `json:"type"`
+}
+
+func (pl ProvidersList) factoryName(handler string) string {
+	var kind providerKind
+	if err := decodeConfig(pl[handler], &kind); err != nil || kind.Type == "" {
+		return handler
+	}
+	return kind.Type
+}
+
+// Handlers - хэндлеры провайдеров из настроек по алфавиту
+func (pl ProvidersList) Handlers() []string {
+	handlers := make([]string, 0, len(pl))
+	for handler := range pl {
+		handlers = append(handlers, handler)
+	}
+	sort.Strings(handlers)
+	return handlers
+}
+
+// Names - имена провайдеров (APIName) из настроек по алфавиту: поле "name" или имя хэндлера.
+// Настройки, которые не разбираются, пропускаем, о них сообщает Validate
+func (pl ProvidersList) Names() []string {
+	names := make([]string, 0, len(pl))
+	for handler, config := range pl {
+		var pc providerCommon
+		if err := decodeConfig(config, &pc); err != nil {
+			continue
+		}
+		if pc.Name == "" {
+			pc.Name = handler
+		}
+		names = append(names, pc.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// APIGetters - создает провайдеров по их настройкам.
+// Если для каких-то хэндлеров нет зарегистрированной фабрики, возвращаем ошибку со списком таких хэндлеров
+func (pl ProvidersList) APIGetters() ([]service.APIDataGetter, error) {
+	handlers := pl.Handlers()
+	getters := make([]service.APIDataGetter, 0, len(handlers))
+	unknown := make([]string, 0)
+	for _, handler := range handlers {
+		factoryName := pl.factoryName(handler)
+		factory, ok := factoryByHandler(factoryName)
+		if !ok {
+			if factoryName != handler {
+				handler = fmt.Sprintf("%v (type %v)", handler, factoryName)
+			}
+			unknown = append(unknown, handler)
+			continue
+		}
+		getter, err := factory(handler, pl[handler])
+		if err != nil {
+			return nil, errors.Wrapf(ErrProviderConfig, "%v: %v", handler, err.Error())
+		}
+		getters = append(getters, getter)
+	}
+	if len(unknown) != 0 {
+		return nil, errors.Wrapf(ErrUnknownHandler, "%v (registered: %v)", strings.Join(unknown, ", "), strings.Join(Handlers(), ", "))
+	}
+	return getters, nil
+}
+
+type providerPolicy struct {
+	Name   string               `json:"name"`
+	Policy httprequester.Policy `json:"request_policy"`
+}
+
+// RequestPolicies - политики запросов (таймауты, повторы, hedging) из поля "request_policy" настроек провайдеров.
+// Ключ - имя провайдера (APIName), провайдеры без политики в результат не попадают
+func (pl ProvidersList) RequestPolicies() (map[string]httprequester.Policy, error) {
+	policies := make(map[string]httprequester.Policy)
+	for handler, config := range pl {
+		var pp providerPolicy
+		if err := decodeConfig(config, &pp); err != nil {
+			return nil, errors.Wrapf(ErrProviderConfig, "%v: request_policy: %v", handler, err.Error())
+		}
+		if pp.Policy.IsEmpty() {
+			continue
+		}
+		if pp.Name == "" {
+			pp.Name = handler
+		}
+		policies[pp.Name] = pp.Policy
+	}
+	return policies, nil
+}
+
+// providerCommon - поля, общие для настроек всех провайдеров
+type providerCommon struct {
+	Name         string
+	Host         string
+	PriceMethod  string
+	TariffGroups []tariffGroup
+}
+
+// Validate - проверяем настройки всех провайдеров: известный хэндлер, обязательные поля, адрес провайдера,
+// уникальность имен и групп тарифов. Возвращаем все найденные проблемы, по хэндлерам в алфавитном порядке
+func (pl ProvidersList) Validate() []error {
+	handlers := make([]string, 0, len(pl))
+	for handler := range pl {
+		handlers = append(handlers, handler)
+	}
+	sort.Strings(handlers)
+
+	problems := make([]error, 0)
+	if len(handlers) == 0 {
+		problems = append(problems, errors.Wrap(ErrProviderConfig, "no providers"))
+	}
+	names := make(map[string]string, len(handlers))
+	for _, handler := range handlers {
+		var pc providerCommon
+		if err := decodeConfig(pl[handler], &pc); err != nil {
+			problems = append(problems, errors.Wrapf(ErrProviderConfig, "%v: %v", handler, err.Error()))
+			continue
+		}
+		if pc.Name == "" {
+			pc.Name = handler
+		}
+		if other, ok := names[pc.Name]; ok {
+			problems = append(problems, errors.Wrapf(ErrProviderConfig, "%v: name %v is already used by %v", handler, pc.Name, other))
+		}
+		names[pc.Name] = handler
+		factoryName := pl.factoryName(handler)
+		if factory, ok := factoryByHandler(factoryName); !ok {
+			problems = append(problems, errors.Wrapf(ErrUnknownHandler, "%v (type %v)", handler, factoryName))
+		} else if _, err := factory(handler, pl[handler]); err != nil {
+			problems = append(problems, errors.Wrapf(ErrProviderConfig, "%v: %v", handler, err.Error()))
+		}
+		if err := validateHost(pc.Host); err != nil {
+			problems = append(problems, errors.Wrapf(ErrProviderConfig, "%v: host: %v", handler, err.Error()))
+		}
+		if pc.PriceMethod == "" {
+			problems = append(problems, errors.Wrapf(ErrProviderConfig, "%v: priceMethod is empty", handler))
+		}
+		groups := make(map[int]bool, len(pc.TariffGroups))
+		for _, group := range pc.TariffGroups {
+			if groups[group.ID] {
+				problems = append(problems, errors.Wrapf(ErrProviderConfig, "%v: duplicate tariff group %v", handler, group.ID))
+			}
+			groups[group.ID] = true
+		}
+		var pp providerPolicy
+		if err := decodeConfig(pl[handler], &pp); err != nil {
+			problems = append(problems, errors.Wrapf(ErrProviderConfig, "%v: request_policy: %v", handler, err.Error()))
+		}
+	}
+	return problems
+}
+
+// validateHost - адрес провайдера: http или https и непустой хост
+func validateHost(host string) error {
+	if host == "" {
+		return errors.New("empty")
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("%q is not an http(s) URL", host)
+	}
+	return nil
 }
 
-// APIGetters - возвращает все провайдеры
-func (pl *ProvidersList) APIGetters() []service.APIDataGetter {
-	return []service.APIDataGetter{
-		pl.Gett,
-		pl.Uber,
-		pl.Citymobil,
+// decodeConfig - общий для провайдеров разбор настроек в структуру
+func decodeConfig(config json.RawMessage, holder interface{}) error {
+	if len(config) == 0 {
+		return nil
 	}
+	return json.Unmarshal(config, holder)
 }
 
 func secondsToMins(eta int) int {
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/stretchr/testify/assert"
+)
+
+var testProvidersSettings = []byte(`{
+	"gett": {
+		"host": "https://api.gett.com",
+		"priceMethod": "/v1/availability/price",
+		"timeMethod": "/v1/availability/eta"
+	},
+	"uber": {
+		"name": "uber",
+		"host": "https://api.uber.com",
+		"dgisClientID": "xxx"
+	},
+	"citymobil": {
+		"name": "citymobil",
+		"tariffGroups": [{"id": 2, "name": "Эконом"}]
+	}
+}`)
+
+func TestProvidersListAPIGetters(t *testing.T) {
+	var pl ProvidersList
+	assert.Nil(t, json.Unmarshal(testProvidersSettings, &pl))
+	getters, err := pl.APIGetters()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(getters))
+	names := make([]string, len(getters))
+	for i, g := range getters {
+		names[i] = g.APIName()
+	}
+	assert.Equal(t, []string{"citymobil", "gett", "uber"}, names)
+	gett, ok := getters[1].(gettAPI)
+	assert.True(t, ok)
+	assert.Equal(t, "https://api.gett.com", gett.Host)
+	uber, ok := getters[2].(uberAPI)
+	assert.True(t, ok)
+	assert.Equal(t, "xxx", uber.DgisClientID)
+	city, ok := getters[0].(citymobilAPI)
+	assert.True(t, ok)
+	assert.Equal(t, []tariffGroup{{ID: 2, Name: "Эконом"}}, city.TariffGroups)
+}
+
+func TestProvidersListUnknownHandler(t *testing.T) {
+	pl := ProvidersList{
+		"gett":    json.RawMessage(`{}`),
+		"foo":     json.RawMessage(`{}`),
+		"unknown": json.RawMessage(`{}`),
+	}
+	getters, err := pl.APIGetters()
+	assert.Nil(t, getters)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrUnknownHandler.Error())
+	assert.Contains(t, err.Error(), "foo, unknown")
+}
+
+func TestProvidersListBadConfig(t *testing.T) {
+	pl := ProvidersList{
+		"gett": json.RawMessage(`{"host": 1}`),
+	}
+	getters, err := pl.APIGetters()
+	assert.Nil(t, getters)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrProviderConfig.Error())
+}
+
+func TestRegisterTwice(t *testing.T) {
+	assert.Panics(t, func() {
+		Register("gett", newGettAPI)
+	})
+}
+
+func TestRequestPolicies(t *testing.T) {
+	policies, err := ProvidersList{
+		"gett":   []byte(`{"name": "gett_main", "request_policy": {"timeout_ms": 500, "max_retries": 1}}`),
+		"uber":   []byte(`{"request_policy": {"hedge_ms": 200}}`),
+		"yandex": []byte(`{"name": "yandex"}`),
+	}.RequestPolicies()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]httprequester.Policy{
+		"gett_main": httprequester.Policy{Timeout: duration.Milliseconds(500 * time.Millisecond), MaxRetries: 1},
+		"uber":      httprequester.Policy{HedgeDelay: duration.Milliseconds(200 * time.Millisecond)},
+	}, policies)
+}
+
+func TestProvidersListNames(t *testing.T) {
+	assert.Equal(t, []string{"gett_main", "uber"}, ProvidersList{
+		"gett": []byte(`{"name": "gett_main"}`),
+		"uber": []byte(`{}`),
+		"bad":  []byte(`[]`),
+	}.Names())
+}
+
+func TestProvidersListValidate(t *testing.T) {
+	assert.Empty(t, ProvidersList{
+		"gett":      json.RawMessage(`{"host": "https://api.gett.com", "priceMethod": "/price"}`),
+		"citymobil": json.RawMessage(`{"host": "http://citymobil.test:8080", "priceMethod": "/", "tariffGroups": [{"id": 2}, {"id": 4}]}`),
+	}.Validate())
+
+	problems := ProvidersList{
+		"gett":      json.RawMessage(`{"host": "https://api.gett.com", "priceMethod": "/price"}`),
+		"gett_copy": json.RawMessage(`{"type": "gett", "name": "gett", "host": "api.gett.com"}`),
+		"foo":       json.RawMessage(`{"host": "https://foo.test", "priceMethod": "/price"}`),
+		"citymobil": json.RawMessage(`{"host": "https://", "priceMethod": "/", "tariffGroups": [{"id": 2}, {"id": 2}]}`),
+	}.Validate()
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Error()
+	}
+	assert.Equal(t, []string{
+		`citymobil: host: "https://" is not an http(s) URL: Cannot create provider from config`,
+		`citymobil: duplicate tariff group 2: Cannot create provider from config`,
+		`foo (type foo): Unknown provider handler`,
+		`gett_copy: name gett is already used by gett: Cannot create provider from config`,
+		`gett_copy: host: "api.gett.com" is not an http(s) URL: Cannot create provider from config`,
+		`gett_copy: priceMethod is empty: Cannot create provider from config`,
+	}, messages)
+	assert.NotEmpty(t, ProvidersList{}.Validate())
+}
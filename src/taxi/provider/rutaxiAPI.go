@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+)
+
+// ErrRutaxiStatus - провайдер вернул статус, отличный от ok
+var ErrRutaxiStatus = errors.New("Order status is not ok")
+
+// rutaxiAPI - провайдеры семейства rutaxi / Престиж: форма с номером заказа, который генерируем мы
+type rutaxiAPI struct {
+	// kind - тип провайдера (rutaxi, prestizh), префикс тарифов в таблице provider. В отличие от Name
+	// не настраивается, поэтому переименование провайдера не ломает тарифы
+	kind        string
+	Name        string
+	DisplayName string
+	Headers     []httprequester.Dict
+	Host        string
+	PriceMethod string
+	Key         string
+	Tariffs     []string
+}
+
+type rutaxiPrice struct {
+	Tariff string  `json:"tariff"`
+	Price  float64 `json:"price"`
+	Time   int     `json:"time"`
+}
+
+type rutaxiPriceResponse struct {
+	OID    string        `json:"oid"`
+	Status string        `json:"status"`
+	Result []rutaxiPrice `json:"result"`
+}
+
+// rutaxiTariffMap - код тарифа провайдера -> суффикс тарифа в таблице provider и название тарифа
+var rutaxiTariffMap = map[string]struct {
+	Tariff string
+	Title  string
+}{
+	"1": {"economy", "Эконом"},
+	"2": {"comfort", "Комфорт"},
+	"3": {"business", "Бизнес"},
+	"4": {"minivan", "Минивэн"},
+	"5": {"universal", "Универсал"},
+}
+
+func init() {
+	Register("rutaxi", rutaxiFactory("rutaxi"))
+	Register("prestizh", rutaxiFactory("prestizh"))
+}
+
+// rutaxiFactory - фабрика провайдеров типа kind; хэндлер в настройках может называться иначе
+func rutaxiFactory(kind string) Factory {
+	return func(handler string, config json.RawMessage) (service.APIDataGetter, error) {
+		h := rutaxiAPI{kind: kind}
+		if err := decodeConfig(config, &h); err != nil {
+			return nil, err
+		}
+		if h.Name == "" {
+			h.Name = handler
+		}
+		return h, nil
+	}
+}
+
+func (h rutaxiAPI) APIName() string {
+	return h.Name
+}
+
+func (h rutaxiAPI) GetAPIData(ctx context.Context, httpreq *httprequester.Requester, taxiReq service.Request) ([]service.APIData, error) {
+	oid := getPrestizhOID(taxiReq.ReqID)
+	if oid == "" {
+		oid = getIntOID()
+	}
+	priceResp, errPrices := h.price(ctx, httpreq, oid, taxiReq.Point1, taxiReq.Point2)
+	if errPrices != nil {
+		return nil, errors.Wrapf(errPrices, "%v: Cannot request prices, oid %v", h.Name, oid)
+	}
+	apiDatas := h.makeAPIDatas(*priceResp, taxiReq.Point1, taxiReq.Point2)
+	for _, p := range priceResp.Result {
+		if p.Time <= 0 {
+			return apiDatas, service.InvalidTime(h.Name, fmt.Sprintf("%v: Time <= 0, oid %v", h.Name, oid))
+		}
+	}
+	return apiDatas, nil
+}
+
+func (h rutaxiAPI) makeAPIDatas(priceResp rutaxiPriceResponse, p1, p2 service.Point) []service.APIData {
+	result := make([]service.APIData, 0, len(priceResp.Result))
+	for _, p := range priceResp.Result {
+		displayName := h.DisplayName
+		var tariffName string
+		if val, ok := rutaxiTariffMap[p.Tariff]; ok {
+			displayName = fmt.Sprintf("%v %v", h.DisplayName, val.Title)
+			tariffName = fmt.Sprintf("%v_%v", h.kind, val.Tariff)
+		}
+		var eta int
+		if p.Time > 0 {
+			eta = p.Time
+		}
+		data := service.APIData{
+			DisplayName: displayName,
+			PriceMax:    int(p.Price),
+			PriceMin:    int(p.Price),
+			PriceMean:   p.Price,
+			ProductID:   p.Tariff,
+			TariffName:  tariffName,
+			Eta:         eta,
+			TemplateVars: map[string]string{
+				"%from.lat%":     p1.LatStr,
+				"%from.lon%":     p1.LonStr,
+				"%from.address%": p1.Address,
+				"%to.lat%":       p2.LatStr,
+				"%to.lon%":       p2.LonStr,
+				"%to.address%":   p2.Address,
+				"%product.id%":   p.Tariff,
+				"%order.id%":     priceResp.OID,
+			},
+		}
+		result = append(result, data)
+	}
+	return result
+}
+
+func (h rutaxiAPI) price(ctx context.Context, httpreq *httprequester.Requester, oid string, p1, p2 service.Point) (*rutaxiPriceResponse, error) {
+	form := []httprequester.Dict{
+		{
+			Key:   "key",
+			Value: h.Key,
+		},
+		{
+			Key:   "oid",
+			Value: oid,
+		},
+		{
+			Key:   "from_lat",
+			Value: p1.LatStr,
+		},
+		{
+			Key:   "from_lon",
+			Value: p1.LonStr,
+		},
+		{
+			Key:   "from_address",
+			Value: p1.Address,
+		},
+		{
+			Key:   "to_lat",
+			Value: p2.LatStr,
+		},
+		{
+			Key:   "to_lon",
+			Value: p2.LonStr,
+		},
+		{
+			Key:   "to_address",
+			Value: p2.Address,
+		},
+	}
+	for _, tariff := range h.Tariffs {
+		form = append(form, httprequester.Dict{
+			Key:   "tariff",
+			Value: tariff,
+		})
+	}
+	p := new(rutaxiPriceResponse)
+	priceURL := fmt.Sprintf("%v%v", h.Host, h.PriceMethod)
+	errPrice := httpreq.PostForm(ctx, priceURL, h.Headers, form, p)
+	if errPrice != nil {
+		return p, errPrice
+	}
+	if p.Status != "ok" {
+		return p, apierror.New(h.Name, "price", apierror.KindInvalidPrice, errors.Wrap(ErrRutaxiStatus, p.Status))
+	}
+	if p.OID == "" {
+		p.OID = oid
+	}
+	if len(p.Result) == 0 {
+		return p, service.InvalidPrice(h.Name, "Price list is empty")
+	}
+	for _, price := range p.Result {
+		if price.Price <= 0 {
+			return p, service.InvalidPrice(h.Name, "Price <= 0")
+		}
+	}
+	return p, nil
+}
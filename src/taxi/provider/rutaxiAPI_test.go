@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var tRutaxiAPI = rutaxiAPI{
+	kind:        "rutaxi",
+	Name:        "rutaxi",
+	DisplayName: "RuTaxi",
+	Host:        "https://api.rutaxi.test",
+	PriceMethod: "/api/calc",
+	Key:         "xxx",
+	Tariffs:     []string{"1", "3"},
+}
+
+var tRutaxiReq = func() service.Request {
+	req := testTaxiRequestMoscow
+	req.ReqID = "host/aBcDeF-000001-2222-3333-4444-5555"
+	return req
+}()
+
+func rutaxiTemplateVars(productID, oid string) map[string]string {
+	return map[string]string{
+		"%from.lat%":     tRutaxiReq.Point1.LatStr,
+		"%from.lon%":     tRutaxiReq.Point1.LonStr,
+		"%from.address%": tRutaxiReq.Point1.Address,
+		"%to.lat%":       tRutaxiReq.Point2.LatStr,
+		"%to.lon%":       tRutaxiReq.Point2.LonStr,
+		"%to.address%":   tRutaxiReq.Point2.Address,
+		"%product.id%":   productID,
+		"%order.id%":     oid,
+	}
+}
+
+func TestPrestizhOID(t *testing.T) {
+	assert.Equal(t, "host/aBcDeF0000012222333344445", getPrestizhOID(tRutaxiReq.ReqID))
+	assert.Equal(t, "123", getPrestizhOID("1-2-3"))
+}
+
+func TestRutaxiAPI(t *testing.T) {
+	defer gock.Off()
+	var form map[string][]string
+	// отдельный matcher, чтобы не добавлять проверку формы в gock.DefaultMatcher для всех тестов
+	formMatcher := gock.NewBasicMatcher()
+	formMatcher.Add(func(req *http.Request, ereq *gock.Request) (bool, error) {
+		if err := req.ParseMultipartForm(1 << 20); err != nil {
+			return false, err
+		}
+		form = req.MultipartForm.Value
+		return true, nil
+	})
+	gock.New("https://api.rutaxi.test").
+		Post("/api/calc").
+		SetMatcher(formMatcher).
+		Reply(200).
+		File("_test_jsons/rutaxi.json")
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tRutaxiAPI.GetAPIData(testContext, testHTTPRequester, tRutaxiReq)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{getPrestizhOID(tRutaxiReq.ReqID)}, form["oid"])
+	assert.Equal(t, []string{"xxx"}, form["key"])
+	assert.Equal(t, []string{tRutaxiReq.Point1.LatStr}, form["from_lat"])
+	assert.Equal(t, []string{tRutaxiReq.Point2.LonStr}, form["to_lon"])
+	assert.Equal(t, []string{"1", "3"}, form["tariff"])
+	assert.Equal(t, []service.APIData{
+		service.APIData{
+			DisplayName:  "RuTaxi Эконом",
+			PriceMax:     180,
+			PriceMin:     180,
+			PriceMean:    180,
+			ProductID:    "1",
+			TariffName:   "rutaxi_economy",
+			Eta:          7,
+			TemplateVars: rutaxiTemplateVars("1", "123abc"),
+		},
+		service.APIData{
+			DisplayName:  "RuTaxi Бизнес",
+			PriceMax:     420,
+			PriceMin:     420,
+			PriceMean:    420,
+			ProductID:    "3",
+			TariffName:   "rutaxi_business",
+			Eta:          12,
+			TemplateVars: rutaxiTemplateVars("3", "123abc"),
+		},
+	}, res)
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestRutaxiAPINoTime(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.rutaxi.test").
+		Post("/api/calc").
+		Reply(200).
+		File("_test_jsons/rutaxi.NoTime.json")
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tRutaxiAPI.GetAPIData(testContext, testHTTPRequester, tRutaxiReq)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), service.ErrInvalidTime.Error())
+	assert.Equal(t, []service.APIData{
+		service.APIData{
+			DisplayName:  "RuTaxi Эконом",
+			PriceMax:     180,
+			PriceMin:     180,
+			PriceMean:    180,
+			ProductID:    "1",
+			TariffName:   "rutaxi_economy",
+			Eta:          0,
+			TemplateVars: rutaxiTemplateVars("1", getPrestizhOID(tRutaxiReq.ReqID)),
+		},
+	}, res)
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestRutaxiAPIPrice0(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.rutaxi.test").
+		Post("/api/calc").
+		Reply(200).
+		File("_test_jsons/rutaxi.0.json")
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tRutaxiAPI.GetAPIData(testContext, testHTTPRequester, tRutaxiReq)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), service.ErrInvalidPrice.Error())
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestRutaxiAPIStatusError(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.rutaxi.test").
+		Post("/api/calc").
+		Reply(200).
+		File("_test_jsons/rutaxi.Error.json")
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tRutaxiAPI.GetAPIData(testContext, testHTTPRequester, tRutaxiReq)
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrRutaxiStatus, errors.Cause(err))
+	assert.Equal(t, apierror.KindInvalidPrice, apierror.KindOf(err))
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestRutaxiAPI500(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.rutaxi.test").
+		Post("/api/calc").
+		Reply(500)
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tRutaxiAPI.GetAPIData(testContext, testHTTPRequester, tRutaxiReq)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestPrestizhRegistered(t *testing.T) {
+	getters, err := ProvidersList{"prestizh": []byte(`{"displayName": "Престиж"}`)}.APIGetters()
+	assert.Nil(t, err)
+	assert.Equal(t, "prestizh", getters[0].APIName())
+	assert.Equal(t, "Престиж", getters[0].(rutaxiAPI).DisplayName)
+}
+
+func TestRutaxiTariffByKind(t *testing.T) {
+	getters, err := ProvidersList{"prestizh_spb": []byte(`{"type": "prestizh", "name": "prestizh_new"}`)}.APIGetters()
+	if !assert.Nil(t, err) {
+		return
+	}
+	h := getters[0].(rutaxiAPI)
+	assert.Equal(t, "prestizh_new", h.APIName())
+	datas := h.makeAPIDatas(rutaxiPriceResponse{Result: []rutaxiPrice{{Tariff: "2", Price: 300, Time: 5}}}, tRutaxiReq.Point1, tRutaxiReq.Point2)
+	assert.Equal(t, "prestizh_comfort", datas[0].TariffName, "tariffs are keyed on the provider type, not its name")
+}
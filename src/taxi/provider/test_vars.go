@@ -52,3 +52,31 @@ var testTaxiRequestMoscow = service.Request{
 	},
 	OnlyAPI: true,
 }
+
+var testWaypoint = service.Point{
+	Lon:     37.615,
+	LonStr:  "37.615",
+	Lat:     55.755,
+	LatStr:  "55.755",
+	Address: "Тестовая остановка",
+}
+
+var testTaxiRequestWaypoints = service.Request{
+	ReqID:     "124",
+	RegionID:  32,
+	Point1:    testTaxiRequestMoscow.Point1,
+	Point2:    testTaxiRequestMoscow.Point2,
+	Waypoints: []service.Point{testWaypoint},
+	OnlyAPI:   true,
+}
+
+var testPickupTime = time.Date(2026, 10, 17, 9, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+var testTaxiRequestScheduled = service.Request{
+	ReqID:      "125",
+	RegionID:   32,
+	Point1:     testTaxiRequestMoscow.Point1,
+	Point2:     testTaxiRequestMoscow.Point2,
+	OnlyAPI:    true,
+	PickupTime: &testPickupTime,
+}
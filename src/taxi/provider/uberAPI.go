@@ -2,16 +2,22 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/errorswrapper"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
 
 	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
 )
 
+// metersInMile - Uber отдает расстояние в милях
+const metersInMile = 1609.344
+
 type uberAPI struct {
 	Name         string
 	Headers      []httprequester.Dict
@@ -49,10 +55,30 @@ type uberTimesResponse struct {
 	Times []uberTime `json:"times"`
 }
 
+func init() {
+	Register("uber", newUberAPI)
+}
+
+func newUberAPI(handler string, config json.RawMessage) (service.APIDataGetter, error) {
+	var h uberAPI
+	if err := decodeConfig(config, &h); err != nil {
+		return nil, err
+	}
+	if h.Name == "" {
+		h.Name = handler
+	}
+	return h, nil
+}
+
 func (h uberAPI) APIName() string {
 	return h.Name
 }
 
+// SupportsWaypoints - Uber считает цену поездки с промежуточными остановками
+func (h uberAPI) SupportsWaypoints() bool {
+	return true
+}
+
 func (h uberAPI) GetAPIData(ctx context.Context, httpreq *httprequester.Requester, taxiReq service.Request) ([]service.APIData, error) {
 	var errPrices, errTimes error
 	var uberPrices *uberPricesResponse
@@ -62,7 +88,7 @@ func (h uberAPI) GetAPIData(ctx context.Context, httpreq *httprequester.Requeste
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		uberPrices, errPrices = h.prices(ctx, httpreq, taxiReq.Point1, taxiReq.Point2)
+		uberPrices, errPrices = h.prices(ctx, httpreq, taxiReq.Point1, taxiReq.Point2, taxiReq.Waypoints)
 	}()
 	go func() {
 		defer wg.Done()
@@ -71,9 +97,10 @@ func (h uberAPI) GetAPIData(ctx context.Context, httpreq *httprequester.Requeste
 	wg.Wait()
 
 	if errPrices != nil {
-		return nil, errors.Wrap(errPrices, "Uber: Cannot request prices")
+		// ошибку второго запроса не теряем: по ней видно, был ли провайдер недоступен целиком
+		return nil, errorswrapper.Append(errors.Wrap(errPrices, "Uber: Cannot request prices"), errors.Wrap(errTimes, "Uber: Cannot request times"))
 	}
-	apiDatas := h.makeAPIDatas(*uberPrices, *uberTimes, taxiReq.Point1, taxiReq.Point2)
+	apiDatas := h.makeAPIDatas(*uberPrices, *uberTimes, taxiReq.Point1, taxiReq.Point2, taxiReq.WaypointTemplateVars())
 	if errTimes != nil {
 		return apiDatas, errors.Wrap(errTimes, "Uber: Cannot request times")
 	}
@@ -111,7 +138,7 @@ var uberDisplayMap = map[string]string{
 	"uberx":      "Uber X",
 }
 
-func (h uberAPI) makeAPIDatas(prices uberPricesResponse, uberTimes uberTimesResponse, p1, p2 service.Point) []service.APIData {
+func (h uberAPI) makeAPIDatas(prices uberPricesResponse, uberTimes uberTimesResponse, p1, p2 service.Point, waypointVars map[string]string) []service.APIData {
 	result := make([]service.APIData, 0)
 	for _, p := range prices.Prices {
 		var pEta int
@@ -148,13 +175,21 @@ func (h uberAPI) makeAPIDatas(prices uberPricesResponse, uberTimes uberTimesResp
 				"%product.id%":   p.ProductID,
 				"%client.id%":    h.DgisClientID,
 			},
+			SurgeMultiplier: p.SurgeMultiplier,
+			MinimumFare:     p.Minimum,
+			Currency:        p.Currency,
+			Distance:        int(math.Round(p.Distance * metersInMile)),
+			Duration:        secondsToMins(p.Duration),
+		}
+		for name, value := range waypointVars {
+			data.TemplateVars[name] = value
 		}
 		result = append(result, data)
 	}
 	return result
 }
 
-func (h uberAPI) prices(ctx context.Context, httpreq *httprequester.Requester, p1, p2 service.Point) (*uberPricesResponse, error) {
+func (h uberAPI) prices(ctx context.Context, httpreq *httprequester.Requester, p1, p2 service.Point, waypoints []service.Point) (*uberPricesResponse, error) {
 	params := []httprequester.Dict{
 		{
 			Key:   "start_latitude",
@@ -173,6 +208,18 @@ func (h uberAPI) prices(ctx context.Context, httpreq *httprequester.Requester, p
 			Value: p2.LonStr,
 		},
 	}
+	for i, wp := range waypoints {
+		params = append(params,
+			httprequester.Dict{
+				Key:   fmt.Sprintf("waypoint_%d_latitude", i+1),
+				Value: wp.LatStr,
+			},
+			httprequester.Dict{
+				Key:   fmt.Sprintf("waypoint_%d_longitude", i+1),
+				Value: wp.LonStr,
+			},
+		)
+	}
 	prices := new(uberPricesResponse)
 	priceURL := fmt.Sprintf("%v%v", h.Host, h.PriceMethod)
 	err := httpreq.Get(ctx, priceURL, h.Headers, params, prices)
@@ -180,11 +227,11 @@ func (h uberAPI) prices(ctx context.Context, httpreq *httprequester.Requester, p
 		return prices, err
 	}
 	if len(prices.Prices) == 0 {
-		return prices, errors.Wrap(service.ErrInvalidPrice, "Price list is empty")
+		return prices, service.InvalidPrice(h.Name, "Price list is empty")
 	}
 	for _, price := range prices.Prices {
 		if (price.LowEstimate+price.HighEstimate)/2 <= 0 {
-			return prices, errors.Wrap(service.ErrInvalidPrice, "Price <= 0")
+			return prices, service.InvalidPrice(h.Name, "Price <= 0")
 		}
 	}
 	return prices, nil
@@ -208,11 +255,11 @@ func (h uberAPI) times(ctx context.Context, httpreq *httprequester.Requester, p1
 		return uberTimes, err
 	}
 	if len(uberTimes.Times) == 0 {
-		return uberTimes, errors.Wrap(service.ErrInvalidTime, "Time list is empty")
+		return uberTimes, service.InvalidTime(h.Name, "Time list is empty")
 	}
 	for _, e := range uberTimes.Times {
 		if e.Estimate <= 0 {
-			return uberTimes, errors.Wrap(service.ErrInvalidTime, "Time <= 0")
+			return uberTimes, service.InvalidTime(h.Name, "Time <= 0")
 		}
 	}
 	return uberTimes, nil
@@ -57,6 +57,11 @@ func TestUberAPI(t *testing.T) {
 				"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
 				"%to.address%":   testTaxiRequestMoscow.Point2.Address,
 			},
+			SurgeMultiplier: 1,
+			MinimumFare:     20,
+			Currency:        "AED",
+			Distance:        7854,
+			Duration:        16,
 		},
 		service.APIData{
 			DisplayName: "Uber SELECT",
@@ -76,6 +81,11 @@ func TestUberAPI(t *testing.T) {
 				"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
 				"%to.address%":   testTaxiRequestMoscow.Point2.Address,
 			},
+			SurgeMultiplier: 1,
+			MinimumFare:     15,
+			Currency:        "AED",
+			Distance:        7854,
+			Duration:        16,
 		},
 	})
 	assert.Equal(t, gock.IsDone(), true)
@@ -116,6 +126,11 @@ func TestUberAPITime0(t *testing.T) {
 				"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
 				"%to.address%":   testTaxiRequestMoscow.Point2.Address,
 			},
+			SurgeMultiplier: 1,
+			MinimumFare:     20,
+			Currency:        "AED",
+			Distance:        7854,
+			Duration:        16,
 		},
 		service.APIData{
 			DisplayName: "Uber SELECT",
@@ -135,6 +150,11 @@ func TestUberAPITime0(t *testing.T) {
 				"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
 				"%to.address%":   testTaxiRequestMoscow.Point2.Address,
 			},
+			SurgeMultiplier: 1,
+			MinimumFare:     15,
+			Currency:        "AED",
+			Distance:        7854,
+			Duration:        16,
 		},
 	})
 	assert.Equal(t, gock.IsDone(), true)
@@ -174,6 +194,11 @@ func TestUberAPITimeEmpty(t *testing.T) {
 				"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
 				"%to.address%":   testTaxiRequestMoscow.Point2.Address,
 			},
+			SurgeMultiplier: 1,
+			MinimumFare:     20,
+			Currency:        "AED",
+			Distance:        7854,
+			Duration:        16,
 		},
 		service.APIData{
 			DisplayName: "Uber SELECT",
@@ -193,6 +218,11 @@ func TestUberAPITimeEmpty(t *testing.T) {
 				"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
 				"%to.address%":   testTaxiRequestMoscow.Point2.Address,
 			},
+			SurgeMultiplier: 1,
+			MinimumFare:     15,
+			Currency:        "AED",
+			Distance:        7854,
+			Duration:        16,
 		},
 	})
 	assert.Equal(t, gock.IsDone(), true)
@@ -231,6 +261,11 @@ func TestUberAPITimeErr500(t *testing.T) {
 				"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
 				"%to.address%":   testTaxiRequestMoscow.Point2.Address,
 			},
+			SurgeMultiplier: 1,
+			MinimumFare:     20,
+			Currency:        "AED",
+			Distance:        7854,
+			Duration:        16,
 		},
 		service.APIData{
 			DisplayName: "Uber SELECT",
@@ -250,6 +285,11 @@ func TestUberAPITimeErr500(t *testing.T) {
 				"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
 				"%to.address%":   testTaxiRequestMoscow.Point2.Address,
 			},
+			SurgeMultiplier: 1,
+			MinimumFare:     15,
+			Currency:        "AED",
+			Distance:        7854,
+			Duration:        16,
 		},
 	})
 	assert.Equal(t, gock.IsDone(), true)
@@ -311,3 +351,29 @@ func TestUberAPIPrice0(t *testing.T) {
 	assert.Equal(t, 0, len(res))
 	assert.Equal(t, gock.IsDone(), true)
 }
+
+func TestUberAPIWaypoints(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.uber.com").
+		Get("/v1/estimates/time").
+		Reply(200).
+		File("_test_jsons/uberTimes.json")
+
+	gock.New("https://api.uber.com").
+		Get("/v1/estimates/price").
+		MatchParam("waypoint_1_latitude", testWaypoint.LatStr).
+		MatchParam("waypoint_1_longitude", testWaypoint.LonStr).
+		Reply(200).
+		File("_test_jsons/uberPrices.json")
+
+	gock.InterceptClient(testHttpClient)
+	assert.True(t, tUberAPI.SupportsWaypoints())
+	res, err := tUberAPI.GetAPIData(testContext, testHTTPRequester, testTaxiRequestWaypoints)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(res))
+	for _, apiData := range res {
+		assert.Equal(t, testWaypoint.LatStr, apiData.TemplateVars["%waypoint1.lat%"])
+		assert.Equal(t, testWaypoint.Address, apiData.TemplateVars["%waypoint1.address%"])
+	}
+	assert.Equal(t, gock.IsDone(), true)
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+)
+
+type yandexAPI struct {
+	Name        string
+	Host        string
+	PriceMethod string
+	Clid        string
+	APIKey      string
+	Classes     []string
+}
+
+type yandexOption struct {
+	ClassName   string  `json:"class_name"`
+	ClassText   string  `json:"class_text"`
+	ClassLevel  int     `json:"class_level"`
+	MinPrice    float64 `json:"min_price"`
+	Price       float64 `json:"price"`
+	PriceText   string  `json:"price_text"`
+	WaitingTime float64 `json:"waiting_time"`
+}
+
+type yandexInfoResponse struct {
+	Currency string         `json:"currency"`
+	Distance float64        `json:"distance"`
+	Time     float64        `json:"time"`
+	Options  []yandexOption `json:"options"`
+}
+
+var yandexTariffMap = map[string]string{
+	"econom":      "yandex_econom",
+	"business":    "yandex_business",
+	"comfortplus": "yandex_comfortplus",
+	"minivan":     "yandex_minivan",
+	"vip":         "yandex_vip",
+}
+
+var yandexDisplayMap = map[string]string{
+	"econom":      "Яндекс.Такси Эконом",
+	"business":    "Яндекс.Такси Комфорт",
+	"comfortplus": "Яндекс.Такси Комфорт+",
+	"minivan":     "Яндекс.Такси Минивэн",
+	"vip":         "Яндекс.Такси Бизнес",
+}
+
+func init() {
+	Register("yandex", newYandexAPI)
+}
+
+func newYandexAPI(handler string, config json.RawMessage) (service.APIDataGetter, error) {
+	var h yandexAPI
+	if err := decodeConfig(config, &h); err != nil {
+		return nil, err
+	}
+	if h.Name == "" {
+		h.Name = handler
+	}
+	return h, nil
+}
+
+func (h yandexAPI) APIName() string {
+	return h.Name
+}
+
+func (h yandexAPI) GetAPIData(ctx context.Context, httpreq *httprequester.Requester, taxiReq service.Request) ([]service.APIData, error) {
+	info, errInfo := h.info(ctx, httpreq, taxiReq.Point1, taxiReq.Point2)
+	if errInfo != nil {
+		return nil, errors.Wrap(errInfo, "Yandex: Cannot request taxi info")
+	}
+	apiDatas := h.makeAPIDatas(*info, taxiReq.Point1, taxiReq.Point2)
+	for _, o := range info.Options {
+		if o.WaitingTime <= 0 {
+			return apiDatas, service.InvalidTime(h.Name, "Yandex: Time <= 0")
+		}
+	}
+	return apiDatas, nil
+}
+
+func (h yandexAPI) makeAPIDatas(info yandexInfoResponse, p1, p2 service.Point) []service.APIData {
+	result := make([]service.APIData, 0, len(info.Options))
+	for _, o := range info.Options {
+		displayName := "Яндекс.Такси"
+		if val, ok := yandexDisplayMap[o.ClassName]; ok {
+			displayName = val
+		} else if o.ClassText != "" {
+			displayName = fmt.Sprintf("%v %v", displayName, o.ClassText)
+		}
+		var priceMin int
+		if o.MinPrice > 0 && o.MinPrice < o.Price {
+			priceMin = int(o.MinPrice)
+		}
+		data := service.APIData{
+			DisplayName: displayName,
+			PriceMin:    priceMin,
+			PriceMax:    int(o.Price),
+			PriceMean:   o.Price,
+			ProductID:   o.ClassName,
+			TariffName:  yandexTariffMap[o.ClassName],
+			Eta:         secondsToMins(int(math.Round(o.WaitingTime))),
+			TemplateVars: map[string]string{
+				"%from.lat%":     p1.LatStr,
+				"%from.lon%":     p1.LonStr,
+				"%from.address%": p1.Address,
+				"%to.lat%":       p2.LatStr,
+				"%to.lon%":       p2.LonStr,
+				"%to.address%":   p2.Address,
+				"%product.id%":   o.ClassName,
+				"%client.id%":    h.Clid,
+			},
+		}
+		result = append(result, data)
+	}
+	return result
+}
+
+func (h yandexAPI) info(ctx context.Context, httpreq *httprequester.Requester, p1, p2 service.Point) (*yandexInfoResponse, error) {
+	params := []httprequester.Dict{
+		{
+			Key:   "clid",
+			Value: h.Clid,
+		},
+		{
+			Key:   "apikey",
+			Value: h.APIKey,
+		},
+		{
+			Key:   "rll",
+			Value: fmt.Sprintf("%v,%v~%v,%v", p1.LonStr, p1.LatStr, p2.LonStr, p2.LatStr),
+		},
+	}
+	if len(h.Classes) != 0 {
+		params = append(params, httprequester.Dict{
+			Key:   "class",
+			Value: strings.Join(h.Classes, ","),
+		})
+	}
+	info := new(yandexInfoResponse)
+	infoURL := fmt.Sprintf("%v%v", h.Host, h.PriceMethod)
+	err := httpreq.Get(ctx, infoURL, nil, params, info)
+	if err != nil {
+		return info, err
+	}
+	if len(info.Options) == 0 {
+		return info, service.InvalidPrice(h.Name, "Price list is empty")
+	}
+	for _, o := range info.Options {
+		if o.Price <= 0 {
+			return info, service.InvalidPrice(h.Name, "Price <= 0")
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var tYandexAPI = yandexAPI{
+	Name:        "yandex",
+	Host:        "https://taxi-routeinfo.taxi.yandex.net",
+	PriceMethod: "/taxi_info",
+	Clid:        "dgis",
+	APIKey:      "xxx",
+	Classes:     []string{"econom", "business"},
+}
+
+func yandexTemplateVars(productID string) map[string]string {
+	return map[string]string{
+		"%from.lat%":     testTaxiRequestMoscow.Point1.LatStr,
+		"%from.lon%":     testTaxiRequestMoscow.Point1.LonStr,
+		"%from.address%": testTaxiRequestMoscow.Point1.Address,
+		"%to.lat%":       testTaxiRequestMoscow.Point2.LatStr,
+		"%to.lon%":       testTaxiRequestMoscow.Point2.LonStr,
+		"%to.address%":   testTaxiRequestMoscow.Point2.Address,
+		"%product.id%":   productID,
+		"%client.id%":    "dgis",
+	}
+}
+
+func TestYandexAPI(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://taxi-routeinfo.taxi.yandex.net").
+		Get("/taxi_info").
+		MatchParam("clid", "dgis").
+		MatchParam("apikey", "xxx").
+		MatchParam("rll", "37.610621,55.750376~37.62002,55.760736").
+		MatchParam("class", "econom,business").
+		Reply(200).
+		File("_test_jsons/yandexInfo.json")
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tYandexAPI.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.Nil(t, err)
+	assert.Equal(t, []service.APIData{
+		service.APIData{
+			DisplayName:  "Яндекс.Такси Эконом",
+			PriceMin:     99,
+			PriceMax:     245,
+			PriceMean:    245,
+			ProductID:    "econom",
+			TariffName:   "yandex_econom",
+			Eta:          3,
+			TemplateVars: yandexTemplateVars("econom"),
+		},
+		service.APIData{
+			DisplayName:  "Яндекс.Такси Комфорт",
+			PriceMin:     199,
+			PriceMax:     310,
+			PriceMean:    310,
+			ProductID:    "business",
+			TariffName:   "yandex_business",
+			Eta:          1,
+			TemplateVars: yandexTemplateVars("business"),
+		},
+	}, res)
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestYandexAPINoTime(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://taxi-routeinfo.taxi.yandex.net").
+		Get("/taxi_info").
+		Reply(200).
+		File("_test_jsons/yandexInfo.NoTime.json")
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tYandexAPI.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), service.ErrInvalidTime.Error())
+	assert.Equal(t, []service.APIData{
+		service.APIData{
+			DisplayName:  "Яндекс.Такси Эконом",
+			PriceMin:     99,
+			PriceMax:     245,
+			PriceMean:    245,
+			ProductID:    "econom",
+			TariffName:   "yandex_econom",
+			Eta:          0,
+			TemplateVars: yandexTemplateVars("econom"),
+		},
+	}, res)
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestYandexAPIPrice0(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://taxi-routeinfo.taxi.yandex.net").
+		Get("/taxi_info").
+		Reply(200).
+		File("_test_jsons/yandexInfo.0.json")
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tYandexAPI.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), service.ErrInvalidPrice.Error())
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestYandexAPIEmpty(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://taxi-routeinfo.taxi.yandex.net").
+		Get("/taxi_info").
+		Reply(200).
+		File("_test_jsons/yandexInfo.Empty.json")
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tYandexAPI.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), service.ErrInvalidPrice.Error())
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestYandexAPI500(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://taxi-routeinfo.taxi.yandex.net").
+		Get("/taxi_info").
+		Reply(500)
+
+	gock.InterceptClient(testHttpClient)
+	res, err := tYandexAPI.GetAPIData(testContext, testHTTPRequester, testTaxiRequestMoscow)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(res))
+	assert.Equal(t, gock.IsDone(), true)
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultBatchMaxItems - сколько запросов можно передать в одном пакете по умолчанию
+	defaultBatchMaxItems = 50
+	// defaultBatchConcurrency - сколько запросов пакетов выполняется одновременно по умолчанию
+	defaultBatchConcurrency = 8
+	// defaultBatchWaitTime - общий дедлайн пакета по умолчанию
+	defaultBatchWaitTime = 10 * time.Second
+)
+
+var (
+	// ErrBatchEmpty - в пакете нет запросов
+	ErrBatchEmpty = errors.New("Batch request is empty")
+	// ErrBatchTooLarge - в пакете больше запросов, чем разрешено настройками
+	ErrBatchTooLarge = errors.New("Too many requests in batch")
+	// ErrBatchInvalid - некорректные настройки пакетных запросов
+	ErrBatchInvalid = errors.New("Invalid batch settings")
+)
+
+// BatchSettings - ограничения пакетных запросов. Лимиты общие для всех пакетов сервиса,
+// обычные запросы /calculate под них не попадают
+type BatchSettings struct {
+	// MaxItems - сколько запросов можно передать в одном пакете
+	MaxItems int `json:"max_items"`
+	// Concurrency - сколько запросов из всех пакетов выполняется одновременно
+	Concurrency int `json:"concurrency"`
+	// ProviderConcurrency - сколько запросов из всех пакетов одновременно уходит к одному провайдеру;
+	// не больше числа простаивающих соединений к хосту, иначе пакет вытеснит из пула обычные запросы
+	ProviderConcurrency int `json:"provider_concurrency"`
+	// WaitTime - общий дедлайн пакета
+	WaitTime duration.Milliseconds `json:"wait_time_ms"`
+}
+
+// Validate - лимиты не могут быть отрицательными; 0 - значение по умолчанию
+func (bs BatchSettings) Validate() error {
+	if bs.MaxItems < 0 || bs.Concurrency < 0 || bs.ProviderConcurrency < 0 || bs.WaitTime < 0 {
+		return errors.Wrap(ErrBatchInvalid, "limits must be >= 0")
+	}
+	return nil
+}
+
+// WithDefaults - незаданные лимиты заменяем значениями по умолчанию; providerConcurrency - лимит запросов
+// к провайдеру по умолчанию, обычно число простаивающих соединений к хосту
+func (bs BatchSettings) WithDefaults(providerConcurrency int) BatchSettings {
+	if bs.MaxItems == 0 {
+		bs.MaxItems = defaultBatchMaxItems
+	}
+	if bs.Concurrency == 0 {
+		bs.Concurrency = defaultBatchConcurrency
+	}
+	if bs.ProviderConcurrency == 0 {
+		bs.ProviderConcurrency = providerConcurrency
+	}
+	if bs.WaitTime == 0 {
+		bs.WaitTime = duration.Milliseconds(defaultBatchWaitTime)
+	}
+	return bs
+}
+
+// BatchRequest - пакет запросов к сервису такси
+type BatchRequest struct {
+	Requests []Request `json:"requests"`
+	// errs - ошибки проверки запросов пакета по их индексу; такие запросы не выполняются
+	errs []error
+}
+
+// BatchItem - результат одного запроса пакета: ответ или ошибка
+type BatchItem struct {
+	Response *Response
+	Err      error
+}
+
+// batchLimiter - семафоры пакетных запросов: общий и по провайдерам
+type batchLimiter struct {
+	items     chan struct{}
+	mu        sync.Mutex
+	providers map[string]chan struct{}
+	perAPI    int
+}
+
+func newBatchLimiter(bs BatchSettings) *batchLimiter {
+	l := &batchLimiter{
+		providers: make(map[string]chan struct{}),
+		perAPI:    bs.ProviderConcurrency,
+	}
+	if bs.Concurrency > 0 {
+		l.items = make(chan struct{}, bs.Concurrency)
+	}
+	return l
+}
+
+// acquire - ждем свободного места в sem; nil sem - без ограничения
+func acquire(ctx context.Context, sem chan struct{}) (func(), error) {
+	if sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *batchLimiter) acquireItem(ctx context.Context) (func(), error) {
+	return acquire(ctx, l.items)
+}
+
+func (l *batchLimiter) acquireProvider(ctx context.Context, apiName string) (func(), error) {
+	if l.perAPI <= 0 {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	sem, ok := l.providers[apiName]
+	if !ok {
+		sem = make(chan struct{}, l.perAPI)
+		l.providers[apiName] = sem
+	}
+	l.mu.Unlock()
+	return acquire(ctx, sem)
+}
+
+// ctxKeyBatch - ключ контекста: запрос выполняется в составе пакета и подчиняется лимитам пакетов
+type ctxKeyBatch struct{}
+
+func isBatch(ctx context.Context) bool {
+	batch, _ := ctx.Value(ctxKeyBatch{}).(bool)
+	return batch
+}
+
+// acquireProvider - место для запроса пакета к провайдеру. Если место не освободилось до дедлайна -
+// ошибка таймаута провайдера; обычные запросы не ограничиваются
+func (s *Service) acquireProvider(ctx context.Context, apiName string) (func(), error) {
+	if !isBatch(ctx) {
+		return func() {}, nil
+	}
+	release, err := s.batchLimit.acquireProvider(ctx, apiName)
+	if err != nil {
+		return nil, &apierror.Error{
+			Provider:  apiName,
+			Stage:     "batch",
+			Kind:      apierror.KindTimeout,
+			Retryable: true,
+			Err:       errors.Wrap(httprequester.ErrContextDeadline, "Waiting for provider slot"),
+		}
+	}
+	return release, nil
+}
+
+// batchEnvelopeSchema - схема BatchRequest без схемы запросов: запросы пакета проверяются по отдельности,
+// и их ошибки возвращаются в результатах запросов
+var batchEnvelopeSchema = func() *openAPISchema {
+	envelope := *openAPISchemas["BatchRequest"]
+	requests := *envelope.Properties["requests"]
+	requests.Items = nil
+	envelope.Properties = map[string]*openAPISchema{"requests": &requests}
+	return &envelope
+}()
+
+// batchCause - исходная ошибка нарушения схемы пакета: пустой или пропущенный requests - ErrBatchEmpty
+func batchCause(field string, err error) error {
+	if field == "requests" && (err == ErrFieldRequired || err == ErrFieldRange) {
+		return ErrBatchEmpty
+	}
+	return err
+}
+
+// ParseBatchRequest - парсим пакет запросов к сервису такси. Ошибка - только если некорректен пакет целиком,
+// ошибки отдельных запросов вернутся в их результатах
+func (s *Service) ParseBatchRequest(ctx context.Context, r *http.Request) (BatchRequest, error) {
+	var batch BatchRequest
+	content, errRead := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if errRead != nil {
+		s.collector.AddServiceError("invalid_request", ModeBatch, 0)
+		return batch, WithErrorClass(errors.Wrap(errRead, "Cannot load batch request body"), ErrClassBadRequest)
+	}
+	var envelope struct {
+		Requests []json.RawMessage `json:"requests"`
+	}
+	if err := s.decodeJSON(content, batchEnvelopeSchema, batchCause, &envelope); err != nil {
+		s.collector.AddServiceError("invalid_request", ModeBatch, 0)
+		return batch, WithErrorClass(errors.Wrap(err, "Cannot parse batch request json"), ErrClassBadRequest)
+	}
+	if len(envelope.Requests) > s.batch.MaxItems {
+		s.collector.AddServiceError("invalid_request", ModeBatch, 0)
+		return batch, WithErrorClass(errors.Wrapf(ErrBatchTooLarge, "%v > %v", len(envelope.Requests), s.batch.MaxItems), ErrClassBadRequest)
+	}
+	reqID := middleware.GetReqID(r.Context())
+	clientHeader := r.Header.Get(ClientIDHeader)
+	batch.Requests = make([]Request, len(envelope.Requests))
+	batch.errs = make([]error, len(envelope.Requests))
+	for i, item := range envelope.Requests {
+		batch.Requests[i].ReqID = fmt.Sprintf("%v-%v", reqID, i+1)
+		batch.errs[i] = s.parseBatchItem(ctx, clientHeader, item, &batch.Requests[i])
+		if batch.errs[i] != nil {
+			s.collector.AddServiceError("invalid_request", batch.Requests[i].Mode(), batch.Requests[i].RegionID)
+		}
+	}
+	return batch, nil
+}
+
+// parseBatchItem - разбираем и проверяем один запрос пакета
+func (s *Service) parseBatchItem(ctx context.Context, clientHeader string, content []byte, taxiReq *Request) error {
+	if err := s.decodeRequest(content, taxiReq); err != nil {
+		return WithErrorClass(errors.Wrap(err, "Cannot parse request json"), ErrClassBadRequest)
+	}
+	return s.checkTaxiRequest(ctx, clientHeader, taxiReq)
+}
+
+// BatchResponse - ответы на запросы пакета в том же порядке. Пакет выполняется с общим дедлайном,
+// каждый запрос - со своим itemWaitTime; priceCoeff - коэффициент цен по коду региона
+func (s *Service) BatchResponse(ctx context.Context, batch BatchRequest, itemWaitTime time.Duration, priceCoeff func(regionID int) float64) []BatchItem {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, s.batch.WaitTime.Duration())
+	defer cancel()
+	ctx = context.WithValue(ctx, ctxKeyBatch{}, true)
+	items := make([]BatchItem, len(batch.Requests))
+	var wg sync.WaitGroup
+	for i := range batch.Requests {
+		if i < len(batch.errs) && batch.errs[i] != nil {
+			items[i].Err = batch.errs[i]
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			items[i] = s.batchItem(ctx, batch.Requests[i], itemWaitTime, priceCoeff)
+		}(i)
+	}
+	wg.Wait()
+	elapsed := float64(time.Since(start).Nanoseconds()) / 1000000
+	s.Logger.TimingLogEntry(ctx, elapsed, fmt.Sprintf("Batch of %v requests", len(batch.Requests)))
+	return items
+}
+
+func (s *Service) batchItem(ctx context.Context, req Request, waitTime time.Duration, priceCoeff func(regionID int) float64) BatchItem {
+	release, errWait := s.batchLimit.acquireItem(ctx)
+	if errWait != nil {
+		return BatchItem{Err: WithErrorClass(errors.Wrap(errWait, "Batch deadline exceeded before request started"), ErrClassUpstreamTimeout)}
+	}
+	defer release()
+	ctx, cancel := context.WithTimeout(ctx, waitTime)
+	defer cancel()
+	ctx = context.WithValue(ctx, log.CtxKeyRegionID, req.RegionID)
+	if err := s.EvaluateTaxiRequest(ctx, &req); err != nil {
+		return BatchItem{Err: err}
+	}
+	response, err := s.Response(ctx, req, priceCoeff(req.RegionID))
+	if err != nil {
+		return BatchItem{Err: err}
+	}
+	return BatchItem{Response: response}
+}
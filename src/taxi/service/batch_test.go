@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/nburunova/taxi-backend-sample/src/product"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowAPIDataGetter - провайдер, который отвечает с задержкой и считает одновременные запросы к себе
+type slowAPIDataGetter struct {
+	name     string
+	delay    time.Duration
+	inFlight *int32
+	maxSeen  *int32
+}
+
+func newSlowAPIDataGetter(name string, delay time.Duration) slowAPIDataGetter {
+	return slowAPIDataGetter{name: name, delay: delay, inFlight: new(int32), maxSeen: new(int32)}
+}
+
+func (m slowAPIDataGetter) GetAPIData(ctx context.Context, httpreq *httprequester.Requester, taxiReq Request) ([]APIData, error) {
+	n := atomic.AddInt32(m.inFlight, 1)
+	defer atomic.AddInt32(m.inFlight, -1)
+	for {
+		seen := atomic.LoadInt32(m.maxSeen)
+		if n <= seen || atomic.CompareAndSwapInt32(m.maxSeen, seen, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(m.delay):
+		return []APIData{{PriceMean: float64(100 * taxiReq.RegionID)}}, nil
+	case <-ctx.Done():
+		return nil, errors.Wrap(httprequester.ErrContextDeadline, "slow")
+	}
+}
+
+func (m slowAPIDataGetter) APIName() string {
+	return m.name
+}
+
+func newTestBatchService(bs BatchSettings, apis ...APIDataGetter) *Service {
+	prods := make([]product.Product, 0, len(apis))
+	for _, api := range apis {
+		prods = append(prods, product.Product{ProviderName: api.APIName()})
+	}
+	return NewBuilder().
+		WithAPIs(apis).
+		WithProductCache(newMockProductCache(nil, prods...)).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		WithBatchSettings(bs).
+		Build()
+}
+
+func noCoeff(regionID int) float64 {
+	return priceOff
+}
+
+func TestBatchSettings(t *testing.T) {
+	var bs BatchSettings
+	err := json.Unmarshal([]byte(`{"max_items": 10, "concurrency": 2, "provider_concurrency": 3, "wait_time_ms": 1500}`), &bs)
+	assert.Nil(t, err)
+	assert.Equal(t, BatchSettings{MaxItems: 10, Concurrency: 2, ProviderConcurrency: 3, WaitTime: duration.Milliseconds(1500 * time.Millisecond)}, bs)
+	assert.Equal(t, bs, bs.WithDefaults(100))
+	assert.Nil(t, bs.Validate())
+
+	assert.Equal(t, BatchSettings{
+		MaxItems:            defaultBatchMaxItems,
+		Concurrency:         defaultBatchConcurrency,
+		ProviderConcurrency: 100,
+		WaitTime:            duration.Milliseconds(defaultBatchWaitTime),
+	}, BatchSettings{}.WithDefaults(100))
+	assert.Equal(t, ErrBatchInvalid, errors.Cause(BatchSettings{Concurrency: -1}.Validate()))
+}
+
+func TestParseBatchRequest(t *testing.T) {
+	service := newTestBatchService(BatchSettings{MaxItems: 2}, newMockAPIDataGetter(nil, "test1"))
+	parse := func(body string) (BatchRequest, error) {
+		r, _ := http.NewRequest("POST", "/taksa/api/1.0/route/calculate/batch", strings.NewReader(body))
+		return service.ParseBatchRequest(testContext, r)
+	}
+	point := `"point1": {"lat": 55.1, "lon": 37.1}, "point2": {"lat": 55.2, "lon": 37.2}`
+	batch, err := parse(fmt.Sprintf(`{"requests": [{"region_id": 32, %v}, {"region_id": 32, "point1": {"lat": 55.1, "lon": 37.1}}]}`, point))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(batch.Requests))
+	assert.Nil(t, batch.errs[0])
+	assert.Equal(t, ErrTaxiReqEmpty, errors.Cause(batch.errs[1]))
+	assert.Equal(t, ErrClassBadRequest, ClassOf(batch.errs[1]))
+
+	tests := []struct {
+		body string
+		err  error
+	}{
+		{`{"requests": []}`, ErrBatchEmpty},
+		{fmt.Sprintf(`{"requests": [{"region_id": 32, %[1]v}, {"region_id": 32, %[1]v}, {"region_id": 32, %[1]v}]}`, point), ErrBatchTooLarge},
+	}
+	for _, test := range tests {
+		_, err = parse(test.body)
+		assert.Equal(t, test.err, errors.Cause(err), test.body)
+		assert.Equal(t, ErrClassBadRequest, ClassOf(err), test.body)
+	}
+	_, err = parse(`[`)
+	assert.Equal(t, ErrClassBadRequest, ClassOf(err))
+}
+
+func TestBatchResponse(t *testing.T) {
+	service := newTestBatchService(BatchSettings{}, newSlowAPIDataGetter("test1", time.Millisecond))
+	batch := BatchRequest{
+		Requests: []Request{testTaxiRequestMoscow, {}, testTaxiRequestMoscow},
+		errs:     []error{nil, WithErrorClass(ErrTaxiReqEmpty, ErrClassBadRequest), nil},
+	}
+	batch.Requests[2].RegionID = 14
+	items := service.BatchResponse(testContext, batch, time.Second, noCoeff)
+	if !assert.Equal(t, 3, len(items)) {
+		return
+	}
+	if assert.Nil(t, items[0].Err) {
+		assert.Equal(t, 3200, items[0].Response.Result.Optimal.Results[0].Price)
+	}
+	assert.Equal(t, ErrTaxiReqEmpty, errors.Cause(items[1].Err))
+	if assert.Nil(t, items[2].Err) {
+		assert.Equal(t, 1400, items[2].Response.Result.Optimal.Results[0].Price, "results keep the order of requests")
+	}
+}
+
+func TestBatchConcurrencyLimits(t *testing.T) {
+	slow := newSlowAPIDataGetter("test1", 10*time.Millisecond)
+	service := newTestBatchService(BatchSettings{Concurrency: 4, ProviderConcurrency: 2}, slow)
+	batch := BatchRequest{Requests: make([]Request, 8)}
+	for i := range batch.Requests {
+		batch.Requests[i] = testTaxiRequestMoscow
+	}
+	for _, item := range service.BatchResponse(testContext, batch, time.Second, noCoeff) {
+		assert.Nil(t, item.Err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(slow.maxSeen), "provider concurrency limit")
+
+	_, err := service.Response(testContext, testTaxiRequestMoscow, priceOff)
+	assert.Nil(t, err, "single requests are not limited")
+}
+
+func TestBatchDeadline(t *testing.T) {
+	slow := newSlowAPIDataGetter("test1", 50*time.Millisecond)
+	service := newTestBatchService(BatchSettings{Concurrency: 1, WaitTime: duration.Milliseconds(20 * time.Millisecond)}, slow)
+	batch := BatchRequest{Requests: []Request{testTaxiRequestMoscow, testTaxiRequestMoscow}}
+	items := service.BatchResponse(testContext, batch, time.Second, noCoeff)
+	assert.Equal(t, ErrClassUpstreamTimeout, ClassOf(items[0].Err), "request is cut by the shared deadline")
+	assert.Equal(t, ErrClassUpstreamTimeout, ClassOf(items[1].Err), "request did not start before the shared deadline")
+}
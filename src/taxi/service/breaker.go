@@ -0,0 +1,211 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+)
+
+// BreakerState - состояние circuit breaker провайдера
+type BreakerState int
+
+const (
+	// BreakerClosed - провайдер опрашивается как обычно
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen - пропускаем один пробный запрос, чтобы понять, ожил ли провайдер
+	BreakerHalfOpen
+	// BreakerOpen - провайдер не опрашивается до истечения OpenTime
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	}
+	return "closed"
+}
+
+// BreakerSettings - настройки circuit breaker провайдеров
+type BreakerSettings struct {
+	// ConsecutiveErrors - после скольких ошибок подряд размыкаем цепь
+	ConsecutiveErrors int `json:"consecutive_errors"`
+	// TimeoutRatio - доля таймаутов в окне, после которой размыкаем цепь
+	TimeoutRatio float64 `json:"timeout_ratio"`
+	// Window - размер окна последних запросов для подсчета доли таймаутов
+	Window int `json:"window"`
+	// OpenTime - сколько провайдер не опрашивается после размыкания
+	OpenTime duration.Milliseconds `json:"open_ms"`
+}
+
+// DefaultBreakerSettings - настройки circuit breaker, если в settings.json их нет
+var DefaultBreakerSettings = BreakerSettings{
+	ConsecutiveErrors: 5,
+	TimeoutRatio:      0.5,
+	Window:            20,
+	OpenTime:          duration.Milliseconds(30 * time.Second),
+}
+
+// IsEmpty - проверяем, заданы ли настройки
+func (bs BreakerSettings) IsEmpty() bool {
+	return bs.ConsecutiveErrors == 0 && bs.TimeoutRatio == 0 && bs.Window == 0 && bs.OpenTime == 0
+}
+
+// breakerResult - результат запроса к провайдеру с точки зрения circuit breaker
+type breakerResult int
+
+const (
+	breakerSuccess breakerResult = iota
+	breakerFailure
+	breakerTimeout
+)
+
+// breakerResultByErr - классифицируем ошибку провайдера. Невалидные цены и время - это ответ провайдера,
+// а не его недоступность, поэтому цепь из-за них не размыкаем
+func breakerResultByErr(err error) breakerResult {
+	if hasProviderErrorKind(err, apierror.KindTimeout) {
+		return breakerTimeout
+	}
+	for _, kind := range providerErrorKinds(err) {
+		switch kind {
+		case apierror.KindTransport, apierror.KindRead, apierror.KindStatus:
+			return breakerFailure
+		}
+	}
+	return breakerSuccess
+}
+
+// circuitBreaker - circuit breaker одного провайдера
+type circuitBreaker struct {
+	settings          BreakerSettings
+	mu                sync.Mutex
+	state             BreakerState
+	consecutiveErrors int
+	window            []bool
+	windowPos         int
+	windowFull        bool
+	openedAt          time.Time
+	probeInFlight     bool
+	now               func() time.Time
+	onStateChange     func(BreakerState)
+}
+
+func newCircuitBreaker(settings BreakerSettings, onStateChange func(BreakerState)) *circuitBreaker {
+	window := settings.Window
+	if window <= 0 {
+		window = 1
+	}
+	return &circuitBreaker{
+		settings:      settings,
+		window:        make([]bool, window),
+		now:           time.Now,
+		onStateChange: onStateChange,
+	}
+}
+
+// State - текущее состояние; открытая цепь, у которой истек OpenTime, считается полуоткрытой
+func (cb *circuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.refresh()
+	return cb.state
+}
+
+// Allow - можно ли сейчас запрашивать провайдера
+func (cb *circuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.refresh()
+	switch cb.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if cb.probeInFlight {
+			return false
+		}
+		cb.probeInFlight = true
+	}
+	return true
+}
+
+// Done - сообщаем результат запроса, разрешенного Allow
+func (cb *circuitBreaker) Done(result breakerResult) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == BreakerHalfOpen {
+		cb.probeInFlight = false
+		if result == breakerSuccess {
+			cb.reset()
+			cb.setState(BreakerClosed)
+			return
+		}
+		cb.open()
+		return
+	}
+	if cb.state == BreakerOpen {
+		return
+	}
+
+	cb.window[cb.windowPos] = result == breakerTimeout
+	cb.windowPos = (cb.windowPos + 1) % len(cb.window)
+	if cb.windowPos == 0 {
+		cb.windowFull = true
+	}
+	if result == breakerSuccess {
+		cb.consecutiveErrors = 0
+		return
+	}
+	cb.consecutiveErrors++
+	if cb.settings.ConsecutiveErrors > 0 && cb.consecutiveErrors >= cb.settings.ConsecutiveErrors {
+		cb.open()
+		return
+	}
+	if cb.settings.TimeoutRatio > 0 && cb.windowFull && cb.timeoutRatio() >= cb.settings.TimeoutRatio {
+		cb.open()
+	}
+}
+
+func (cb *circuitBreaker) timeoutRatio() float64 {
+	var timeouts int
+	for _, isTimeout := range cb.window {
+		if isTimeout {
+			timeouts++
+		}
+	}
+	return float64(timeouts) / float64(len(cb.window))
+}
+
+func (cb *circuitBreaker) refresh() {
+	if cb.state == BreakerOpen && cb.now().Sub(cb.openedAt) >= cb.settings.OpenTime.Duration() {
+		cb.setState(BreakerHalfOpen)
+	}
+}
+
+func (cb *circuitBreaker) open() {
+	cb.reset()
+	cb.openedAt = cb.now()
+	cb.setState(BreakerOpen)
+}
+
+func (cb *circuitBreaker) reset() {
+	cb.consecutiveErrors = 0
+	cb.windowPos = 0
+	cb.windowFull = false
+	for i := range cb.window {
+		cb.window[i] = false
+	}
+}
+
+func (cb *circuitBreaker) setState(state BreakerState) {
+	if cb.state == state {
+		return
+	}
+	cb.state = state
+	if cb.onStateChange != nil {
+		cb.onStateChange(state)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/errorswrapper"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/nburunova/taxi-backend-sample/src/product"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBreaker(settings BreakerSettings) (*circuitBreaker, *testClock) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	cb := newCircuitBreaker(settings, nil)
+	cb.now = clock.Now
+	return cb, clock
+}
+
+var testBreakerSettings = BreakerSettings{
+	ConsecutiveErrors: 3,
+	TimeoutRatio:      0.5,
+	Window:            4,
+	OpenTime:          duration.Milliseconds(10 * time.Second),
+}
+
+func TestBreakerResultByErr(t *testing.T) {
+	assert.Equal(t, breakerSuccess, breakerResultByErr(nil))
+	assert.Equal(t, breakerTimeout, breakerResultByErr(errors.Wrap(errors.Wrap(httprequester.ErrContextDeadline, "url"), "Gett")))
+	assert.Equal(t, breakerFailure, breakerResultByErr(errors.Wrap(errors.Wrap(httprequester.ErrStatusNotOK, "500"), "Uber")))
+	assert.Equal(t, breakerSuccess, breakerResultByErr(errors.Wrap(ErrInvalidPrice, "Price <= 0")))
+
+	transport := &apierror.Error{Provider: "gett", Kind: apierror.KindTransport, Retryable: true, Err: errors.Wrap(httprequester.ErrDoRequest, "dial")}
+	assert.Equal(t, breakerFailure, breakerResultByErr(errors.Wrap(transport, "Gett")))
+	assert.Equal(t, breakerSuccess, breakerResultByErr(errors.Wrap(InvalidPrice("gett", "Price <= 0"), "Gett")))
+	assert.Equal(t, breakerFailure, breakerResultByErr(errorswrapper.Append(InvalidPrice("gett", "Price <= 0"), errors.Wrap(transport, "eta"))), "any failed request of the provider counts")
+}
+
+func TestBreakerConsecutiveErrors(t *testing.T) {
+	cb, clock := newTestBreaker(testBreakerSettings)
+	for i := 0; i < 2; i++ {
+		assert.True(t, cb.Allow())
+		cb.Done(breakerFailure)
+	}
+	assert.Equal(t, BreakerClosed, cb.State())
+	assert.True(t, cb.Allow())
+	cb.Done(breakerSuccess)
+	for i := 0; i < 3; i++ {
+		assert.True(t, cb.Allow())
+		cb.Done(breakerFailure)
+	}
+	assert.Equal(t, BreakerOpen, cb.State())
+	assert.False(t, cb.Allow())
+
+	clock.now = clock.now.Add(testBreakerSettings.OpenTime.Duration())
+	assert.Equal(t, BreakerHalfOpen, cb.State())
+	assert.True(t, cb.Allow())
+	assert.False(t, cb.Allow(), "only one probe in half-open state")
+	cb.Done(breakerFailure)
+	assert.Equal(t, BreakerOpen, cb.State())
+
+	clock.now = clock.now.Add(testBreakerSettings.OpenTime.Duration())
+	assert.True(t, cb.Allow())
+	cb.Done(breakerSuccess)
+	assert.Equal(t, BreakerClosed, cb.State())
+	assert.True(t, cb.Allow())
+}
+
+func TestBreakerTimeoutRatio(t *testing.T) {
+	cb, _ := newTestBreaker(testBreakerSettings)
+	for _, res := range []breakerResult{breakerTimeout, breakerSuccess, breakerTimeout} {
+		assert.True(t, cb.Allow())
+		cb.Done(res)
+	}
+	assert.Equal(t, BreakerClosed, cb.State(), "window is not full yet")
+	assert.True(t, cb.Allow())
+	cb.Done(breakerSuccess)
+	assert.Equal(t, BreakerClosed, cb.State(), "success does not open breaker")
+	assert.True(t, cb.Allow())
+	cb.Done(breakerTimeout)
+	assert.Equal(t, BreakerOpen, cb.State())
+}
+
+type countingAPIDataGetter struct {
+	mockAPIDataGetter
+	calls *int
+}
+
+func (m countingAPIDataGetter) GetAPIData(ctx context.Context, httpreq *httprequester.Requester, taxiReq Request) ([]APIData, error) {
+	*m.calls++
+	return m.apiDatas, m.err
+}
+
+func TestServiceSkipsOpenProvider(t *testing.T) {
+	var calls int
+	failing := countingAPIDataGetter{
+		mockAPIDataGetter: mockAPIDataGetter{
+			err:  errors.Wrap(httprequester.ErrContextDeadline, "failing"),
+			name: "failing",
+		},
+		calls: &calls,
+	}
+	prodFailing := product.Product{ProviderName: "failing", Name: "failing"}
+	prodOK := product.Product{ProviderName: "test1", Name: "test1"}
+	service := NewBuilder().
+		WithAPIs([]APIDataGetter{failing, newMockAPIDataGetter(nil, "test1", APIData{PriceMean: 100})}).
+		WithProductCache(newMockProductCache(nil, prodFailing, prodOK)).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		WithBreakerSettings(BreakerSettings{ConsecutiveErrors: 2, Window: 10, OpenTime: duration.Milliseconds(time.Hour)}).
+		Build()
+	for i := 0; i < 4; i++ {
+		resp, err := service.Response(testContext, testTaxiRequestMoscow, priceOff)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(resp.Result.Optimal.Results))
+	}
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, map[string]string{"failing": "open", "test1": "closed"}, service.BreakerStates())
+}
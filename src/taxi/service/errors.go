@@ -0,0 +1,95 @@
+package service
+
+import "context"
+
+// ErrorClass - класс ошибки сервиса, по нему хендлер выбирает HTTP код и код ошибки приложения
+type ErrorClass int
+
+const (
+	// ErrClassInternal - непредвиденная ошибка сервиса; класс всех ошибок, которым класс не назначен
+	ErrClassInternal ErrorClass = iota
+	// ErrClassBadRequest - некорректный запрос клиента
+	ErrClassBadRequest
+	// ErrClassRegionNotServed - в регионе нет продуктов такси или ни один из них не подключен
+	ErrClassRegionNotServed
+	// ErrClassNoProviders - провайдеры региона опрошены, но ни один не вернул результатов
+	ErrClassNoProviders
+	// ErrClassUpstreamTimeout - провайдеры не успели ответить
+	ErrClassUpstreamTimeout
+	// ErrClassGeocodingFailed - не удалось обогатить запрос данными WebAPI. Класс назначает тот, кто не может
+	// продолжить без адреса; EvaluateTaxiRequest вместо этого оставляет координаты клиента (см. degradePoint)
+	ErrClassGeocodingFailed
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrClassBadRequest:
+		return "bad_request"
+	case ErrClassRegionNotServed:
+		return "region_not_served"
+	case ErrClassNoProviders:
+		return "no_providers"
+	case ErrClassUpstreamTimeout:
+		return "upstream_timeout"
+	case ErrClassGeocodingFailed:
+		return "geocoding_failed"
+	}
+	return "internal"
+}
+
+// classError - ошибка с классом. errors.Cause проходит сквозь нее, поэтому проверки исходных ошибок не ломаются
+type classError struct {
+	class ErrorClass
+	err   error
+}
+
+func (e *classError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classError) Cause() error {
+	return e.err
+}
+
+// WithErrorClass - назначаем ошибке класс
+func WithErrorClass(err error, class ErrorClass) error {
+	if err == nil {
+		return nil
+	}
+	return &classError{class: class, err: err}
+}
+
+type causer interface {
+	Cause() error
+}
+
+// ClassOf - класс ошибки: ближайший назначенный по цепочке errors.Wrap, иначе ErrClassInternal
+func ClassOf(err error) ErrorClass {
+	for err != nil {
+		if ce, ok := err.(*classError); ok {
+			return ce.class
+		}
+		cause, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = cause.Cause()
+	}
+	return ErrClassInternal
+}
+
+// noRecordsClass - класс ошибки, когда провайдеры не вернули ни одного результата
+func noRecordsClass(ctx context.Context, statuses []ProviderStatus) ErrorClass {
+	if len(statuses) == 0 {
+		return ErrClassRegionNotServed
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return ErrClassUpstreamTimeout
+	}
+	for _, status := range statuses {
+		if status.Status != ProviderTimeout {
+			return ErrClassNoProviders
+		}
+	}
+	return ErrClassUpstreamTimeout
+}
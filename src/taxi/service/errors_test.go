@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassOf(t *testing.T) {
+	errBase := errors.New("base")
+	assert.Equal(t, ErrClassInternal, ClassOf(nil))
+	assert.Equal(t, ErrClassInternal, ClassOf(errBase))
+	assert.Nil(t, WithErrorClass(nil, ErrClassBadRequest))
+
+	err := errors.Wrap(WithErrorClass(errors.Wrap(errBase, "inner"), ErrClassUpstreamTimeout), "outer")
+	assert.Equal(t, ErrClassUpstreamTimeout, ClassOf(err))
+	assert.Equal(t, errBase, errors.Cause(err))
+	assert.Equal(t, "outer: inner: base", err.Error())
+	assert.Equal(t, "upstream_timeout", ClassOf(err).String())
+}
+
+func TestNoRecordsClass(t *testing.T) {
+	assert.Equal(t, ErrClassRegionNotServed, noRecordsClass(testContext, nil))
+	assert.Equal(t, ErrClassNoProviders, noRecordsClass(testContext, []ProviderStatus{
+		{Name: "test1", Status: ProviderTimeout},
+		{Name: "test2", Status: ProviderHTTPError},
+	}))
+	assert.Equal(t, ErrClassUpstreamTimeout, noRecordsClass(testContext, []ProviderStatus{
+		{Name: "test1", Status: ProviderTimeout},
+	}))
+
+	ctx, cancel := context.WithTimeout(testContext, time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, ErrClassUpstreamTimeout, noRecordsClass(ctx, []ProviderStatus{
+		{Name: "test1", Status: ProviderHTTPError},
+	}))
+}
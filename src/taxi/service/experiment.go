@@ -0,0 +1,198 @@
+package service
+
+import (
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+)
+
+// ClientIDHeader - заголовок с идентификатором клиента или устройства, по нему запрос попадает в вариант эксперимента
+const ClientIDHeader = "X-Client-Id"
+
+// allocationBuckets - на сколько корзин делим трафик эксперимента; доли вариантов задаются в процентах
+const allocationBuckets = 100
+
+var (
+	// ErrExperimentVariant - у варианта эксперимента нет имени или имя повторяется
+	ErrExperimentVariant = errors.New("Invalid experiment variant")
+	// ErrExperimentAllocation - доли вариантов эксперимента заданы неверно
+	ErrExperimentAllocation = errors.New("Invalid experiment allocation")
+)
+
+// VariantSettings - параметры варианта эксперимента; незаданные параметры берутся из общих настроек
+type VariantSettings struct {
+	Name string `json:"name"`
+	// PriceCoeff - коэффициент цены вместо region_price_coeff; 0 - не переопределять
+	PriceCoeff float64 `json:"price_coeff"`
+	// Ranking - стратегия выбора оптимального варианта вместо стратегии региона
+	Ranking *RankerSettings `json:"ranking"`
+	// Providers - опрашиваются только эти провайдеры; пусто - все провайдеры региона
+	Providers []string `json:"providers"`
+}
+
+// ExperimentSettings - эксперимент: варианты и доли трафика вариантов в процентах, по умолчанию и по коду региона.
+// Оставшийся трафик в эксперимент не попадает. Пустое имя - эксперимент выключен
+type ExperimentSettings struct {
+	Name       string                 `json:"name"`
+	Variants   []VariantSettings      `json:"variants"`
+	Allocation map[string]int         `json:"allocation"`
+	Regions    map[int]map[string]int `json:"regions"`
+}
+
+// IsEmpty - эксперимент выключен
+func (es ExperimentSettings) IsEmpty() bool {
+	return es.Name == "" || len(es.Variants) == 0
+}
+
+// Validate - проверяем варианты и доли трафика
+func (es ExperimentSettings) Validate() error {
+	if es.IsEmpty() {
+		return nil
+	}
+	names := make(map[string]bool, len(es.Variants))
+	for _, vs := range es.Variants {
+		if vs.Name == "" || names[vs.Name] {
+			return errors.Wrapf(ErrExperimentVariant, "variant %q", vs.Name)
+		}
+		names[vs.Name] = true
+		if vs.PriceCoeff < 0 {
+			return errors.Wrapf(ErrExperimentVariant, "variant %v: price_coeff %v", vs.Name, vs.PriceCoeff)
+		}
+		if vs.Ranking != nil {
+			if _, err := newRanker(*vs.Ranking); err != nil {
+				return errors.Wrapf(err, "variant %v", vs.Name)
+			}
+		}
+	}
+	if err := validateAllocation(es.Allocation, names); err != nil {
+		return errors.Wrap(err, "default")
+	}
+	for regionID, allocation := range es.Regions {
+		if err := validateAllocation(allocation, names); err != nil {
+			return errors.Wrapf(err, "region %v", regionID)
+		}
+	}
+	return nil
+}
+
+func validateAllocation(allocation map[string]int, names map[string]bool) error {
+	var total int
+	for name, share := range allocation {
+		if !names[name] {
+			return errors.Wrapf(ErrExperimentAllocation, "unknown variant %q", name)
+		}
+		if share < 0 {
+			return errors.Wrapf(ErrExperimentAllocation, "variant %v: share %v", name, share)
+		}
+		total += share
+	}
+	if total > allocationBuckets {
+		return errors.Wrapf(ErrExperimentAllocation, "total share %v > %v", total, allocationBuckets)
+	}
+	return nil
+}
+
+// Variant - вариант эксперимента, в который попал запрос
+type Variant struct {
+	Experiment string
+	Name       string
+	priceCoeff float64
+	ranker     Ranker
+	providers  map[string]bool
+}
+
+// PriceCoeffOr - коэффициент цены варианта, если он задан, иначе coeff
+func (v *Variant) PriceCoeffOr(coeff float64) float64 {
+	if v == nil || v.priceCoeff <= 0 {
+		return coeff
+	}
+	return v.priceCoeff
+}
+
+// allowsProvider - опрашивать ли провайдера в этом варианте
+func (v *Variant) allowsProvider(apiName string) bool {
+	if v == nil || len(v.providers) == 0 {
+		return true
+	}
+	return v.providers[apiName]
+}
+
+func newVariant(experiment string, vs VariantSettings) *Variant {
+	v := &Variant{
+		Experiment: experiment,
+		Name:       vs.Name,
+		priceCoeff: vs.PriceCoeff,
+	}
+	if vs.Ranking != nil {
+		if ranker, err := newRanker(*vs.Ranking); err == nil {
+			v.ranker = ranker
+		}
+	}
+	if len(vs.Providers) != 0 {
+		v.providers = make(map[string]bool, len(vs.Providers))
+		for _, name := range vs.Providers {
+			v.providers[name] = true
+		}
+	}
+	return v
+}
+
+// experiment - распределение запросов по вариантам эксперимента
+type experiment struct {
+	name       string
+	variants   []*Variant
+	allocation map[string]int
+	regions    map[int]map[string]int
+}
+
+// newExperiment - nil, если эксперимент выключен
+func newExperiment(es ExperimentSettings) *experiment {
+	if es.IsEmpty() {
+		return nil
+	}
+	exp := &experiment{
+		name:       es.Name,
+		variants:   make([]*Variant, 0, len(es.Variants)),
+		allocation: es.Allocation,
+		regions:    es.Regions,
+	}
+	for _, vs := range es.Variants {
+		exp.variants = append(exp.variants, newVariant(es.Name, vs))
+	}
+	return exp
+}
+
+// assign - вариант для клиента в регионе или nil, если запрос в эксперимент не попал.
+// Клиент попадает в корзину по хэшу имени эксперимента и своего идентификатора, поэтому вариант для него постоянен,
+// а разные эксперименты делят трафик независимо. Корзины раздаются вариантам по порядку их объявления
+func (e *experiment) assign(regionID int, clientID string) *Variant {
+	if e == nil || clientID == "" {
+		return nil
+	}
+	allocation, ok := e.regions[regionID]
+	if !ok {
+		allocation = e.allocation
+	}
+	h := fnv.New64a()
+	h.Write([]byte(e.name + "/" + clientID))
+	bucket := int(h.Sum64() % allocationBuckets)
+	var bound int
+	for _, v := range e.variants {
+		bound += allocation[v.Name]
+		if bucket < bound {
+			return v
+		}
+	}
+	return nil
+}
+
+// clientID - идентификатор для распределения по вариантам: заголовок клиента, затем пользователь, затем запрос
+func clientID(header string, req Request) string {
+	if header != "" {
+		return header
+	}
+	if req.UserID != "" {
+		return req.UserID
+	}
+	return req.ReqID
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nburunova/taxi-backend-sample/src/product"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var testExperiment = ExperimentSettings{
+	Name: "test_exp",
+	Variants: []VariantSettings{
+		{Name: "control"},
+		{Name: "cheap", PriceCoeff: 1.5, Ranking: &RankerSettings{Strategy: RankPrice}, Providers: []string{"test2"}},
+	},
+	Allocation: map[string]int{"control": 50, "cheap": 50},
+	Regions: map[int]map[string]int{
+		14: {"cheap": 100},
+		99: {},
+	},
+}
+
+func TestExperimentSettings(t *testing.T) {
+	var es ExperimentSettings
+	err := json.Unmarshal([]byte(`{"name": "exp", "variants": [{"name": "a"}, {"name": "b", "price_coeff": 1.2, "ranking": {"strategy": "weighted"}, "providers": ["uber"]}], "allocation": {"a": 10, "b": 10}, "regions": {"32": {"b": 100}}}`), &es)
+	assert.Nil(t, err)
+	assert.Nil(t, es.Validate())
+	assert.Equal(t, map[string]int{"b": 100}, es.Regions[32])
+	assert.Nil(t, ExperimentSettings{}.Validate(), "disabled experiment")
+
+	tests := []struct {
+		name string
+		es   ExperimentSettings
+		err  error
+	}{
+		{"no variant name", ExperimentSettings{Name: "exp", Variants: []VariantSettings{{}}}, ErrExperimentVariant},
+		{"same names", ExperimentSettings{Name: "exp", Variants: []VariantSettings{{Name: "a"}, {Name: "a"}}}, ErrExperimentVariant},
+		{"negative coeff", ExperimentSettings{Name: "exp", Variants: []VariantSettings{{Name: "a", PriceCoeff: -1}}}, ErrExperimentVariant},
+		{"unknown ranker", ExperimentSettings{Name: "exp", Variants: []VariantSettings{{Name: "a", Ranking: &RankerSettings{Strategy: "best"}}}}, ErrUnknownRanker},
+		{"unknown variant", ExperimentSettings{Name: "exp", Variants: []VariantSettings{{Name: "a"}}, Allocation: map[string]int{"b": 10}}, ErrExperimentAllocation},
+		{"over 100", ExperimentSettings{Name: "exp", Variants: []VariantSettings{{Name: "a"}, {Name: "b"}}, Regions: map[int]map[string]int{1: {"a": 60, "b": 50}}}, ErrExperimentAllocation},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.err, errors.Cause(test.es.Validate()), test.name)
+	}
+}
+
+func TestExperimentAssign(t *testing.T) {
+	exp := newExperiment(testExperiment)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		clientID := fmt.Sprintf("device-%d", i)
+		v := exp.assign(32, clientID)
+		if !assert.NotNil(t, v) {
+			return
+		}
+		assert.Equal(t, v, exp.assign(32, clientID), "same client gets the same variant")
+		counts[v.Name]++
+	}
+	assert.InDelta(t, 500, counts["control"], 100)
+	assert.InDelta(t, 500, counts["cheap"], 100)
+
+	assert.Equal(t, "cheap", exp.assign(14, "device-1").Name, "region allocation")
+	assert.Nil(t, exp.assign(99, "device-1"), "region is out of experiment")
+	assert.Nil(t, exp.assign(32, ""))
+	assert.Nil(t, newExperiment(ExperimentSettings{}).assign(32, "device-1"))
+}
+
+func TestVariantParams(t *testing.T) {
+	var none *Variant
+	assert.Equal(t, 1.3, none.PriceCoeffOr(1.3))
+	assert.True(t, none.allowsProvider("test1"))
+
+	exp := newExperiment(testExperiment)
+	control, cheap := exp.variants[0], exp.variants[1]
+	assert.Equal(t, 1.3, control.PriceCoeffOr(1.3))
+	assert.True(t, control.allowsProvider("test1"))
+	assert.Equal(t, 1.5, cheap.PriceCoeffOr(1.3))
+	assert.False(t, cheap.allowsProvider("test1"))
+	assert.True(t, cheap.allowsProvider("test2"))
+	assert.Equal(t, priceRanker{}, cheap.ranker)
+}
+
+func TestParseReqExperiment(t *testing.T) {
+	service := getTestService()
+	service.experiment = newExperiment(testExperiment)
+	body := `{"region_id": 14, "point1": {"lat": 55.1, "lon": 37.1}, "point2": {"lat": 55.2, "lon": 37.2}}`
+
+	r, _ := http.NewRequest("POST", "/taksa/api/1.0/route/calculate", strings.NewReader(body))
+	r.Header.Set(ClientIDHeader, "device-1")
+	req, err := service.ParseTaxiRequest(testContext, r)
+	assert.Nil(t, err)
+	if assert.NotNil(t, req.Variant) {
+		assert.Equal(t, "test_exp", req.Variant.Experiment)
+		assert.Equal(t, "cheap", req.Variant.Name)
+	}
+
+	r, _ = http.NewRequest("POST", "/taksa/api/1.0/route/calculate", strings.NewReader(strings.Replace(body, "14", "99", 1)))
+	r.Header.Set(ClientIDHeader, "device-1")
+	req, err = service.ParseTaxiRequest(testContext, r)
+	assert.Nil(t, err)
+	assert.Nil(t, req.Variant)
+}
+
+func TestResponseExperiment(t *testing.T) {
+	service := NewBuilder().
+		WithAPIs([]APIDataGetter{
+			newMockAPIDataGetter(nil, "test1", APIData{PriceMean: 100}),
+			newMockAPIDataGetter(nil, "test2", APIData{PriceMean: 200}),
+		}).
+		WithProductCache(newMockProductCache(nil, product.Product{ProviderName: "test1"}, product.Product{ProviderName: "test2"})).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		WithExperiment(testExperiment).
+		Build()
+
+	req := fullMode(testTaxiRequestMoscow)
+	req.WithProviders = true
+	resp, err := service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, "", resp.Meta.Variant)
+	assert.Equal(t, 2, len(resp.Providers))
+
+	req.Variant = service.experiment.variants[1]
+	resp, err = service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, "test_exp", resp.Meta.Experiment)
+	assert.Equal(t, "cheap", resp.Meta.Variant)
+	if assert.Equal(t, 1, len(resp.Providers), "only variant providers are requested") {
+		assert.Equal(t, "test2", resp.Providers[0].Name)
+	}
+	assert.Equal(t, 200, resp.Result.Optimal.Results[0].Price)
+}
@@ -0,0 +1,34 @@
+//go:build ignore
+// +build ignore
+
+// gen_openapi - переносит openapi.json в константу OpenAPISpec (openapi_spec.go): make openapi
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+)
+
+const template = `// Code generated by gen_openapi.go from openapi.json; DO NOT EDIT.
+
+package service
+
+// OpenAPISpec - контракт REST API в OpenAPI 3
+const OpenAPISpec = %v%v%v
+`
+
+func main() {
+	spec, err := ioutil.ReadFile("openapi.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if bytes.ContainsRune(spec, '`') {
+		log.Fatal("openapi.json: backquotes cannot be placed into a Go raw string")
+	}
+	code := fmt.Sprintf(template, "`", string(spec), "`")
+	if err := ioutil.WriteFile("openapi_spec.go", []byte(code), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
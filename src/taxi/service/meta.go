@@ -1,20 +1,53 @@
 package service
 
-type meta struct {
-	Distance *int `json:"distance"`
-	Time     *int `json:"time"`
+import "fmt"
+
+const (
+	// WarningGeocodingPoint1 - WebAPI не вернул адрес точки отправления, используем координаты клиента
+	WarningGeocodingPoint1 = "geocoding_point1"
+	// WarningGeocodingPoint2 - WebAPI не вернул адрес точки назначения, используем координаты клиента
+	WarningGeocodingPoint2 = "geocoding_point2"
+	// WarningRouting - не смогли получить расстояние и время проезда
+	WarningRouting = "routing"
+)
+
+// WarningGeocodingWaypoint - WebAPI не вернул адрес остановки с индексом i, используем координаты клиента.
+// Остановки нумеруются с единицы: geocoding_waypoint1, geocoding_waypoint2, ...
+func WarningGeocodingWaypoint(i int) string {
+	return fmt.Sprintf("geocoding_waypoint%d", i+1)
+}
+
+// Meta - расстояние и время маршрута и предупреждения ответа
+type Meta struct {
+	Distance *int `json:"distance,omitempty"`
+	Time     *int `json:"time,omitempty"`
+	// Source - откуда расстояние и время: routed, cached или estimated
+	Source string `json:"source,omitempty"`
+	// Warnings - какие шаги обогащения запроса пропущены
+	Warnings []string `json:"warnings,omitempty"`
+	// Unsupported - провайдеры региона, которые не умеют считать такой запрос и не опрашивались:
+	// статус waypoints_unsupported или preorder_unsupported. Есть в ответе и без with_providers
+	Unsupported []ProviderStatus `json:"unsupported,omitempty"`
+	// Experiment, Variant - эксперимент и вариант, в который попал запрос
+	Experiment string `json:"experiment,omitempty"`
+	Variant    string `json:"variant,omitempty"`
 }
 
-func (m meta) isEmpty() bool {
-	if (m.Distance == nil && m.Time == nil) || (*m.Distance == 0 && *m.Time == 0) {
-		return true
+func (m Meta) hasRoute() bool {
+	if m.Distance == nil || m.Time == nil || (*m.Distance == 0 && *m.Time == 0) {
+		return false
 	}
-	return false
+	return true
+}
+
+func (m Meta) isEmpty() bool {
+	return !m.hasRoute() && len(m.Warnings) == 0 && len(m.Unsupported) == 0 && m.Variant == ""
 }
 
-func newMeta(distance int, time int) meta {
-	return meta{
+func newMeta(distance int, time int, source string) Meta {
+	return Meta{
 		Distance: &distance,
 		Time:     &time,
+		Source:   source,
 	}
 }
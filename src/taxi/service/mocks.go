@@ -84,6 +84,24 @@ func (m mockAPIDataGetter) APIName() string {
 	return m.name
 }
 
+// mockWaypointsAPIDataGetter - провайдер, который умеет считать маршрут с остановками
+type mockWaypointsAPIDataGetter struct {
+	APIDataGetter
+}
+
+func (m mockWaypointsAPIDataGetter) SupportsWaypoints() bool {
+	return true
+}
+
+// mockPreOrderAPIDataGetter - провайдер, который умеет считать предварительный заказ
+type mockPreOrderAPIDataGetter struct {
+	APIDataGetter
+}
+
+func (m mockPreOrderAPIDataGetter) SupportsPreOrder() bool {
+	return true
+}
+
 type mockDistanceTimeService struct {
 	distance int
 	time     int
@@ -166,6 +184,46 @@ var testTaxiRequestMoscow = Request{
 	OnlyAPI: true,
 }
 
+var testWaypoint = Point{
+	Lon:     37.615,
+	LonStr:  "37.615",
+	Lat:     55.755,
+	LatStr:  "55.755",
+	Address: "Тестовая остановка",
+}
+
+var testTaxiRequestWaypoints = Request{
+	RegionID:  32,
+	Point1:    testTaxiRequestMoscow.Point1,
+	Point2:    testTaxiRequestMoscow.Point2,
+	Waypoints: []Point{testWaypoint},
+	OnlyAPI:   true,
+}
+
+var testPickupTime = time.Date(2026, 10, 17, 9, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+var testTaxiRequestScheduled = Request{
+	RegionID:   32,
+	Point1:     testTaxiRequestMoscow.Point1,
+	Point2:     testTaxiRequestMoscow.Point2,
+	OnlyAPI:    true,
+	PickupTime: &testPickupTime,
+}
+
+// testTaxiRequestOnlyAPI - запрос в режиме only_api, каким его присылает клиент: координаты без адресов
+var testTaxiRequestOnlyAPI = Request{
+	RegionID: 32,
+	Point1:   Point{Lon: testTaxiRequestMoscow.Point1.Lon, Lat: testTaxiRequestMoscow.Point1.Lat},
+	Point2:   Point{Lon: testTaxiRequestMoscow.Point2.Lon, Lat: testTaxiRequestMoscow.Point2.Lat},
+	OnlyAPI:  true,
+}
+
+// fullMode - копия запроса в полном режиме, с адресами WebAPI и маршрутом Моисея
+func fullMode(req Request) Request {
+	req.OnlyAPI = false
+	return req
+}
+
 func strPointer(value string) *string {
 	s := value
 	return &s
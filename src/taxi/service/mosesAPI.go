@@ -21,6 +21,9 @@ var (
 	mosesURL              = "http://routing.2gis.com/carrouting/4.0.0/"
 )
 
+// mosesStatisticType - маршрут по статистике пробок на время из utc, для предварительных заказов
+var mosesStatisticType = "statistic"
+
 type mosesPoint struct {
 	Type string  `json:"type"`
 	X    float64 `json:"x"`
@@ -31,6 +34,7 @@ type mosesReqData struct {
 	Output string       `json:"output"`
 	Type   string       `json:"type"`
 	Points []mosesPoint `json:"points"`
+	UTC    int64        `json:"utc,omitempty"`
 }
 
 type mosesRes struct {
@@ -68,27 +72,30 @@ func NewMosesService(regionsInfo RegionsInfo) (*MosesService, error) {
 	}, nil
 }
 
-// DistanceTime - возвращает дистанцию и время проезда от точки А в Б
+// DistanceTime - возвращает дистанцию и время проезда от точки А в Б через все промежуточные остановки.
+// Для предварительного заказа время считается по статистике пробок на время подачи
 func (m *MosesService) DistanceTime(ctx context.Context, httpreq *httprequester.Requester, taxiReq Request) (int, int, error) {
 	regionName, errRegionName := m.regionsInfo.GetRegionNameByID(taxiReq.RegionID)
 	if errRegionName != nil {
 		return 0, 0, errors.Wrap(errRegionName, "Moses: Cannot get region name")
 	}
+	route := taxiReq.Route()
+	points := make([]mosesPoint, 0, len(route))
+	for _, p := range route {
+		points = append(points, mosesPoint{
+			Type: mosesPointType,
+			X:    p.Lon,
+			Y:    p.Lat,
+		})
+	}
 	data := mosesReqData{
 		Output: mosesOutput,
 		Type:   mosesReqType,
-		Points: []mosesPoint{
-			{
-				Type: mosesPointType,
-				X:    taxiReq.Point1.Lon,
-				Y:    taxiReq.Point1.Lat,
-			},
-			{
-				Type: mosesPointType,
-				X:    taxiReq.Point2.Lon,
-				Y:    taxiReq.Point2.Lat,
-			},
-		},
+		Points: points,
+	}
+	if taxiReq.IsScheduled() {
+		data.Type = mosesStatisticType
+		data.UTC = taxiReq.PickupTime.Unix()
 	}
 	bData, errData := json.Marshal(data)
 	if errData != nil {
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"encoding/json"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -107,3 +109,50 @@ func TestMosesErr(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, gock.IsDone(), true)
 }
+
+func TestMosesWaypoints(t *testing.T) {
+	defer gock.Off()
+	var data mosesReqData
+	bodyMatcher := gock.NewBasicMatcher()
+	bodyMatcher.Add(func(req *http.Request, ereq *gock.Request) (bool, error) {
+		return true, json.NewDecoder(req.Body).Decode(&data)
+	})
+	mosesService, _ := NewMosesService(new(mockRegionsInfo))
+	gock.New(mosesService.mosesURL.Host).
+		Post(mosesService.mosesURL.Path).
+		SetMatcher(bodyMatcher).
+		Reply(200).
+		File("_test_jsons/moses.json")
+	gock.InterceptClient(testHttpClient)
+	_, _, err := mosesService.DistanceTime(testContext, testHTTPRequester, testTaxiRequestWaypoints)
+	assert.Nil(t, err)
+	assert.Equal(t, []mosesPoint{
+		{Type: mosesPointType, X: testTaxiRequestMoscow.Point1.Lon, Y: testTaxiRequestMoscow.Point1.Lat},
+		{Type: mosesPointType, X: testWaypoint.Lon, Y: testWaypoint.Lat},
+		{Type: mosesPointType, X: testTaxiRequestMoscow.Point2.Lon, Y: testTaxiRequestMoscow.Point2.Lat},
+	}, data.Points)
+	assert.Equal(t, gock.IsDone(), true)
+}
+
+func TestMosesPreOrder(t *testing.T) {
+	defer gock.Off()
+	var data mosesReqData
+	bodyMatcher := gock.NewBasicMatcher()
+	bodyMatcher.Add(func(req *http.Request, ereq *gock.Request) (bool, error) {
+		return true, json.NewDecoder(req.Body).Decode(&data)
+	})
+	mosesService, _ := NewMosesService(new(mockRegionsInfo))
+	gock.New(mosesService.mosesURL.Host).
+		Post(mosesService.mosesURL.Path).
+		SetMatcher(bodyMatcher).
+		Reply(200).
+		File("_test_jsons/moses.preorder.json")
+	gock.InterceptClient(testHttpClient)
+	distance, times, err := mosesService.DistanceTime(testContext, testHTTPRequester, testTaxiRequestScheduled)
+	assert.Nil(t, err)
+	assert.Equal(t, mosesStatisticType, data.Type)
+	assert.Equal(t, testPickupTime.Unix(), data.UTC)
+	assert.Equal(t, 1890, distance)
+	assert.Equal(t, 22, times)
+	assert.Equal(t, gock.IsDone(), true)
+}
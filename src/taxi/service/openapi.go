@@ -0,0 +1,207 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/errorswrapper"
+	"github.com/pkg/errors"
+)
+
+// openapi.json - источник контракта REST API, openapi_spec.go генерируется из него.
+// Запросы проверяются по схемам из OpenAPISpec
+
+//go:generate go run gen_openapi.go
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// openAPISchema - схема из components.schemas. Разбираются только ключевые слова, которые используют
+// схемы запросов; MaxAheadHours - расширение x-max-ahead-hours: время в будущем и не дальше стольких часов
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref"`
+	Type                 string                    `json:"type"`
+	Format               string                    `json:"format"`
+	Nullable             bool                      `json:"nullable"`
+	Required             []string                  `json:"required"`
+	AdditionalProperties *bool                     `json:"additionalProperties"`
+	Properties           map[string]*openAPISchema `json:"properties"`
+	Items                *openAPISchema            `json:"items"`
+	Minimum              *float64                  `json:"minimum"`
+	Maximum              *float64                  `json:"maximum"`
+	MinItems             *int                      `json:"minItems"`
+	MaxItems             *int                      `json:"maxItems"`
+	MaxAheadHours        *int                      `json:"x-max-ahead-hours"`
+}
+
+// openAPISchemas - схемы components.schemas из OpenAPISpec
+var openAPISchemas = mustParseSchemas(OpenAPISpec)
+
+func mustParseSchemas(spec string) map[string]*openAPISchema {
+	var doc struct {
+		Components struct {
+			Schemas map[string]*openAPISchema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(spec), &doc); err != nil {
+		panic(fmt.Sprintf("openapi.json: %v", err))
+	}
+	return doc.Components.Schemas
+}
+
+// resolve - схема, на которую ссылается $ref
+func (sc *openAPISchema) resolve() *openAPISchema {
+	for sc.Ref != "" {
+		ref, ok := openAPISchemas[strings.TrimPrefix(sc.Ref, schemaRefPrefix)]
+		if !ok {
+			panic(fmt.Sprintf("openapi.json: unknown schema %v", sc.Ref))
+		}
+		sc = ref
+	}
+	return sc
+}
+
+// schemaValidator - проверяет JSON документ по схеме и копит ошибки всех полей.
+// cause - исходная ошибка нарушения по пути к полю и ошибке ключевого слова схемы
+type schemaValidator struct {
+	now   time.Time
+	cause func(field string, err error) error
+	err   error
+}
+
+// validateSchema - проверяем документ doc, разобранный в interface{}, по схеме sc
+func validateSchema(sc *openAPISchema, doc interface{}, now time.Time, cause func(field string, err error) error) error {
+	v := &schemaValidator{now: now, cause: cause}
+	v.value(sc, doc, "")
+	return v.err
+}
+
+func (v *schemaValidator) fail(field, message string, err error) {
+	if field == "" {
+		// документ целиком, а не его поле
+		v.err = errorswrapper.Append(v.err, errors.Wrap(v.cause(field, err), "JSON "+message))
+		return
+	}
+	v.err = errorswrapper.Append(v.err, fieldError(field, message, v.cause(field, err)))
+}
+
+func (v *schemaValidator) value(sc *openAPISchema, value interface{}, field string) {
+	sc = sc.resolve()
+	if value == nil {
+		if !sc.Nullable {
+			v.fail(field, "must be "+sc.Type, ErrFieldType)
+		}
+		return
+	}
+	switch sc.Type {
+	case "object":
+		if obj, ok := value.(map[string]interface{}); ok {
+			v.object(sc, obj, field)
+			return
+		}
+	case "array":
+		if arr, ok := value.([]interface{}); ok {
+			v.array(sc, arr, field)
+			return
+		}
+	case "integer", "number":
+		if n, ok := value.(float64); ok && (sc.Type == "number" || n == math.Trunc(n)) {
+			v.number(sc, n, field)
+			return
+		}
+	case "string":
+		if s, ok := value.(string); ok {
+			v.str(sc, s, field)
+			return
+		}
+	case "boolean":
+		if _, ok := value.(bool); ok {
+			return
+		}
+	default:
+		return
+	}
+	v.fail(field, "must be "+sc.Type, ErrFieldType)
+}
+
+// object - сначала отсутствующие обязательные поля, затем поля документа по алфавиту
+func (v *schemaValidator) object(sc *openAPISchema, obj map[string]interface{}, field string) {
+	for _, name := range sc.Required {
+		if _, ok := obj[name]; !ok {
+			v.fail(fieldPath(field, name), "is required", ErrFieldRequired)
+		}
+	}
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := sc.Properties[name]
+		if !ok {
+			if sc.AdditionalProperties != nil && !*sc.AdditionalProperties {
+				v.fail(fieldPath(field, name), "unknown field", ErrUnknownField)
+			}
+			continue
+		}
+		v.value(prop, obj[name], fieldPath(field, name))
+	}
+}
+
+func (v *schemaValidator) array(sc *openAPISchema, arr []interface{}, field string) {
+	if sc.MinItems != nil && len(arr) < *sc.MinItems {
+		v.fail(field, fmt.Sprintf("at least %v items, got %v", *sc.MinItems, len(arr)), ErrFieldRange)
+	}
+	if sc.MaxItems != nil && len(arr) > *sc.MaxItems {
+		v.fail(field, fmt.Sprintf("at most %v items, got %v", *sc.MaxItems, len(arr)), ErrFieldRange)
+	}
+	if sc.Items == nil {
+		return
+	}
+	for i, item := range arr {
+		v.value(sc.Items, item, fmt.Sprintf("%v[%v]", field, i))
+	}
+}
+
+func (v *schemaValidator) number(sc *openAPISchema, n float64, field string) {
+	tooSmall := sc.Minimum != nil && n < *sc.Minimum
+	tooBig := sc.Maximum != nil && n > *sc.Maximum
+	switch {
+	case (tooSmall || tooBig) && sc.Minimum != nil && sc.Maximum != nil:
+		v.fail(field, fmt.Sprintf("must be in [%v, %v]", *sc.Minimum, *sc.Maximum), ErrFieldRange)
+	case tooSmall:
+		v.fail(field, fmt.Sprintf("must be >= %v", *sc.Minimum), ErrFieldRange)
+	case tooBig:
+		v.fail(field, fmt.Sprintf("must be <= %v", *sc.Maximum), ErrFieldRange)
+	}
+}
+
+func (v *schemaValidator) str(sc *openAPISchema, s string, field string) {
+	if sc.Format != "date-time" {
+		return
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		v.fail(field, "must be date-time", ErrFieldType)
+		return
+	}
+	if sc.MaxAheadHours == nil {
+		return
+	}
+	maxAhead := time.Duration(*sc.MaxAheadHours) * time.Hour
+	if !t.After(v.now) {
+		v.fail(field, s+" is in the past", ErrPickupTimeInPast)
+	} else if t.Sub(v.now) > maxAhead {
+		v.fail(field, fmt.Sprintf("%v is more than %v ahead", s, maxAhead), ErrPickupTimeTooFar)
+	}
+}
+
+func fieldPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIGenerated(t *testing.T) {
+	spec, err := ioutil.ReadFile("openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(spec), OpenAPISpec, "openapi_spec.go is outdated, run make openapi")
+}
+
+// TestOpenAPIRequestKeywords - в схемах запросов нет ключевых слов, которые не проверяет schemaValidator
+func TestOpenAPIRequestKeywords(t *testing.T) {
+	supported := map[string]bool{
+		"$ref": true, "type": true, "format": true, "nullable": true, "required": true, "additionalProperties": true,
+		"properties": true, "items": true, "minimum": true, "maximum": true, "minItems": true, "maxItems": true,
+		"x-max-ahead-hours": true, "description": true,
+	}
+	var spec struct {
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(OpenAPISpec), &spec); err != nil {
+		t.Fatal(err)
+	}
+	var check func(path string, schema interface{})
+	check = func(path string, schema interface{}) {
+		for keyword, value := range schema.(map[string]interface{}) {
+			assert.True(t, supported[keyword], "%v: %v is not validated", path, keyword)
+			switch keyword {
+			case "properties":
+				for name, prop := range value.(map[string]interface{}) {
+					check(path+"."+name, prop)
+				}
+			case "items":
+				check(path+"[]", value)
+			}
+		}
+	}
+	for _, name := range []string{"Request", "Point", "BatchRequest"} {
+		check(name, spec.Components.Schemas[name])
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	now := testPickupTime.Add(-time.Hour)
+	validate := func(doc string) []string {
+		var v interface{}
+		if err := json.Unmarshal([]byte(doc), &v); err != nil {
+			t.Fatal(err)
+		}
+		var messages []string
+		for _, fe := range FieldErrors(validateSchema(openAPISchemas["Request"], v, now, requestCause)) {
+			messages = append(messages, fe.Error())
+		}
+		return messages
+	}
+	point := `"point1": {"lat": 46.4, "lon": 30.7}, "point2": {"lat": 46.4, "lon": 30.7}`
+	assert.Empty(t, validate(`{"region_id": 14, `+point+`, "waypoints": null, "pickup_time": null, "only_api": true, "user_id": "u"}`))
+	assert.Equal(t, []string{
+		"only_api: must be boolean",
+		"point1: must be object",
+		"region_id: must be integer",
+		"user_id: must be string",
+		"waypoints[0]: must be object",
+	}, validate(`{"region_id": 14.5, "point1": [], "point2": {"lat": 46.4, "lon": 30.7}, "waypoints": [null], "only_api": 1, "user_id": 1}`))
+	assert.Equal(t, []string{"pickup_time: 2018-07-01T15:00:00Z is in the past"}, validate(`{"region_id": 14, `+point+`, "pickup_time": "2018-07-01T15:00:00Z"}`))
+	err := validateSchema(openAPISchemas["Request"], []interface{}{}, now, requestCause)
+	assert.Equal(t, ErrFieldType, errors.Cause(err))
+	assert.Empty(t, FieldErrors(err), "the document itself is not a field")
+
+	err = validateSchema(batchEnvelopeSchema, map[string]interface{}{"requests": []interface{}{}}, now, batchCause)
+	assert.Equal(t, ErrBatchEmpty, errors.Cause(err))
+	assert.Nil(t, validateSchema(batchEnvelopeSchema, map[string]interface{}{"requests": []interface{}{1}}, now, batchCause), "requests of the batch are validated one by one")
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/pkg/errors"
+)
+
+// Этапы обработки ответа провайдера для ошибок невалидных значений
+const (
+	stagePrice = "price"
+	stageTime  = "time"
+	// stageResponse - разбор ответа провайдера
+	stageResponse = "response"
+)
+
+// InvalidPrice - ошибка провайдера: в ответе нет цен или они невалидны. errors.Cause - ErrInvalidPrice
+func InvalidPrice(provider, message string) error {
+	return apierror.New(provider, stagePrice, apierror.KindInvalidPrice, errors.Wrap(ErrInvalidPrice, message))
+}
+
+// InvalidTime - ошибка провайдера: в ответе нет времени подачи или оно невалидно. errors.Cause - ErrInvalidTime
+func InvalidTime(provider, message string) error {
+	return apierror.New(provider, stageTime, apierror.KindInvalidTime, errors.Wrap(ErrInvalidTime, message))
+}
+
+// InvalidResponse - ошибка провайдера: ответ не соответствует ожидаемому, например в нем нет поля
+// по пути из настроек. Вид - KindParse, errors.Cause - исходная ошибка
+func InvalidResponse(provider string, err error) error {
+	return apierror.New(provider, stageResponse, apierror.KindParse, err)
+}
+
+// providerErrorKinds - виды всех ошибок внутри ошибки провайдера. Ошибки без типа классифицируем
+// по sentinel-ошибке из errors.Cause
+func providerErrorKinds(err error) []apierror.Kind {
+	if err == nil {
+		return nil
+	}
+	apiErrs := apierror.All(err)
+	if len(apiErrs) == 0 {
+		return []apierror.Kind{kindByCause(err)}
+	}
+	kinds := make([]apierror.Kind, 0, len(apiErrs))
+	for _, apiErr := range apiErrs {
+		kinds = append(kinds, apiErr.Kind)
+	}
+	return kinds
+}
+
+func hasProviderErrorKind(err error, kind apierror.Kind) bool {
+	for _, k := range providerErrorKinds(err) {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func kindByCause(err error) apierror.Kind {
+	switch errors.Cause(err) {
+	case httprequester.ErrContextDeadline:
+		return apierror.KindTimeout
+	case httprequester.ErrDoRequest:
+		return apierror.KindTransport
+	case httprequester.ErrReadRequest:
+		return apierror.KindRead
+	case httprequester.ErrStatusNotOK:
+		return apierror.KindStatus
+	case httprequester.ErrParse, httprequester.ErrResponseEmpty:
+		return apierror.KindParse
+	case httprequester.ErrCreateRequest:
+		return apierror.KindRequest
+	case ErrInvalidPrice:
+		return apierror.KindInvalidPrice
+	case ErrInvalidTime:
+		return apierror.KindInvalidTime
+	}
+	return apierror.KindUnknown
+}
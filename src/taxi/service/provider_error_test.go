@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/errorswrapper"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidValueErrors(t *testing.T) {
+	err := errors.Wrap(InvalidPrice("gett", "Price <= 0"), "Gett: Cannot request prices")
+	assert.Equal(t, ErrInvalidPrice, errors.Cause(err))
+	assert.Equal(t, "Gett: Cannot request prices: Price <= 0: Invalid price", err.Error())
+	apiErr, ok := apierror.As(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "gett", apiErr.Provider)
+		assert.Equal(t, stagePrice, apiErr.Stage)
+		assert.Equal(t, apierror.KindInvalidPrice, apiErr.Kind)
+		assert.False(t, apiErr.Retryable)
+	}
+	assert.Equal(t, ErrInvalidTime, errors.Cause(InvalidTime("uber", "Time <= 0")))
+}
+
+func TestProviderErrorKinds(t *testing.T) {
+	timeout := &apierror.Error{Provider: "gett", Kind: apierror.KindTimeout, Retryable: true, Err: errors.Wrap(httprequester.ErrContextDeadline, "url")}
+	tests := []struct {
+		name   string
+		err    error
+		kinds  []apierror.Kind
+		status string
+	}{
+		{"nil", nil, nil, ProviderOK},
+		{"untyped timeout", errors.Wrap(errors.Wrap(httprequester.ErrContextDeadline, "url"), "Gett"), []apierror.Kind{apierror.KindTimeout}, ProviderTimeout},
+		{"untyped status", errors.Wrap(httprequester.ErrStatusNotOK, "500"), []apierror.Kind{apierror.KindStatus}, ProviderHTTPError},
+		{"untyped price", errors.Wrap(ErrInvalidPrice, "Price <= 0"), []apierror.Kind{apierror.KindInvalidPrice}, ProviderInvalidPrice},
+		{"unknown", errors.New("unknown"), []apierror.Kind{apierror.KindUnknown}, ProviderHTTPError},
+		{"wrapped typed", errors.Wrap(errors.Wrap(timeout, "Gett: Cannot request prices"), "gett"), []apierror.Kind{apierror.KindTimeout}, ProviderTimeout},
+		{
+			"multi",
+			errorswrapper.Append(errors.Wrap(InvalidPrice("gett", "Price list is empty"), "prices"), errors.Wrap(timeout, "eta")),
+			[]apierror.Kind{apierror.KindInvalidPrice, apierror.KindTimeout},
+			ProviderTimeout,
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.kinds, providerErrorKinds(test.err), test.name)
+		assert.Equal(t, test.status, providerStatusByErr(test.err), test.name)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
+)
+
+// Статусы провайдеров в блоке providers ответа
+const (
+	// ProviderOK - провайдер ответил, его результаты есть в ответе
+	ProviderOK = "ok"
+	// ProviderTimeout - провайдер не успел ответить
+	ProviderTimeout = "timeout"
+	// ProviderHTTPError - провайдер недоступен или ответил ошибкой
+	ProviderHTTPError = "http_error"
+	// ProviderInvalidPrice - провайдер вернул невалидные цены
+	ProviderInvalidPrice = "invalid_price"
+	// ProviderFilteredOut - все тарифы провайдера отфильтрованы настройками продукта
+	ProviderFilteredOut = "filtered_out"
+	// ProviderCircuitOpen - провайдер не опрашивался, т.к. его circuit breaker разомкнут
+	ProviderCircuitOpen = "circuit_open"
+	// ProviderWaypointsUnsupported - провайдер не опрашивался, т.к. не умеет считать маршрут с остановками
+	ProviderWaypointsUnsupported = "waypoints_unsupported"
+	// ProviderPreOrderUnsupported - провайдер не опрашивался, т.к. не умеет считать предварительный заказ
+	ProviderPreOrderUnsupported = "preorder_unsupported"
+)
+
+// ProviderStatus - статус провайдера, опрошенного для запроса
+type ProviderStatus struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Latency int    `json:"latency_ms"`
+}
+
+func newProviderStatus(name, status string, start time.Time) ProviderStatus {
+	return ProviderStatus{
+		Name:    name,
+		Status:  status,
+		Latency: int(time.Since(start) / time.Millisecond),
+	}
+}
+
+// providerStatusByErr - статус провайдера, который не вернул ни одного результата.
+// Если запросов к провайдеру было несколько, смотрим на ошибки каждого из них
+func providerStatusByErr(err error) string {
+	if err == nil {
+		return ProviderOK
+	}
+	if hasProviderErrorKind(err, apierror.KindTimeout) {
+		return ProviderTimeout
+	}
+	if hasProviderErrorKind(err, apierror.KindInvalidPrice) {
+		return ProviderInvalidPrice
+	}
+	return ProviderHTTPError
+}
+
+type byProviderName []ProviderStatus
+
+func (a byProviderName) Len() int           { return len(a) }
+func (a byProviderName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byProviderName) Less(i, j int) bool { return a[i].Name < a[j].Name }
+
+// fanoutResult - результаты опроса провайдеров для одного запроса
+type fanoutResult struct {
+	mu       sync.Mutex
+	records  []Record
+	statuses []ProviderStatus
+	notify   recordsNotifier
+}
+
+func (r *fanoutResult) add(status ProviderStatus, records []Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, status)
+	r.records = append(r.records, records...)
+	if r.notify != nil && len(records) != 0 {
+		r.notify(status.Name, records)
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/collector"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/ttlcache"
+)
+
+const (
+	// defaultQuoteCacheLevel - уровень S2 ячейки по умолчанию, ~150 метров
+	defaultQuoteCacheLevel = 16
+	// defaultQuoteCacheSize - сколько маршрутов храним по умолчанию
+	defaultQuoteCacheSize = 10000
+)
+
+// QuoteCacheSettings - настройки кэша ответов провайдеров.
+// Точки маршрута привязываются к S2 ячейке уровня Level, поэтому близкие запросы попадают в одну запись.
+// Кэш выключен, если не задан ни общий TTL, ни TTL провайдеров
+type QuoteCacheSettings struct {
+	// Level - уровень S2 ячейки, к которой привязываем точки
+	Level int `json:"s2_level"`
+	// MaxEntries - максимальное количество записей, самые давно использованные вытесняются
+	MaxEntries int `json:"max_entries"`
+	// TTL - время жизни записи для провайдеров, которых нет в ProvidersTTL
+	TTL duration.Milliseconds `json:"ttl_ms"`
+	// ProvidersTTL - время жизни записи по имени провайдера; 0 - не кэшируем провайдера
+	ProvidersTTL map[string]duration.Milliseconds `json:"providers_ttl_ms"`
+}
+
+// IsEmpty - кэш не настроен
+func (qs QuoteCacheSettings) IsEmpty() bool {
+	return qs.TTL == 0 && len(qs.ProvidersTTL) == 0
+}
+
+func (qs QuoteCacheSettings) ttl(providerName string) time.Duration {
+	if ttl, ok := qs.ProvidersTTL[providerName]; ok {
+		return ttl.Duration()
+	}
+	return qs.TTL.Duration()
+}
+
+// quoteKey - ключ кэша: регион, провайдер и ячейки точек маршрута
+type quoteKey struct {
+	regionID int
+	provider string
+	from     s2.CellID
+	to       s2.CellID
+	stops    string
+}
+
+// quoteCache - LRU кэш ответов провайдеров с TTL
+type quoteCache struct {
+	settings QuoteCacheSettings
+	cache    *ttlcache.Cache
+	coll     *collector.Collector
+}
+
+func newQuoteCache(settings QuoteCacheSettings, coll *collector.Collector) *quoteCache {
+	if settings.IsEmpty() {
+		return nil
+	}
+	settings.Level = ttlcache.CellLevel(settings.Level, defaultQuoteCacheLevel)
+	if settings.MaxEntries <= 0 {
+		settings.MaxEntries = defaultQuoteCacheSize
+	}
+	qc := &quoteCache{settings: settings, coll: coll}
+	qc.cache = ttlcache.New(settings.MaxEntries, qc.evicted)
+	return qc
+}
+
+func (qc *quoteCache) key(taxiReq Request, providerName string) quoteKey {
+	return quoteKey{
+		regionID: taxiReq.RegionID,
+		provider: providerName,
+		from:     ttlcache.Cell(taxiReq.Point1.Lat, taxiReq.Point1.Lon, qc.settings.Level),
+		to:       ttlcache.Cell(taxiReq.Point2.Lat, taxiReq.Point2.Lon, qc.settings.Level),
+		stops:    waypointCells(taxiReq.Waypoints, qc.settings.Level),
+	}
+}
+
+// get - ответ провайдера для маршрута из кэша. Координаты и адреса в TemplateVars заменяем на точки
+// текущего запроса, чтобы диплинки вели туда, куда просил пользователь, а не в центр ячейки
+func (qc *quoteCache) get(taxiReq Request, providerName string) ([]APIData, bool) {
+	if qc == nil || taxiReq.IsScheduled() || qc.settings.ttl(providerName) <= 0 {
+		return nil, false
+	}
+	value, ok := qc.cache.Get(qc.key(taxiReq, providerName))
+	if !ok {
+		qc.addMiss(providerName)
+		return nil, false
+	}
+	qc.addHit(providerName)
+
+	cached := value.([]APIData)
+	data := make([]APIData, 0, len(cached))
+	for _, apiData := range cached {
+		apiData.TemplateVars = pointTemplateVars(apiData.TemplateVars, taxiReq)
+		data = append(data, apiData)
+	}
+	return data, true
+}
+
+// put - сохраняем ответ провайдера для маршрута. Цены предварительных заказов и ответы с идентификатором
+// заказа не кэшируем: диплинк из кэша не должен вести другого пользователя в чужой заказ
+func (qc *quoteCache) put(taxiReq Request, providerName string, data []APIData) {
+	if qc == nil || taxiReq.IsScheduled() || len(data) == 0 || hasOrderID(data) {
+		return
+	}
+	qc.cache.Put(qc.key(taxiReq, providerName), append([]APIData(nil), data...), qc.settings.ttl(providerName))
+}
+
+func (qc *quoteCache) evicted(key, value interface{}, cause string) {
+	if qc.coll != nil {
+		qc.coll.AddQuoteCacheEviction(key.(quoteKey).provider, cause)
+	}
+}
+
+func (qc *quoteCache) addHit(providerName string) {
+	if qc.coll != nil {
+		qc.coll.AddQuoteCacheHit(providerName)
+	}
+}
+
+func (qc *quoteCache) addMiss(providerName string) {
+	if qc.coll != nil {
+		qc.coll.AddQuoteCacheMiss(providerName)
+	}
+}
+
+func hasOrderID(data []APIData) bool {
+	for _, apiData := range data {
+		if _, ok := apiData.TemplateVars[OrderIDVar]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// waypointCells - ячейки остановок маршрута одной строкой, чтобы ключ кэша оставался сравнимым
+func waypointCells(waypoints []Point, level int) string {
+	if len(waypoints) == 0 {
+		return ""
+	}
+	tokens := make([]string, 0, len(waypoints))
+	for _, p := range waypoints {
+		tokens = append(tokens, ttlcache.Cell(p.Lat, p.Lon, level).ToToken())
+	}
+	return strings.Join(tokens, ",")
+}
+
+// pointTemplateVars - копия переменных шаблона с координатами и адресами точек из запроса
+func pointTemplateVars(vars map[string]string, taxiReq Request) map[string]string {
+	if vars == nil {
+		return nil
+	}
+	points := taxiReq.WaypointTemplateVars()
+	points["%from.lat%"] = taxiReq.Point1.LatStr
+	points["%from.lon%"] = taxiReq.Point1.LonStr
+	points["%from.address%"] = taxiReq.Point1.Address
+	points["%to.lat%"] = taxiReq.Point2.LatStr
+	points["%to.lon%"] = taxiReq.Point2.LonStr
+	points["%to.address%"] = taxiReq.Point2.Address
+	res := make(map[string]string, len(vars))
+	for name, value := range vars {
+		if pointValue, ok := points[name]; ok {
+			value = pointValue
+		}
+		res[name] = value
+	}
+	return res
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/product"
+	"github.com/stretchr/testify/assert"
+)
+
+var testQuoteCacheSettings = QuoteCacheSettings{
+	Level:        16,
+	MaxEntries:   2,
+	TTL:          duration.Milliseconds(10 * time.Second),
+	ProvidersTTL: map[string]duration.Milliseconds{"nocache": 0},
+}
+
+func newTestQuoteCache() (*quoteCache, *testClock) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	qc := newQuoteCache(testQuoteCacheSettings, testCollector)
+	qc.cache.Now = clock.Now
+	return qc, clock
+}
+
+func movedRequest(req Request, dLat float64) Request {
+	req.Point1.Lat += dLat
+	req.Point1.stringfy()
+	return req
+}
+
+func TestQuoteCacheSnapping(t *testing.T) {
+	qc, _ := newTestQuoteCache()
+	data := []APIData{
+		APIData{
+			PriceMean: 100,
+			TemplateVars: map[string]string{
+				"%from.lat%":   testTaxiRequestMoscow.Point1.LatStr,
+				"%product.id%": "econom",
+			},
+		},
+	}
+	qc.put(testTaxiRequestMoscow, "test1", data)
+
+	near := movedRequest(testTaxiRequestMoscow, 0.00001)
+	cached, ok := qc.get(near, "test1")
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, cached[0].PriceMean)
+	assert.Equal(t, near.Point1.LatStr, cached[0].TemplateVars["%from.lat%"], "deeplink points to the requested point")
+	assert.Equal(t, "econom", cached[0].TemplateVars["%product.id%"])
+	assert.Equal(t, testTaxiRequestMoscow.Point1.LatStr, data[0].TemplateVars["%from.lat%"], "cached data is not modified")
+
+	_, ok = qc.get(movedRequest(testTaxiRequestMoscow, 0.01), "test1")
+	assert.False(t, ok)
+	_, ok = qc.get(testTaxiRequestMoscow, "test2")
+	assert.False(t, ok)
+	other := testTaxiRequestMoscow
+	other.RegionID++
+	_, ok = qc.get(other, "test1")
+	assert.False(t, ok)
+}
+
+func TestQuoteCacheEviction(t *testing.T) {
+	qc, clock := newTestQuoteCache()
+	data := []APIData{APIData{PriceMean: 100}}
+	qc.put(testTaxiRequestMoscow, "test1", data)
+	clock.now = clock.now.Add(testQuoteCacheSettings.TTL.Duration() + time.Second)
+	_, ok := qc.get(testTaxiRequestMoscow, "test1")
+	assert.False(t, ok, "expired")
+	assert.Equal(t, 0, qc.cache.Len())
+
+	qc.put(testTaxiRequestMoscow, "test1", data)
+	qc.put(testTaxiRequestMoscow, "test2", data)
+	_, ok = qc.get(testTaxiRequestMoscow, "test1")
+	assert.True(t, ok)
+	qc.put(testTaxiRequestMoscow, "test3", data)
+	_, ok = qc.get(testTaxiRequestMoscow, "test2")
+	assert.False(t, ok, "least recently used is evicted")
+	_, ok = qc.get(testTaxiRequestMoscow, "test1")
+	assert.True(t, ok)
+
+	qc.put(testTaxiRequestMoscow, "nocache", data)
+	_, ok = qc.get(testTaxiRequestMoscow, "nocache")
+	assert.False(t, ok, "provider ttl 0 disables cache")
+	assert.Nil(t, newQuoteCache(QuoteCacheSettings{}, nil))
+}
+
+func TestServiceQuoteCache(t *testing.T) {
+	var calls int
+	api := countingAPIDataGetter{
+		mockAPIDataGetter: mockAPIDataGetter{
+			apiDatas: []APIData{APIData{PriceMean: 100}},
+			name:     "test1",
+		},
+		calls: &calls,
+	}
+	service := NewBuilder().
+		WithAPIs([]APIDataGetter{api}).
+		WithProductCache(newMockProductCache(nil, product.Product{ProviderName: "test1", Name: "test1"})).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		WithQuoteCache(QuoteCacheSettings{TTL: duration.Milliseconds(time.Minute)}).
+		Build()
+	for i := 0; i < 3; i++ {
+		resp, err := service.Response(testContext, movedRequest(testTaxiRequestMoscow, float64(i)*0.00001), priceOff)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(resp.Result.Optimal.Results))
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestQuoteCacheWaypoints(t *testing.T) {
+	qc, _ := newTestQuoteCache()
+	qc.put(testTaxiRequestMoscow, "test1", []APIData{APIData{PriceMean: 100}})
+	_, ok := qc.get(testTaxiRequestWaypoints, "test1")
+	assert.False(t, ok, "route with stops is not the direct route")
+
+	qc.put(testTaxiRequestWaypoints, "test1", []APIData{APIData{
+		PriceMean:    150,
+		TemplateVars: testTaxiRequestWaypoints.WaypointTemplateVars(),
+	}})
+	moved := testTaxiRequestWaypoints
+	moved.Waypoints = []Point{testWaypoint}
+	moved.Waypoints[0].Lat += 0.00001
+	moved.Waypoints[0].stringfy()
+	cached, ok := qc.get(moved, "test1")
+	assert.True(t, ok)
+	assert.Equal(t, 150.0, cached[0].PriceMean)
+	assert.Equal(t, moved.Waypoints[0].LatStr, cached[0].TemplateVars["%waypoint1.lat%"])
+}
+
+func TestQuoteCacheOrderID(t *testing.T) {
+	qc, _ := newTestQuoteCache()
+	data := []APIData{
+		APIData{
+			PriceMean: 100,
+			TemplateVars: map[string]string{
+				"%from.lat%": testTaxiRequestMoscow.Point1.LatStr,
+				OrderIDVar:   "order-of-first-user",
+			},
+		},
+	}
+	qc.put(testTaxiRequestMoscow, "test1", data)
+	cached, ok := qc.get(movedRequest(testTaxiRequestMoscow, 0.00001), "test1")
+	assert.False(t, ok, "order id is not served to another request")
+	assert.Nil(t, cached)
+}
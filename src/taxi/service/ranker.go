@@ -0,0 +1,233 @@
+package service
+
+import (
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/pkg/errors"
+)
+
+// Стратегии выбора оптимального варианта
+const (
+	// RankSticky - продукт региона с флагом is_optimal из БД; если в регионе такого нет - RankRandom
+	RankSticky = "sticky"
+	// RankPrice - самый дешевый вариант
+	RankPrice = "price"
+	// RankWeighted - вариант с лучшей взвешенной оценкой цены, времени подачи, рейтинга и повышающего коэффициента
+	RankWeighted = "weighted"
+	// RankRandom - случайный вариант среди тех, что дороже самого дешевого не больше чем в price_coeff раз.
+	// Случайность зависит только от пользователя или запроса, поэтому повторный запрос получает тот же выбор
+	RankRandom = "random"
+)
+
+var (
+	// ErrUnknownRanker - в настройках указана неизвестная стратегия выбора оптимального варианта
+	ErrUnknownRanker = errors.New("Unknown ranking strategy")
+)
+
+// Ranker - стратегия выбора оптимального варианта
+type Ranker interface {
+	// Rank - индекс оптимальной записи; records отсортированы по возрастанию цены. -1 - оптимального варианта нет
+	Rank(req Request, records []Record, isOptimalInRegion bool, priceCoeff float64) int
+}
+
+// RankWeights - веса взвешенной оценки. Каждый показатель нормируется по лучшему значению среди вариантов
+type RankWeights struct {
+	Price  float64 `json:"price"`
+	Eta    float64 `json:"eta"`
+	Rating float64 `json:"rating"`
+	Surge  float64 `json:"surge"`
+}
+
+func (w RankWeights) isEmpty() bool {
+	return w == RankWeights{}
+}
+
+// RankerSettings - стратегия выбора оптимального варианта и веса для RankWeighted
+type RankerSettings struct {
+	Strategy string      `json:"strategy"`
+	Weights  RankWeights `json:"weights"`
+}
+
+// RankingSettings - стратегия по умолчанию и стратегии по коду региона
+type RankingSettings struct {
+	Default RankerSettings         `json:"default"`
+	Regions map[int]RankerSettings `json:"regions"`
+}
+
+// Validate - проверяем, что все стратегии известны
+func (rs RankingSettings) Validate() error {
+	if _, err := newRanker(rs.Default); err != nil {
+		return errors.Wrap(err, "default")
+	}
+	for regionID, settings := range rs.Regions {
+		if _, err := newRanker(settings); err != nil {
+			return errors.Wrapf(err, "region %v", regionID)
+		}
+	}
+	return nil
+}
+
+// rankers - стратегия по умолчанию и стратегии регионов; неизвестные стратегии заменяем стратегией по умолчанию
+func (rs RankingSettings) rankers() (Ranker, map[int]Ranker) {
+	defaultRanker, err := newRanker(rs.Default)
+	if err != nil {
+		defaultRanker = stickyRanker{}
+	}
+	regions := make(map[int]Ranker, len(rs.Regions))
+	for regionID, settings := range rs.Regions {
+		if ranker, err := newRanker(settings); err == nil {
+			regions[regionID] = ranker
+		}
+	}
+	return defaultRanker, regions
+}
+
+func newRanker(settings RankerSettings) (Ranker, error) {
+	switch settings.Strategy {
+	case "", RankSticky:
+		return stickyRanker{}, nil
+	case RankPrice:
+		return priceRanker{}, nil
+	case RankWeighted:
+		weights := settings.Weights
+		if weights.isEmpty() {
+			weights.Price = 1
+		}
+		return weightedRanker{weights: weights}, nil
+	case RankRandom:
+		return randomRanker{}, nil
+	}
+	return nil, errors.Wrap(ErrUnknownRanker, settings.Strategy)
+}
+
+// priceRanker - самый дешевый вариант
+type priceRanker struct{}
+
+func (priceRanker) Rank(req Request, records []Record, isOptimalInRegion bool, priceCoeff float64) int {
+	if len(records) == 0 {
+		return -1
+	}
+	return 0
+}
+
+// stickyRanker - продукт с флагом is_optimal; если в регионе нет такого продукта - случайный выбор
+type stickyRanker struct{}
+
+func (stickyRanker) Rank(req Request, records []Record, isOptimalInRegion bool, priceCoeff float64) int {
+	if !isOptimalInRegion {
+		return randomRanker{}.Rank(req, records, isOptimalInRegion, priceCoeff)
+	}
+	for ind, rec := range records {
+		if rec.Operator.IsOptimal {
+			return ind
+		}
+	}
+	return -1
+}
+
+// randomRanker - случайный вариант среди близких по цене к самому дешевому.
+// Генератор инициализируется пользователем, а если его нет - идентификатором запроса
+type randomRanker struct{}
+
+func (randomRanker) Rank(req Request, records []Record, isOptimalInRegion bool, priceCoeff float64) int {
+	if len(records) == 0 {
+		return -1
+	}
+	var suitableCandidates int
+	priceLimit := float64(records[0].Price) * priceCoeff
+	for k := range records {
+		if float64(records[k].Price) <= priceLimit {
+			suitableCandidates = k
+			continue
+		}
+		break
+	}
+	if suitableCandidates == 0 {
+		return 0
+	}
+	r := rand.New(rand.NewSource(rankSeed(req)))
+	return r.Intn(suitableCandidates + 1)
+}
+
+func rankSeed(req Request) int64 {
+	key := req.UserID
+	if key == "" {
+		key = req.ReqID
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// weightedRanker - вариант с наименьшей взвешенной оценкой. Показатели нормируются по лучшему значению:
+// цена и время подачи - во сколько раз хуже лучшего, рейтинг - насколько ниже лучшего в долях,
+// повышающий коэффициент - на сколько больше 1
+type weightedRanker struct {
+	weights RankWeights
+}
+
+func (wr weightedRanker) Rank(req Request, records []Record, isOptimalInRegion bool, priceCoeff float64) int {
+	if len(records) == 0 {
+		return -1
+	}
+	minPrice, minEta, maxEta, maxRating := recordsBounds(records)
+	best, bestScore := -1, 0.0
+	for ind, rec := range records {
+		var score float64
+		if minPrice > 0 {
+			score += wr.weights.Price * float64(rec.Price) / minPrice
+		}
+		if minEta > 0 {
+			eta, ok := recordEta(rec)
+			if !ok {
+				eta = maxEta
+			}
+			score += wr.weights.Eta * eta / minEta
+		}
+		if maxRating > 0 {
+			var rating float64
+			if rec.Rating != nil {
+				rating = float64(*rec.Rating)
+			}
+			score += wr.weights.Rating * (maxRating - rating) / maxRating
+		}
+		score += wr.weights.Surge * (rec.surge() - 1)
+		if best == -1 || score < bestScore {
+			best, bestScore = ind, score
+		}
+	}
+	return best
+}
+
+// recordEta - время подачи от провайдера, а если его нет - среднее время подачи продукта
+func recordEta(rec Record) (float64, bool) {
+	if rec.Eta != nil && *rec.Eta > 0 {
+		return float64(*rec.Eta), true
+	}
+	if rec.AvgEta != nil && *rec.AvgEta > 0 {
+		return float64(*rec.AvgEta), true
+	}
+	return 0, false
+}
+
+func recordsBounds(records []Record) (minPrice, minEta, maxEta, maxRating float64) {
+	for _, rec := range records {
+		price := float64(rec.Price)
+		if price > 0 && (minPrice == 0 || price < minPrice) {
+			minPrice = price
+		}
+		if eta, ok := recordEta(rec); ok {
+			if minEta == 0 || eta < minEta {
+				minEta = eta
+			}
+			if eta > maxEta {
+				maxEta = eta
+			}
+		}
+		if rec.Rating != nil && float64(*rec.Rating) > maxRating {
+			maxRating = float64(*rec.Rating)
+		}
+	}
+	return minPrice, minEta, maxEta, maxRating
+}
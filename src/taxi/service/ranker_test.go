@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func rankedRecord(price int, eta int, rating float32, surge float64, isOptimal bool) Record {
+	rec := surgedRecord(price, surge, isOptimal)
+	if eta > 0 {
+		rec.Eta = intPointer(eta)
+	}
+	if rating > 0 {
+		rec.Rating = float32Pointer(rating)
+	}
+	return rec
+}
+
+var testRankRecords = []Record{
+	rankedRecord(100, 15, 4.1, 1.9, false),
+	rankedRecord(110, 3, 4.8, 0, true),
+	rankedRecord(125, 5, 4.9, 0, false),
+	rankedRecord(300, 2, 5.0, 0, false),
+}
+
+func TestRankingSettings(t *testing.T) {
+	var rs RankingSettings
+	err := json.Unmarshal([]byte(`{"default": {"strategy": "price"}, "regions": {"32": {"strategy": "weighted", "weights": {"eta": 1}}}}`), &rs)
+	assert.Nil(t, err)
+	assert.Nil(t, rs.Validate())
+	ranker, rankers := rs.rankers()
+	assert.Equal(t, priceRanker{}, ranker)
+	assert.Equal(t, weightedRanker{weights: RankWeights{Eta: 1}}, rankers[32])
+
+	rs.Regions[1] = RankerSettings{Strategy: "best"}
+	assert.Equal(t, ErrUnknownRanker, errors.Cause(rs.Validate()))
+	_, rankers = rs.rankers()
+	assert.Nil(t, rankers[1], "unknown strategy falls back to default")
+
+	ranker, _ = RankingSettings{}.rankers()
+	assert.Equal(t, stickyRanker{}, ranker)
+}
+
+func TestRankers(t *testing.T) {
+	req := testTaxiRequestMoscow
+	assert.Equal(t, 0, priceRanker{}.Rank(req, testRankRecords, true, priceOff))
+	assert.Equal(t, -1, priceRanker{}.Rank(req, nil, true, priceOff))
+
+	assert.Equal(t, 1, stickyRanker{}.Rank(req, testRankRecords, true, priceOff))
+	assert.Equal(t, -1, stickyRanker{}.Rank(req, testRankRecords[2:], true, priceOff))
+
+	price := weightedRanker{weights: RankWeights{Price: 1}}
+	assert.Equal(t, 0, price.Rank(req, testRankRecords, false, priceOff))
+	eta := weightedRanker{weights: RankWeights{Eta: 1}}
+	assert.Equal(t, 3, eta.Rank(req, testRankRecords, false, priceOff))
+	balanced := weightedRanker{weights: RankWeights{Price: 1, Eta: 0.3, Rating: 0.2, Surge: 0.5}}
+	assert.Equal(t, 1, balanced.Rank(req, testRankRecords, false, priceOff))
+}
+
+func TestRandomRankerSeed(t *testing.T) {
+	records := []Record{
+		rankedRecord(100, 0, 0, 0, false),
+		rankedRecord(101, 0, 0, 0, false),
+		rankedRecord(102, 0, 0, 0, false),
+		rankedRecord(103, 0, 0, 0, false),
+		rankedRecord(500, 0, 0, 0, false),
+	}
+	picks := make(map[int]bool)
+	for _, userID := range []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8"} {
+		req := testTaxiRequestMoscow
+		req.UserID = userID
+		pick := randomRanker{}.Rank(req, records, false, priceOff)
+		assert.True(t, pick >= 0 && pick <= 3, "only options within price coeff")
+		req.ReqID = "another request"
+		assert.Equal(t, pick, randomRanker{}.Rank(req, records, false, priceOff), "same user gets the same pick")
+		picks[pick] = true
+	}
+	assert.True(t, len(picks) > 1, "different users get different picks")
+
+	req := testTaxiRequestMoscow
+	req.ReqID = "req-1"
+	assert.Equal(t, randomRanker{}.Rank(req, records, false, priceOff), randomRanker{}.Rank(req, records, false, priceOff))
+}
+
+func TestServiceRankerByRegion(t *testing.T) {
+	service := getTestService()
+	service.ranker, service.rankers = RankingSettings{
+		Default: RankerSettings{Strategy: RankPrice},
+		Regions: map[int]RankerSettings{32: RankerSettings{Strategy: RankWeighted, Weights: RankWeights{Eta: 1}}},
+	}.rankers()
+	records := append([]Record(nil), testRankRecords...)
+	optimal, elses := service.getOptimalElse(testTaxiRequestMoscow, records, false, priceOff)
+	assert.Equal(t, 300, optimal[0].Price)
+	assert.Equal(t, 3, len(elses))
+
+	other := testTaxiRequestMoscow
+	other.RegionID = 1
+	optimal, _ = service.getOptimalElse(other, records, false, priceOff)
+	assert.Equal(t, 100, optimal[0].Price)
+}
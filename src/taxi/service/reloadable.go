@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// Reloadable - сервис Таксы, который можно заменить без перезапуска, например после перечитывания настроек.
+// Каждый вызов уходит в сервис, текущий на момент вызова; запросы, начатые до замены, дорабатывают со старым
+type Reloadable struct {
+	current atomic.Value
+}
+
+// NewReloadable - оборачиваем сервис Таксы
+func NewReloadable(s *Service) *Reloadable {
+	r := new(Reloadable)
+	r.Swap(s)
+	return r
+}
+
+// Current - текущий сервис
+func (r *Reloadable) Current() *Service {
+	return r.current.Load().(*Service)
+}
+
+// Swap - заменяем сервис
+func (r *Reloadable) Swap(s *Service) {
+	r.current.Store(s)
+}
+
+// Replace - заменяем сервис после перечитывания настроек, перенося в него состояние текущего:
+// circuit breaker провайдеров, кэш ответов провайдеров, кэш маршрутов и лимиты пакетных запросов.
+// Состояние переносится, только если его настройки не изменились; иначе оно начинается заново
+func (r *Reloadable) Replace(s *Service) {
+	s.inherit(r.Current())
+	r.Swap(s)
+}
+
+// IsOK - проверяем, работоспособен ли текущий сервис
+func (r *Reloadable) IsOK() bool {
+	return r.Current().IsOK()
+}
+
+// BreakerStates - состояния circuit breaker провайдеров текущего сервиса
+func (r *Reloadable) BreakerStates() map[string]string {
+	return r.Current().BreakerStates()
+}
+
+// ParseTaxiRequest - парсим запрос к сервису такси
+func (r *Reloadable) ParseTaxiRequest(ctx context.Context, httpReq *http.Request) (Request, error) {
+	return r.Current().ParseTaxiRequest(ctx, httpReq)
+}
+
+// CheckTaxiRequest - проверяем запрос gRPC API к сервису такси
+func (r *Reloadable) CheckTaxiRequest(ctx context.Context, clientHeader string, taxiReq *Request) error {
+	return r.Current().CheckTaxiRequest(ctx, clientHeader, taxiReq)
+}
+
+// EvaluateTaxiRequest - обогащаем запрос к сервису такси
+func (r *Reloadable) EvaluateTaxiRequest(ctx context.Context, taxiReq *Request) error {
+	return r.Current().EvaluateTaxiRequest(ctx, taxiReq)
+}
+
+// Response - возвращает ответ с данными от провайдеров такси
+func (r *Reloadable) Response(ctx context.Context, req Request, priceCoeff float64) (*Response, error) {
+	return r.Current().Response(ctx, req, priceCoeff)
+}
+
+// StreamResponse - потоковый ответ с данными от провайдеров такси
+func (r *Reloadable) StreamResponse(ctx context.Context, req Request, priceCoeff float64, emit func(StreamEvent)) error {
+	return r.Current().StreamResponse(ctx, req, priceCoeff, emit)
+}
+
+// ParseBatchRequest - парсим пакет запросов к сервису такси
+func (r *Reloadable) ParseBatchRequest(ctx context.Context, httpReq *http.Request) (BatchRequest, error) {
+	return r.Current().ParseBatchRequest(ctx, httpReq)
+}
+
+// BatchResponse - ответы на запросы пакета. Пакет целиком выполняется сервисом, текущим на момент вызова
+func (r *Reloadable) BatchResponse(ctx context.Context, batch BatchRequest, itemWaitTime time.Duration, priceCoeff func(regionID int) float64) []BatchItem {
+	return r.Current().BatchResponse(ctx, batch, itemWaitTime, priceCoeff)
+}
+
+// inherit - переносим состояние из prev, если настройки этого состояния у сервисов совпадают.
+// Circuit breaker переносим по имени провайдера: у провайдера, которого не было, цепь замкнута,
+// состояние провайдера, которого больше нет, убираем из метрик
+func (s *Service) inherit(prev *Service) {
+	if prev == nil {
+		return
+	}
+	for name := range prev.breakers {
+		if _, ok := s.breakers[name]; !ok && s.collector != nil {
+			s.collector.DeleteProviderBreakerState(name)
+		}
+	}
+	for name, breaker := range s.breakers {
+		prevBreaker, ok := prev.breakers[name]
+		if !ok || prevBreaker.settings != breaker.settings {
+			continue
+		}
+		s.breakers[name] = prevBreaker
+		if s.collector != nil {
+			s.collector.SetProviderBreakerState(name, int(prevBreaker.State()))
+		}
+	}
+	if s.quoteCache != nil && prev.quoteCache != nil && reflect.DeepEqual(s.quoteCache.settings, prev.quoteCache.settings) {
+		s.quoteCache = prev.quoteCache
+	}
+	if s.routeCache != nil && prev.routeCache != nil && reflect.DeepEqual(s.routeCache.settings, prev.routeCache.settings) {
+		s.routeCache = prev.routeCache
+	}
+	if s.batch == prev.batch {
+		s.batchLimit = prev.batchLimit
+	}
+}
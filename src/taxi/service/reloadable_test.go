@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadableSwap(t *testing.T) {
+	build := func(apiName string, price float64) *Service {
+		return NewBuilder().
+			WithAPIs([]APIDataGetter{newMockAPIDataGetter(nil, apiName, APIData{PriceMean: price})}).
+			WithProductCache(newMockProductCache(nil, product.Product{ProviderName: "test1"}, product.Product{ProviderName: "test2"})).
+			WithRequester(testHTTPRequester).
+			WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+			WithAddressSrv(newMockAddressService(nil, "")).
+			WithStatCollector(testCollector).
+			WithLogger(testLogger).
+			Build()
+	}
+	old := build("test1", 100)
+	srv := NewReloadable(old)
+	assert.True(t, srv.IsOK())
+	assert.Equal(t, map[string]string{"test1": "closed"}, srv.BreakerStates())
+
+	resp, err := srv.Response(testContext, testTaxiRequestMoscow, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, resp.Result.Optimal.Results[0].Price)
+
+	srv.Swap(build("test2", 200))
+	assert.Equal(t, map[string]string{"test2": "closed"}, srv.BreakerStates())
+	resp, err = srv.Response(testContext, testTaxiRequestMoscow, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.Result.Optimal.Results[0].Price)
+
+	resp, err = old.Response(testContext, testTaxiRequestMoscow, priceOff)
+	assert.Nil(t, err, "replaced service still serves requests started before the swap")
+	assert.Equal(t, 100, resp.Result.Optimal.Results[0].Price)
+}
+
+func TestReloadableReplace(t *testing.T) {
+	build := func(quoteTTL time.Duration) *Service {
+		return NewBuilder().
+			WithAPIs([]APIDataGetter{
+				newMockAPIDataGetter(nil, "test1", APIData{PriceMean: 100}),
+				newMockAPIDataGetter(nil, "test2", APIData{PriceMean: 200}),
+			}).
+			WithProductCache(newMockProductCache(nil, product.Product{ProviderName: "test1"}, product.Product{ProviderName: "test2"})).
+			WithRequester(testHTTPRequester).
+			WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+			WithAddressSrv(newMockAddressService(nil, "")).
+			WithStatCollector(testCollector).
+			WithLogger(testLogger).
+			WithQuoteCache(QuoteCacheSettings{TTL: duration.Milliseconds(quoteTTL)}).
+			WithRouteSettings(RouteSettings{TTL: duration.Milliseconds(time.Minute)}).
+			Build()
+	}
+	old := build(time.Minute)
+	for i := 0; i < DefaultBreakerSettings.ConsecutiveErrors; i++ {
+		old.breakers["test1"].Done(breakerFailure)
+	}
+	old.quoteCache.put(testTaxiRequestMoscow, "test2", []APIData{{PriceMean: 200}})
+	old.routeCache.put(testTaxiRequestMoscow, 1000, 2000)
+	srv := NewReloadable(old)
+
+	same := build(time.Minute)
+	srv.Replace(same)
+	assert.Equal(t, map[string]string{"test1": "open", "test2": "closed"}, srv.BreakerStates(), "breaker state survives reload")
+	_, ok := same.quoteCache.get(testTaxiRequestMoscow, "test2")
+	assert.True(t, ok, "quote cache survives reload")
+	_, _, ok = same.routeCache.get(testTaxiRequestMoscow)
+	assert.True(t, ok, "route cache survives reload")
+	assert.True(t, same.batchLimit == old.batchLimit, "batch limits are shared with in-flight batches")
+
+	changed := build(2 * time.Minute)
+	srv.Replace(changed)
+	assert.Equal(t, "open", srv.BreakerStates()["test1"])
+	_, ok = changed.quoteCache.get(testTaxiRequestMoscow, "test2")
+	assert.False(t, ok, "quote cache with changed settings starts empty")
+	_, _, ok = changed.routeCache.get(testTaxiRequestMoscow)
+	assert.True(t, ok)
+}
@@ -1,19 +1,23 @@
 package service
 
 import (
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/nburunova/taxi-backend-sample/src/webapi"
 )
 
 // Point - струтура для точки
 type Point struct {
-	Lat     float64 `json:"lat"`
-	LatStr  string
-	Lon     float64 `json:"lon"`
-	LonStr  string
-	Address string
-	Area    string
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+	// Address - адрес от клиента, остается в запросе, если WebAPI адрес не вернул
+	Address string `json:"address"`
+	// LatStr, LonStr, Area заполняет сервис, клиент их не передает
+	LatStr string `json:"-"`
+	LonStr string `json:"-"`
+	Area   string `json:"-"`
 }
 
 func (p *Point) stringfy() {
@@ -41,9 +45,66 @@ func (p *Point) AddArea(area string) {
 
 // Request - струтура, описывающая запрос к сервису такси
 type Request struct {
-	ReqID    string
-	RegionID int   `json:"region_id" json:"req_region"`
-	Point1   Point `json:"point1" json:"point1"`
-	Point2   Point `json:"point2" json:"point2"`
-	OnlyAPI  bool  `json:"only_api"`
+	ReqID    string `json:"-"`
+	RegionID int    `json:"region_id"`
+	Point1   Point  `json:"point1"`
+	Point2   Point  `json:"point2"`
+	// Waypoints - промежуточные остановки между Point1 и Point2 в порядке объезда
+	Waypoints []Point `json:"waypoints"`
+	// OnlyAPI - облегченный режим: без WebAPI и Moses, в ответе только цены провайдеров без ссылок на приложения
+	OnlyAPI bool `json:"only_api"`
+	// PickupTime - время подачи для предварительного заказа; пусто - поездка сейчас
+	PickupTime *time.Time `json:"pickup_time"`
+	// UserID - идентификатор пользователя, чтобы случайный выбор оптимального варианта был для него постоянным
+	UserID string `json:"user_id"`
+	// WithProviders - добавить в ответ статусы провайдеров
+	WithProviders bool `json:"with_providers"`
+	// Warnings - пропущенные шаги обогащения запроса, попадают в meta ответа
+	Warnings []string `json:"-"`
+	// Variant - вариант эксперимента, в который попал запрос; nil - запрос вне эксперимента
+	Variant *Variant `json:"-"`
+}
+
+// Режимы запроса, метка mode в метриках
+const (
+	// ModeFull - запрос обогащается адресами и маршрутом, в ответе ссылки на приложения
+	ModeFull = "full"
+	// ModeOnlyAPI - облегченный режим only_api: только цены провайдеров
+	ModeOnlyAPI = "only_api"
+	// ModeBatch - пакет запросов целиком, у запросов пакета режимы свои
+	ModeBatch = "batch"
+)
+
+// Mode - режим запроса
+func (r Request) Mode() string {
+	if r.OnlyAPI {
+		return ModeOnlyAPI
+	}
+	return ModeFull
+}
+
+// IsScheduled - запрос на предварительный заказ
+func (r Request) IsScheduled() bool {
+	return r.PickupTime != nil
+}
+
+// Route - все точки маршрута по порядку: отправление, остановки, назначение
+func (r Request) Route() []Point {
+	points := make([]Point, 0, len(r.Waypoints)+2)
+	points = append(points, r.Point1)
+	points = append(points, r.Waypoints...)
+	return append(points, r.Point2)
+}
+
+// WaypointTemplateVars - переменные шаблона для остановок: %waypoint1.lat%, %waypoint1.lon%, %waypoint1.address%, ...
+// Нумерация с единицы в порядке объезда
+func (r Request) WaypointTemplateVars() map[string]string {
+	vars := make(map[string]string, len(r.Waypoints)*3)
+	for i, p := range r.Waypoints {
+		prefix := fmt.Sprintf("%%waypoint%d.", i+1)
+		vars[prefix+"lat%"] = p.LatStr
+		vars[prefix+"lon%"] = p.LonStr
+		vars[prefix+"address%"] = p.Address
+	}
+	return vars
 }
@@ -3,31 +3,34 @@ package service
 // Response - струтура, описывающая формат ответа сервиса
 type Response struct {
 	Result taxiResult `json:"results"`
-	Meta   *meta      `json:"meta,omitempty"`
+	Meta   *Meta      `json:"meta,omitempty"`
+	// Providers - статусы опрошенных провайдеров, только если клиент попросил with_providers
+	Providers []ProviderStatus `json:"providers,omitempty"`
 }
 
 type taxiResult struct {
 	ID      int          `json:"id"`
-	Optimal *resultBlock `json:"optimal,omitempty"`
-	Else    *resultBlock `json:"else,omitempty"`
+	Optimal *ResultBlock `json:"optimal,omitempty"`
+	Else    *ResultBlock `json:"else,omitempty"`
 }
 
-type resultBlock struct {
-	Title   string          `json:"title"`
-	Summary string          `json:"summary"`
-	Results []serviceRecord `json:"results"`
+// ResultBlock - блок предложений ответа: оптимальные или остальные
+type ResultBlock struct {
+	Title   string   `json:"title"`
+	Summary string   `json:"summary"`
+	Results []Record `json:"results"`
 }
 
-func newResponse(m *meta, optimal, elses []serviceRecord) *Response {
-	var optimalResBlock, elsesResBlock *resultBlock
+func newResponse(m *Meta, optimal, elses []Record) *Response {
+	var optimalResBlock, elsesResBlock *ResultBlock
 	if optimal != nil && len(optimal) > 0 {
-		optimalResBlock = &resultBlock{
+		optimalResBlock = &ResultBlock{
 			Summary: "Оптимальный выбор с учётом рейтинга перевозчика и популярности",
 			Results: optimal,
 		}
 	}
 	if elses != nil && len(elses) > 0 {
-		elsesResBlock = &resultBlock{
+		elsesResBlock = &ResultBlock{
 			Results: elses,
 		}
 	}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"math"
+
+	"github.com/golang/geo/s2"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/ttlcache"
+	"github.com/pkg/errors"
+)
+
+const (
+	// RouteRouted - расстояние и время посчитал Моисей
+	RouteRouted = "routed"
+	// RouteCached - расстояние и время взяты из кэша недавних ответов Моисея
+	RouteCached = "cached"
+	// RouteEstimated - Моисей недоступен, расстояние и время оценены по прямой
+	RouteEstimated = "estimated"
+
+	// defaultRouteCacheLevel - уровень S2 ячейки по умолчанию, ~150 метров
+	defaultRouteCacheLevel = 16
+	// defaultRouteCacheSize - сколько маршрутов храним по умолчанию
+	defaultRouteCacheSize = 10000
+	// earthRadius - средний радиус Земли в метрах
+	earthRadius = 6371010.0
+)
+
+var (
+	// ErrRouteEstimate - нет настроек для оценки маршрута без Моисея
+	ErrRouteEstimate = errors.New("Cannot estimate route without Moses")
+)
+
+// RouteEstimate - параметры оценки маршрута по прямой
+type RouteEstimate struct {
+	// DetourFactor - во сколько раз путь по дорогам длиннее расстояния по прямой
+	DetourFactor float64 `json:"detour_factor"`
+	// AvgSpeed - средняя скорость, км/ч
+	AvgSpeed float64 `json:"avg_speed_kmh"`
+}
+
+// RouteSettings - настройки кэша ответов Моисея и оценки маршрута, когда Моисей недоступен
+type RouteSettings struct {
+	// Level - уровень S2 ячейки, к которой привязываем точки маршрута
+	Level int `json:"s2_level"`
+	// MaxEntries - максимальное количество маршрутов в кэше
+	MaxEntries int `json:"max_entries"`
+	// TTL - время жизни ответа Моисея в кэше; 0 - не кэшируем
+	TTL duration.Milliseconds `json:"ttl_ms"`
+	// Estimate - оценка маршрута по умолчанию; без AvgSpeed маршрут не оцениваем
+	Estimate RouteEstimate `json:"estimate"`
+	// Regions - оценка маршрута по коду региона
+	Regions map[int]RouteEstimate `json:"regions"`
+}
+
+// estimate - параметры оценки для региона; настройки региона дополняются общими
+func (rs RouteSettings) estimate(regionID int) RouteEstimate {
+	est := rs.Estimate
+	if regional, ok := rs.Regions[regionID]; ok {
+		if regional.DetourFactor > 0 {
+			est.DetourFactor = regional.DetourFactor
+		}
+		if regional.AvgSpeed > 0 {
+			est.AvgSpeed = regional.AvgSpeed
+		}
+	}
+	if est.DetourFactor <= 0 {
+		est.DetourFactor = 1
+	}
+	return est
+}
+
+// estimateRoute - расстояние (м) и время (мин) по расстоянию по прямой между точками маршрута,
+// умноженному на коэффициент извилистости
+func (rs RouteSettings) estimateRoute(taxiReq Request) (int, int, error) {
+	est := rs.estimate(taxiReq.RegionID)
+	if est.AvgSpeed <= 0 {
+		return 0, 0, errors.Wrapf(ErrRouteEstimate, "region %v", taxiReq.RegionID)
+	}
+	var distance float64
+	route := taxiReq.Route()
+	for i := 1; i < len(route); i++ {
+		from := s2.LatLngFromDegrees(route[i-1].Lat, route[i-1].Lon)
+		to := s2.LatLngFromDegrees(route[i].Lat, route[i].Lon)
+		distance += from.Distance(to).Radians() * earthRadius * est.DetourFactor
+	}
+	minutes := distance / 1000 / est.AvgSpeed * 60
+	return int(math.Round(distance)), int(math.Ceil(minutes)), nil
+}
+
+type routeKey struct {
+	regionID int
+	from     s2.CellID
+	to       s2.CellID
+	stops    string
+}
+
+type routeEntry struct {
+	distance int
+	time     int
+}
+
+// routeCache - LRU кэш ответов Моисея с TTL
+type routeCache struct {
+	settings RouteSettings
+	cache    *ttlcache.Cache
+}
+
+func newRouteCache(settings RouteSettings) *routeCache {
+	if settings.TTL <= 0 {
+		return nil
+	}
+	settings.Level = ttlcache.CellLevel(settings.Level, defaultRouteCacheLevel)
+	if settings.MaxEntries <= 0 {
+		settings.MaxEntries = defaultRouteCacheSize
+	}
+	return &routeCache{
+		settings: settings,
+		cache:    ttlcache.New(settings.MaxEntries, nil),
+	}
+}
+
+func (rc *routeCache) key(taxiReq Request) routeKey {
+	return routeKey{
+		regionID: taxiReq.RegionID,
+		from:     ttlcache.Cell(taxiReq.Point1.Lat, taxiReq.Point1.Lon, rc.settings.Level),
+		to:       ttlcache.Cell(taxiReq.Point2.Lat, taxiReq.Point2.Lon, rc.settings.Level),
+		stops:    waypointCells(taxiReq.Waypoints, rc.settings.Level),
+	}
+}
+
+func (rc *routeCache) get(taxiReq Request) (int, int, bool) {
+	if rc == nil || taxiReq.IsScheduled() {
+		return 0, 0, false
+	}
+	value, ok := rc.cache.Get(rc.key(taxiReq))
+	if !ok {
+		return 0, 0, false
+	}
+	entry := value.(routeEntry)
+	return entry.distance, entry.time, true
+}
+
+// put - сохраняем ответ Моисея. Маршруты предварительных заказов посчитаны на другое время суток, их не кэшируем
+func (rc *routeCache) put(taxiReq Request, distance, time int) {
+	if rc == nil || taxiReq.IsScheduled() {
+		return
+	}
+	rc.cache.Put(rc.key(taxiReq), routeEntry{distance: distance, time: time}, rc.settings.TTL.Duration())
+}
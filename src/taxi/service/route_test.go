@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/product"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var testRouteSettings = RouteSettings{
+	TTL: duration.Milliseconds(time.Minute),
+	Estimate: RouteEstimate{
+		DetourFactor: 1.4,
+		AvgSpeed:     25,
+	},
+	Regions: map[int]RouteEstimate{
+		32: RouteEstimate{DetourFactor: 1.3, AvgSpeed: 22},
+	},
+}
+
+func TestRouteSettingsUnmarshal(t *testing.T) {
+	var rs RouteSettings
+	err := json.Unmarshal([]byte(`{"ttl_ms": 60000, "estimate": {"detour_factor": 1.4, "avg_speed_kmh": 25}, "regions": {"32": {"detour_factor": 1.3, "avg_speed_kmh": 22}}}`), &rs)
+	assert.Nil(t, err)
+	assert.Equal(t, testRouteSettings, rs)
+}
+
+func TestEstimateRoute(t *testing.T) {
+	dist, tm, err := testRouteSettings.estimateRoute(testTaxiRequestMoscow)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1681, dist, 1)
+	assert.Equal(t, 5, tm)
+
+	other := testTaxiRequestMoscow
+	other.RegionID = 1
+	dist, tm, err = testRouteSettings.estimateRoute(other)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1811, dist, 1)
+	assert.Equal(t, 5, tm)
+
+	_, _, err = RouteSettings{}.estimateRoute(testTaxiRequestMoscow)
+	assert.Equal(t, ErrRouteEstimate, errors.Cause(err))
+}
+
+func newRouteTestService(distTimeSrv DistanceTimeService, rs RouteSettings) *Service {
+	return NewBuilder().
+		WithAPIs([]APIDataGetter{newMockAPIDataGetter(nil, "test1", APIData{PriceMean: 100})}).
+		WithProductCache(newMockProductCache(nil, product.Product{ProviderName: "test1", Name: "test1"})).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(distTimeSrv).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		WithRouteSettings(rs).
+		Build()
+}
+
+func TestServiceRouteSource(t *testing.T) {
+	req := fullMode(testTaxiRequestMoscow)
+	service := newRouteTestService(newMockDistanceTimeService(nil, 1000, 2000), testRouteSettings)
+	resp, err := service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, RouteRouted, resp.Meta.Source)
+	resp, err = service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, RouteCached, resp.Meta.Source)
+	assert.Equal(t, 1000, *resp.Meta.Distance)
+
+	service = newRouteTestService(newMockDistanceTimeService(ErrMosesEmptyResult, 0, 0), testRouteSettings)
+	resp, err = service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, RouteEstimated, resp.Meta.Source)
+	assert.InDelta(t, 1681, *resp.Meta.Distance, 1)
+
+	service = newRouteTestService(newMockDistanceTimeService(ErrMosesEmptyResult, 0, 0), RouteSettings{})
+	resp, err = service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Meta.Distance)
+	assert.Equal(t, []string{WarningRouting}, resp.Meta.Warnings)
+}
+
+func TestEstimateRouteWaypoints(t *testing.T) {
+	direct, _, err := testRouteSettings.estimateRoute(testTaxiRequestMoscow)
+	assert.Nil(t, err)
+	withStop, tm, err := testRouteSettings.estimateRoute(testTaxiRequestWaypoints)
+	assert.Nil(t, err)
+	assert.True(t, withStop >= direct)
+	assert.InDelta(t, 1682, withStop, 1)
+	assert.Equal(t, 5, tm)
+
+	detour := testTaxiRequestWaypoints
+	detour.Waypoints = []Point{{Lat: 55.74, Lon: 37.60}}
+	withDetour, _, err := testRouteSettings.estimateRoute(detour)
+	assert.Nil(t, err)
+	assert.True(t, withDetour > direct)
+}
+
+func TestRouteCacheWaypoints(t *testing.T) {
+	rc := newRouteCache(testRouteSettings)
+	rc.put(testTaxiRequestMoscow, 1000, 5)
+	_, _, ok := rc.get(testTaxiRequestWaypoints)
+	assert.False(t, ok, "route with stops is not the direct route")
+	rc.put(testTaxiRequestWaypoints, 1500, 7)
+	dist, _, ok := rc.get(testTaxiRequestWaypoints)
+	assert.True(t, ok)
+	assert.Equal(t, 1500, dist)
+}
@@ -1,11 +1,8 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"sort"
 	"strings"
@@ -14,6 +11,7 @@ import (
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/pkg/errors"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/apierror"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/collector"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
@@ -32,6 +30,23 @@ var (
 	ErrInvalidTime = errors.New("Invalid time")
 	// ErrRegionOutOfService - провайдер не обслуживает запрошенный регион
 	ErrRegionOutOfService = errors.New("Provider does not service this region")
+	// ErrCircuitOpen - провайдер не опрашивается, т.к. его circuit breaker разомкнут
+	ErrCircuitOpen = errors.New("Provider circuit breaker is open")
+	// ErrTooManyWaypoints - в запросе больше промежуточных остановок, чем MaxWaypoints
+	ErrTooManyWaypoints = errors.New("Too many waypoints in taxi request")
+	// ErrWaypointEmpty - у промежуточной остановки нет координат
+	ErrWaypointEmpty = errors.New("Taxi request waypoint is empty")
+	// ErrPickupTimeInPast - время подачи предварительного заказа уже прошло
+	ErrPickupTimeInPast = errors.New("Pickup time is in the past")
+	// ErrPickupTimeTooFar - время подачи предварительного заказа дальше MaxPreOrderHorizon
+	ErrPickupTimeTooFar = errors.New("Pickup time is too far in the future")
+)
+
+const (
+	// MaxWaypoints - сколько промежуточных остановок можно указать в запросе
+	MaxWaypoints = 5
+	// MaxPreOrderHorizon - на сколько вперед можно оценить предварительный заказ
+	MaxPreOrderHorizon = 7 * 24 * time.Hour
 )
 
 // APIData - cтрутура описывает, в каком формате ожидаем ответ от провайдеров
@@ -44,8 +59,22 @@ type APIData struct {
 	PriceMean    float64
 	Eta          int
 	TemplateVars map[string]string
+	// SurgeMultiplier - повышающий коэффициент провайдера; 0 - провайдер его не сообщает
+	SurgeMultiplier float64
+	// MinimumFare - минимальная стоимость поездки по данным провайдера
+	MinimumFare int
+	// Currency - валюта цены по данным провайдера
+	Currency string
+	// Distance - длина маршрута по данным провайдера, метры
+	Distance int
+	// Duration - время в пути по данным провайдера, минуты
+	Duration int
 }
 
+// OrderIDVar - переменная шаблона диплинка с идентификатором заказа у провайдера. Он уникален для запроса,
+// поэтому такие ответы не кэшируются
+const OrderIDVar = "%order.id%"
+
 // ByProductID - упорядочиваем список APIData по возрастанию product id
 type ByProductID []APIData
 
@@ -59,6 +88,46 @@ type APIDataGetter interface {
 	APIName() string
 }
 
+// WaypointsSupporter - провайдер, который умеет считать цену маршрута с промежуточными остановками.
+// Провайдеры без этого интерфейса для маршрутов с остановками не опрашиваются
+type WaypointsSupporter interface {
+	SupportsWaypoints() bool
+}
+
+// PreOrderSupporter - провайдер, который умеет считать цену предварительного заказа.
+// Провайдеры без этого интерфейса для запросов с временем подачи не опрашиваются
+type PreOrderSupporter interface {
+	SupportsPreOrder() bool
+}
+
+// unsupportedStatus - статус провайдера, который не умеет считать такой запрос; пусто - умеет
+func unsupportedStatus(taxiReq Request, taxiAPI APIDataGetter) string {
+	if len(taxiReq.Waypoints) != 0 {
+		supporter, ok := taxiAPI.(WaypointsSupporter)
+		if !ok || !supporter.SupportsWaypoints() {
+			return ProviderWaypointsUnsupported
+		}
+	}
+	if taxiReq.IsScheduled() {
+		supporter, ok := taxiAPI.(PreOrderSupporter)
+		if !ok || !supporter.SupportsPreOrder() {
+			return ProviderPreOrderUnsupported
+		}
+	}
+	return ""
+}
+
+// unsupportedStatuses - статусы провайдеров, которые не опрашивались, потому что не умеют считать запрос
+func unsupportedStatuses(statuses []ProviderStatus) []ProviderStatus {
+	var unsupported []ProviderStatus
+	for _, status := range statuses {
+		if status.Status == ProviderWaypointsUnsupported || status.Status == ProviderPreOrderUnsupported {
+			unsupported = append(unsupported, status)
+		}
+	}
+	return unsupported
+}
+
 // ProductsCache - интерфейс для работы с хранилищем продуктов
 type ProductsCache interface {
 	GetProducts(int) ([]product.Product, error)
@@ -79,12 +148,25 @@ type AddressService interface {
 // Service - струтура для сервиса такси
 type Service struct {
 	apisMap     map[string]APIDataGetter
+	breakers    map[string]*circuitBreaker
+	requesters  map[string]*httprequester.Requester
+	quoteCache  *quoteCache
+	routeSett   RouteSettings
+	routeCache  *routeCache
+	placeholder string
+	maxSurge    float64
+	ranker      Ranker
+	rankers     map[int]Ranker
+	experiment  *experiment
+	batch       BatchSettings
+	batchLimit  *batchLimiter
 	prodCache   ProductsCache
 	httpreq     *httprequester.Requester
 	distTimeSrv DistanceTimeService
 	addrSrv     AddressService
 	collector   *collector.Collector
 	Logger      *log.StructuredLogger
+	now         func() time.Time
 }
 
 // IsOK - проверяем, работоспособен ли сервис
@@ -98,23 +180,75 @@ func (s *Service) IsOK() bool {
 	return true
 }
 
-func (s *Service) requestOne(ctx context.Context, wg *sync.WaitGroup, taxiReq Request, taxiAPI APIDataGetter, prod product.Product, mutex *sync.Mutex, result *[]serviceRecord) {
-	defer wg.Done()
-	apiCtx := context.WithValue(ctx, log.CtxKeyAPIName, taxiAPI.APIName())
+// BreakerStates - состояния circuit breaker всех провайдеров
+func (s *Service) BreakerStates() map[string]string {
+	states := make(map[string]string, len(s.breakers))
+	for name, breaker := range s.breakers {
+		states[name] = breaker.State().String()
+	}
+	return states
+}
+
+// requesterFor - реквестер с политикой запросов провайдера, если она задана, иначе общий
+func (s *Service) requesterFor(apiName string) *httprequester.Requester {
+	if httpreq, ok := s.requesters[apiName]; ok {
+		return httpreq
+	}
+	return s.httpreq
+}
+
+// fetchAPIData - запрашиваем провайдера, если его circuit breaker замкнут; успешный ответ кладем в кэш.
+// Если цепь разомкнута, провайдера не опрашиваем и возвращаем ErrCircuitOpen
+func (s *Service) fetchAPIData(apiCtx context.Context, taxiReq Request, taxiAPI APIDataGetter) ([]APIData, error) {
+	release, errWait := s.acquireProvider(apiCtx, taxiAPI.APIName())
+	if errWait != nil {
+		s.Logger.ServiceWarningLogEntry(apiCtx, errWait, "request API", taxiAPI.APIName())
+		return nil, errWait
+	}
+	defer release()
+	breaker := s.breakers[taxiAPI.APIName()]
+	if breaker != nil && !breaker.Allow() {
+		err := errors.Wrap(ErrCircuitOpen, taxiAPI.APIName())
+		s.Logger.ServiceWarningLogEntry(apiCtx, err, "request API", taxiAPI.APIName())
+		return nil, err
+	}
 	start := time.Now()
-	taxiData, err := taxiAPI.GetAPIData(apiCtx, s.httpreq, taxiReq)
+	taxiData, err := taxiAPI.GetAPIData(apiCtx, s.requesterFor(taxiAPI.APIName()), taxiReq)
 	elapsed := float64(time.Since(start).Nanoseconds()) / 1000000
+	if breaker != nil {
+		breaker.Done(breakerResultByErr(err))
+	}
 	if err != nil {
 		s.Logger.ServiceWarningLogEntry(apiCtx, errors.Wrap(err, taxiAPI.APIName()), "request API", taxiAPI.APIName())
-		if strings.Contains(err.Error(), ErrInvalidPrice.Error()) {
-			s.collector.AddProviderInvalidValueResponse(taxiAPI.APIName(), "price", taxiReq.RegionID)
+		s.collector.AddProviderFailure(taxiAPI.APIName(), err, taxiReq.RegionID)
+		if hasProviderErrorKind(err, apierror.KindInvalidPrice) {
+			s.collector.AddProviderInvalidValueResponse(taxiAPI.APIName(), stagePrice, taxiReq.RegionID)
 		}
-		if strings.Contains(err.Error(), ErrInvalidTime.Error()) {
-			s.collector.AddProviderInvalidValueResponse(taxiAPI.APIName(), "time", taxiReq.RegionID)
+		if hasProviderErrorKind(err, apierror.KindInvalidTime) {
+			s.collector.AddProviderInvalidValueResponse(taxiAPI.APIName(), stageTime, taxiReq.RegionID)
 		}
+	} else {
+		s.quoteCache.put(taxiReq, taxiAPI.APIName(), taxiData)
 	}
 	s.collector.AddProviderResponseTime(taxiAPI.APIName(), "all", elapsed)
 	s.collector.AddProviderOKResponse(taxiAPI.APIName(), taxiReq.RegionID)
+	return taxiData, err
+}
+
+func (s *Service) requestOne(ctx context.Context, wg *sync.WaitGroup, taxiReq Request, taxiAPI APIDataGetter, prod product.Product, res *fanoutResult) {
+	defer wg.Done()
+	start := time.Now()
+	apiCtx := context.WithValue(ctx, log.CtxKeyAPIName, taxiAPI.APIName())
+	var err error
+	taxiData, ok := s.quoteCache.get(taxiReq, taxiAPI.APIName())
+	if !ok {
+		taxiData, err = s.fetchAPIData(apiCtx, taxiReq, taxiAPI)
+		if errors.Cause(err) == ErrCircuitOpen {
+			res.add(newProviderStatus(taxiAPI.APIName(), ProviderCircuitOpen, start), nil)
+			return
+		}
+	}
+	taxiData = s.withAddressPlaceholder(taxiData)
 	filteredTaxiData := make([]APIData, 0)
 	for _, tData := range taxiData {
 		if prod.IsGoodTariff(tData.TariffName) {
@@ -124,75 +258,69 @@ func (s *Service) requestOne(ctx context.Context, wg *sync.WaitGroup, taxiReq Re
 	if len(filteredTaxiData) == 0 && len(taxiData) != 0 {
 		s.Logger.ServiceWarningLogEntry(apiCtx, errors.Wrap(ErrNoAPIData, taxiAPI.APIName()), "fitlered all tariffs", taxiAPI.APIName())
 		s.collector.AddFilterError(taxiAPI.APIName(), taxiReq.RegionID)
+		res.add(newProviderStatus(taxiAPI.APIName(), ProviderFilteredOut, start), nil)
 		return
 	}
+	records := make([]Record, 0, len(filteredTaxiData))
 	for _, tData := range filteredTaxiData {
-		serviceRecord, err := newServiceRecord(tData, prod)
+		serviceRecord, err := newServiceRecord(tData, prod, taxiReq.OnlyAPI)
 		if err != nil {
 			s.Logger.Warning(errors.Wrap(err, "Error when creating service record, not blocking"))
 		}
-		mutex.Lock()
-		*result = append(*result, serviceRecord)
-		mutex.Unlock()
+		records = append(records, serviceRecord)
 	}
-	return
+	status := ProviderOK
+	if len(records) == 0 {
+		status = providerStatusByErr(err)
+	}
+	res.add(newProviderStatus(taxiAPI.APIName(), status, start), records)
 }
 
-func (s *Service) requestProviders(ctx context.Context, taxiReq Request, prods []product.Product) ([]serviceRecord, error) {
-	serviceRecords := make([]serviceRecord, 0)
+func (s *Service) requestProviders(ctx context.Context, taxiReq Request, prods []product.Product, notify recordsNotifier) ([]Record, []ProviderStatus, error) {
+	res := &fanoutResult{
+		records:  make([]Record, 0),
+		statuses: make([]ProviderStatus, 0),
+		notify:   notify,
+	}
 	var wg sync.WaitGroup
-	var mutex sync.Mutex
 	for _, prod := range prods {
 		taxiAPI, ok := s.apisMap[prod.ProviderName]
-		if !ok {
+		if !ok || !taxiReq.Variant.allowsProvider(taxiAPI.APIName()) {
+			continue
+		}
+		if status := unsupportedStatus(taxiReq, taxiAPI); status != "" {
+			res.add(newProviderStatus(taxiAPI.APIName(), status, time.Now()), nil)
 			continue
 		}
 		wg.Add(1)
-		go s.requestOne(ctx, &wg, taxiReq, taxiAPI, prod, &mutex, &serviceRecords)
+		go s.requestOne(ctx, &wg, taxiReq, taxiAPI, prod, res)
 	}
 	wg.Wait()
-	if len(serviceRecords) == 0 {
-		return serviceRecords, errors.Wrap(ErrNoAPIData, "All providers")
+	sort.Sort(byProviderName(res.statuses))
+	if len(res.records) == 0 {
+		return res.records, res.statuses, WithErrorClass(errors.Wrap(ErrNoAPIData, "All providers"), noRecordsClass(ctx, res.statuses))
 	}
-	return serviceRecords, nil
+	return res.records, res.statuses, nil
 }
 
-func (s *Service) getOptimalElse(records []serviceRecord, isOptimalInRegion bool, priceCoeff float64) ([]serviceRecord, []serviceRecord) {
+func (s *Service) getOptimalElse(req Request, records []Record, isOptimalInRegion bool, priceCoeff float64) ([]Record, []Record) {
 	var optimalCandidateInd = -1
 
 	sort.Sort(byPrice(records))
-
-	if isOptimalInRegion {
-		for ind, rec := range records {
-			if rec.Operator.IsOptimal && isOptimalInRegion {
-				optimalCandidateInd = ind
-				break
-			}
-		}
+	ranker := s.rankerFor(req)
+	candidates := s.optimalCandidates(records)
+	candidateRecords := make([]Record, 0, len(candidates))
+	for _, ind := range candidates {
+		candidateRecords = append(candidateRecords, records[ind])
 	}
-
-	if !isOptimalInRegion {
-		var suitableCandidates int
-		priceLimit := float64(records[0].Price) * priceCoeff
-		for k := range records {
-			if float64(records[k].Price) <= priceLimit {
-				suitableCandidates = k
-				continue
-			}
-			break
-		}
-		var randInd int
-		if suitableCandidates > 0 {
-			s := rand.NewSource(time.Now().Unix())
-			r := rand.New(s) // initialize local pseudorandom generator
-			randInd = r.Intn(suitableCandidates + 1)
-		} else {
-			randInd = 0
-		}
-		optimalCandidateInd = randInd
+	if ind := ranker.Rank(req, candidateRecords, isOptimalInRegion, priceCoeff); ind >= 0 {
+		optimalCandidateInd = candidates[ind]
+	} else if len(candidates) < len(records) {
+		// среди вариантов без повышающего коэффициента стратегия ничего не выбрала - выбираем из всех
+		optimalCandidateInd = ranker.Rank(req, records, isOptimalInRegion, priceCoeff)
 	}
 
-	elses := make([]serviceRecord, 0)
+	elses := make([]Record, 0)
 
 	for ind, rec := range records {
 		if ind == optimalCandidateInd {
@@ -205,15 +333,44 @@ func (s *Service) getOptimalElse(records []serviceRecord, isOptimalInRegion bool
 		return nil, elses
 	}
 
-	return []serviceRecord{records[optimalCandidateInd]}, elses
+	return []Record{records[optimalCandidateInd]}, elses
+}
+
+// rankerFor - стратегия выбора оптимального варианта: варианта эксперимента, если она задана, иначе региона
+func (s *Service) rankerFor(req Request) Ranker {
+	if req.Variant != nil && req.Variant.ranker != nil {
+		return req.Variant.ranker
+	}
+	if ranker, ok := s.rankers[req.RegionID]; ok {
+		return ranker
+	}
+	return s.ranker
+}
+
+// optimalCandidates - индексы записей, из которых выбираем оптимальную, по возрастанию цены.
+// Если задан maxSurge, записи с повышающим коэффициентом выше него в кандидаты не попадают,
+// пока есть хотя бы одна запись без такого коэффициента
+func (s *Service) optimalCandidates(records []Record) []int {
+	all := make([]int, 0, len(records))
+	calm := make([]int, 0, len(records))
+	for ind, rec := range records {
+		all = append(all, ind)
+		if s.maxSurge <= 0 || rec.surge() <= s.maxSurge {
+			calm = append(calm, ind)
+		}
+	}
+	if len(calm) == 0 {
+		return all
+	}
+	return calm
 }
 
-func (s *Service) getServiceRecords(ctx context.Context, wg *sync.WaitGroup, req Request, priceCoeff float64, optimal *[]serviceRecord, elses *[]serviceRecord, errResult *error) {
+func (s *Service) getServiceRecords(ctx context.Context, wg *sync.WaitGroup, req Request, priceCoeff float64, optimal *[]Record, elses *[]Record, statuses *[]ProviderStatus, errResult *error, notify recordsNotifier) {
 	defer wg.Done()
 	start := time.Now()
 	prods, errProds := s.prodCache.GetProducts(req.RegionID)
 	if errProds != nil {
-		*errResult = errors.Wrap(errProds, "Products not found for region")
+		*errResult = WithErrorClass(errors.Wrap(errProds, "Products not found for region"), ErrClassRegionNotServed)
 		return
 	}
 	elapsed := float64(time.Since(start).Nanoseconds()) / 1000000
@@ -229,7 +386,8 @@ func (s *Service) getServiceRecords(ctx context.Context, wg *sync.WaitGroup, req
 	elapsed = float64(time.Since(start).Nanoseconds()) / 1000000
 	s.Logger.TimingLogEntry(ctx, elapsed, "Filter prods")
 	start = time.Now()
-	serviceRecords, errProviders := s.requestProviders(ctx, req, prods)
+	serviceRecords, providerStatuses, errProviders := s.requestProviders(ctx, req, prods, notify)
+	*statuses = providerStatuses
 	if errProviders != nil {
 		*errResult = errors.Wrap(errProviders, "Error when request taxiAPIs")
 		return
@@ -237,48 +395,74 @@ func (s *Service) getServiceRecords(ctx context.Context, wg *sync.WaitGroup, req
 	elapsed = float64(time.Since(start).Nanoseconds()) / 1000000
 	s.Logger.TimingLogEntry(ctx, elapsed, "Request providers")
 
-	optimalLocal, elsesLocal := s.getOptimalElse(serviceRecords, isOptimalInRegion, priceCoeff)
+	optimalLocal, elsesLocal := s.getOptimalElse(req, serviceRecords, isOptimalInRegion, req.Variant.PriceCoeffOr(priceCoeff))
 
 	*optimal = optimalLocal
 	*elses = elsesLocal
 	return
 }
 
-func (s *Service) getMeta(ctx context.Context, wg *sync.WaitGroup, taxiReq Request, result *meta, errResult *error) {
+func (s *Service) getMeta(ctx context.Context, wg *sync.WaitGroup, taxiReq Request, result *Meta, errResult *error) {
 	defer wg.Done()
+	// в режиме only_api маршрут не считаем
+	if taxiReq.OnlyAPI {
+		return
+	}
+	if dist, tm, ok := s.routeCache.get(taxiReq); ok {
+		*result = newMeta(dist, tm, RouteCached)
+		return
+	}
 	mosesCtx := context.WithValue(ctx, log.CtxKeyAPIName, "moses")
 	dist, tm, errMoses := s.distTimeSrv.DistanceTime(mosesCtx, s.httpreq, taxiReq)
-	if errMoses != nil {
+	if errMoses == nil {
+		s.routeCache.put(taxiReq, dist, tm)
+		*result = newMeta(dist, tm, RouteRouted)
+		return
+	}
+	dist, tm, errEstimate := s.routeSett.estimateRoute(taxiReq)
+	if errEstimate != nil {
 		*errResult = errors.Wrap(errMoses, "Error when requesting Moses")
 		return
 	}
-	*result = newMeta(dist, tm)
+	s.Logger.ServiceWarningLogEntry(ctx, errors.Wrap(errMoses, "Route is estimated"), "Error when requesting Moses", "moses")
+	s.collector.AddServiceError("moses", taxiReq.Mode(), taxiReq.RegionID)
+	*result = newMeta(dist, tm, RouteEstimated)
 }
 
-// Response - возвращает ответ с данными от провайдеров такси
+// Response - возвращает ответ с данными от провайдеров такси. Это StreamResponse,
+// из событий которого берется только итоговый ответ
 func (s *Service) Response(ctx context.Context, req Request, priceCoeff float64) (*Response, error) {
-	var errRecords, errMeta error
-	var optimal, elses []serviceRecord
-	var m meta
-	var wg sync.WaitGroup
 	response := new(Response)
-	wg.Add(2)
-	go s.getServiceRecords(ctx, &wg, req, priceCoeff, &optimal, &elses, &errRecords)
-	go s.getMeta(ctx, &wg, req, &m, &errMeta)
-	wg.Wait()
-	if errRecords != nil {
-		s.Logger.ServiceWarningLogEntry(ctx, errRecords, "Empty data from providers", "provider")
-		s.collector.AddServiceError("providers_empty", req.RegionID)
-		return response, errors.Wrap(errRecords, "Empty data from providers")
-	}
+	err := s.StreamResponse(ctx, req, priceCoeff, func(event StreamEvent) {
+		if event.Name == EventOptimal {
+			response = event.Data.(*Response)
+		}
+	})
+	return response, err
+}
+
+// responseMeta - meta ответа: расстояние и время, если они есть, пропущенные шаги обогащения запроса
+// и провайдеры, которые не умеют считать такой запрос
+func (s *Service) responseMeta(ctx context.Context, req Request, m Meta, errMeta error, statuses []ProviderStatus) *Meta {
+	warnings := append([]string(nil), req.Warnings...)
 	if errMeta != nil {
 		s.Logger.ServiceWarningLogEntry(ctx, errMeta, "Emty data from Moses", "moses")
-		s.collector.AddServiceError("moses", req.RegionID)
+		s.collector.AddServiceError("moses", req.Mode(), req.RegionID)
+		warnings = append(warnings, WarningRouting)
+	}
+	if !m.hasRoute() {
+		m = Meta{}
+	}
+	m.Warnings = warnings
+	m.Unsupported = unsupportedStatuses(statuses)
+	if req.Variant != nil {
+		m.Experiment = req.Variant.Experiment
+		m.Variant = req.Variant.Name
 	}
 	if m.isEmpty() {
-		return newResponse(nil, optimal, elses), nil
+		return nil
 	}
-	return newResponse(&m, optimal, elses), nil
+	return &m
 }
 
 // ParseTaxiRequest - парсим запрос к сервису такси
@@ -288,27 +472,62 @@ func (s *Service) ParseTaxiRequest(ctx context.Context, r *http.Request) (Reques
 	taxiReq.ReqID = taxiReqID
 	content, errRead := ioutil.ReadAll(r.Body)
 	if errRead != nil {
-		s.collector.AddServiceError("invalid_request", taxiReq.RegionID)
-		return taxiReq, errors.Wrap(errRead, "Cannot load request body")
+		s.collector.AddServiceError("invalid_request", taxiReq.Mode(), taxiReq.RegionID)
+		return taxiReq, WithErrorClass(errors.Wrap(errRead, "Cannot load request body"), ErrClassBadRequest)
 	}
 	r.Body.Close()
-	if err := json.NewDecoder(bytes.NewReader(content)).Decode(&taxiReq); err != nil {
-		s.collector.AddServiceError("invalid_request", taxiReq.RegionID)
-		return taxiReq, errors.Wrap(err, "Cannot parse request json")
+	if err := s.decodeRequest(content, &taxiReq); err != nil {
+		s.collector.AddServiceError("invalid_request", taxiReq.Mode(), taxiReq.RegionID)
+		return taxiReq, WithErrorClass(errors.Wrap(err, "Cannot parse request json"), ErrClassBadRequest)
+	}
+
+	return taxiReq, s.CheckTaxiRequest(ctx, r.Header.Get(ClientIDHeader), &taxiReq)
+}
+
+// CheckTaxiRequest - проверяем разобранный запрос и распределяем его по вариантам эксперимента; запросы gRPC API
+// приходят уже разобранными. clientHeader - идентификатор клиента, как заголовок X-Client-Id
+func (s *Service) CheckTaxiRequest(ctx context.Context, clientHeader string, taxiReq *Request) error {
+	if err := s.checkTaxiRequest(ctx, clientHeader, taxiReq); err != nil {
+		s.collector.AddServiceError("invalid_request", taxiReq.Mode(), taxiReq.RegionID)
+		return err
 	}
+	return nil
+}
 
-	if taxiReq.RegionID == 0 || taxiReq.Point1.IsEmpty() || taxiReq.Point2.IsEmpty() {
-		return taxiReq, ErrTaxiReqEmpty
+// checkTaxiRequest - проверяем разобранный запрос и распределяем его по вариантам эксперимента
+func (s *Service) checkTaxiRequest(ctx context.Context, clientHeader string, taxiReq *Request) error {
+	if err := s.validateRequest(*taxiReq); err != nil {
+		return WithErrorClass(err, ErrClassBadRequest)
+	}
+	taxiReq.Variant = s.experiment.assign(taxiReq.RegionID, clientID(clientHeader, *taxiReq))
+	if taxiReq.Variant != nil {
+		s.Logger.ExperimentLogEntry(ctx, taxiReq.Variant.Experiment, taxiReq.Variant.Name, taxiReq.RegionID)
+		s.collector.AddExperimentRequest(taxiReq.Variant.Experiment, taxiReq.Variant.Name, taxiReq.RegionID)
 	}
-	return taxiReq, nil
+	return nil
 }
 
 // EvaluateTaxiRequest - парсим запрос к сервису такси
 func (s *Service) EvaluateTaxiRequest(ctx context.Context, taxiReq *Request) error {
 	start := time.Now()
+	if taxiReq.OnlyAPI {
+		s.evaluateOnlyAPI(taxiReq)
+		elapsed := float64(time.Since(start).Nanoseconds()) / 1000000
+		s.Logger.TimingLogEntry(ctx, elapsed, "Evaluate request: only API")
+		return nil
+	}
 	var wg sync.WaitGroup
 	var addrPoint1, addrPoint2 *webapi.PointInfo
 	var errAddrPoint1, errAddrPoint2 error
+	addrWaypoints := make([]*webapi.PointInfo, len(taxiReq.Waypoints))
+	errAddrWaypoints := make([]error, len(taxiReq.Waypoints))
+	for i := range taxiReq.Waypoints {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			addrWaypoints[i], errAddrWaypoints[i] = s.addrSrv.Address(ctx, taxiReq.Waypoints[i].Lat, taxiReq.Waypoints[i].Lon)
+		}(i)
+	}
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
@@ -319,23 +538,79 @@ func (s *Service) EvaluateTaxiRequest(ctx context.Context, taxiReq *Request) err
 		addrPoint2, errAddrPoint2 = s.addrSrv.Address(ctx, taxiReq.Point2.Lat, taxiReq.Point2.Lon)
 	}()
 	wg.Wait()
+	elapsed := float64(time.Since(start).Nanoseconds()) / 1000000
+	s.Logger.TimingLogEntry(ctx, elapsed, "Evaluate request: request webAPI")
+	start = time.Now()
 	if errAddrPoint1 != nil {
-		s.collector.AddServiceError("webapi_point", taxiReq.RegionID)
-		return errors.Wrap(errAddrPoint1, "Cannot evaluate address for point1")
+		s.degradePoint(ctx, taxiReq, &taxiReq.Point1, errors.Wrap(errAddrPoint1, "Cannot evaluate address for point1"), WarningGeocodingPoint1)
+	} else {
+		taxiReq.Point1.AddGeoInfo(addrPoint1)
 	}
 	if errAddrPoint2 != nil {
-		s.collector.AddServiceError("webapi_point", taxiReq.RegionID)
-		return errors.Wrap(errAddrPoint2, "Cannot evaluate address for point2")
+		s.degradePoint(ctx, taxiReq, &taxiReq.Point2, errors.Wrap(errAddrPoint2, "Cannot evaluate address for point2"), WarningGeocodingPoint2)
+	} else {
+		taxiReq.Point2.AddGeoInfo(addrPoint2)
+	}
+	for i := range taxiReq.Waypoints {
+		if errAddrWaypoints[i] != nil {
+			s.degradePoint(ctx, taxiReq, &taxiReq.Waypoints[i], errors.Wrapf(errAddrWaypoints[i], "Cannot evaluate address for waypoint %v", i+1), WarningGeocodingWaypoint(i))
+		} else {
+			taxiReq.Waypoints[i].AddGeoInfo(addrWaypoints[i])
+		}
 	}
-	elapsed := float64(time.Since(start).Nanoseconds()) / 1000000
-	s.Logger.TimingLogEntry(ctx, elapsed, "Evaluate request: request webAPI")
-	start = time.Now()
-	taxiReq.Point1.AddGeoInfo(addrPoint1)
-	taxiReq.Point2.AddGeoInfo(addrPoint2)
 
-	taxiReq.Point1.AddArea(s.addrSrv.AreaNameByLatLon(taxiReq.Point1.Lat, taxiReq.Point1.Lon))
-	taxiReq.Point2.AddArea(s.addrSrv.AreaNameByLatLon(taxiReq.Point2.Lat, taxiReq.Point2.Lon))
+	s.addAreas(taxiReq)
 	elapsed = float64(time.Since(start).Nanoseconds()) / 1000000
 	s.Logger.TimingLogEntry(ctx, elapsed, "Evaluate request: Parse area")
 	return nil
 }
+
+// evaluateOnlyAPI - в режиме only_api WebAPI не спрашиваем: провайдерам уходят координаты клиента без адресов
+func (s *Service) evaluateOnlyAPI(taxiReq *Request) {
+	taxiReq.Point1.stringfy()
+	taxiReq.Point2.stringfy()
+	for i := range taxiReq.Waypoints {
+		taxiReq.Waypoints[i].stringfy()
+	}
+	s.addAreas(taxiReq)
+}
+
+// addAreas - области точек маршрута, считаются локально без WebAPI
+func (s *Service) addAreas(taxiReq *Request) {
+	taxiReq.Point1.AddArea(s.addrSrv.AreaNameByLatLon(taxiReq.Point1.Lat, taxiReq.Point1.Lon))
+	taxiReq.Point2.AddArea(s.addrSrv.AreaNameByLatLon(taxiReq.Point2.Lat, taxiReq.Point2.Lon))
+	for i := range taxiReq.Waypoints {
+		taxiReq.Waypoints[i].AddArea(s.addrSrv.AreaNameByLatLon(taxiReq.Waypoints[i].Lat, taxiReq.Waypoints[i].Lon))
+	}
+}
+
+// degradePoint - WebAPI не ответил: оставляем координаты и адрес, которые прислал клиент, и отмечаем это в warnings
+func (s *Service) degradePoint(ctx context.Context, taxiReq *Request, point *Point, err error, warning string) {
+	s.Logger.ServiceWarningLogEntry(ctx, err, "Use client coordinates", "webapi")
+	s.collector.AddServiceError("webapi_point", taxiReq.Mode(), taxiReq.RegionID)
+	point.stringfy()
+	taxiReq.Warnings = append(taxiReq.Warnings, warning)
+}
+
+// withAddressPlaceholder - пустые адреса в переменных шаблона заменяем на заглушку из настроек.
+// Переменные копируем, т.к. они могут лежать в кэше ответов провайдеров
+func (s *Service) withAddressPlaceholder(taxiData []APIData) []APIData {
+	if s.placeholder == "" {
+		return taxiData
+	}
+	res := make([]APIData, 0, len(taxiData))
+	for _, apiData := range taxiData {
+		if apiData.TemplateVars != nil {
+			vars := make(map[string]string, len(apiData.TemplateVars))
+			for name, value := range apiData.TemplateVars {
+				if value == "" && strings.HasSuffix(name, ".address%") {
+					value = s.placeholder
+				}
+				vars[name] = value
+			}
+			apiData.TemplateVars = vars
+		}
+		res = append(res, apiData)
+	}
+	return res
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"time"
+
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/collector"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
 	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
@@ -15,6 +17,15 @@ type Builder struct {
 	addrSrv     AddressService
 	collector   *collector.Collector
 	logger      *log.StructuredLogger
+	breakerSett BreakerSettings
+	policies    map[string]httprequester.Policy
+	quoteSett   QuoteCacheSettings
+	routeSett   RouteSettings
+	placeholder string
+	maxSurge    float64
+	ranking     RankingSettings
+	experiment  ExperimentSettings
+	batch       BatchSettings
 }
 
 // NewBuilder - создаем создателя сервиса Таксы
@@ -64,23 +75,115 @@ func (sb *Builder) WithLogger(l *log.StructuredLogger) *Builder {
 	return sb
 }
 
+// WithBreakerSettings - передаем настройки circuit breaker провайдеров
+func (sb *Builder) WithBreakerSettings(bs BreakerSettings) *Builder {
+	sb.breakerSett = bs
+	return sb
+}
+
+// WithRequestPolicies - передаем политики запросов к провайдерам: имя провайдера -> политика
+func (sb *Builder) WithRequestPolicies(policies map[string]httprequester.Policy) *Builder {
+	sb.policies = policies
+	return sb
+}
+
+// WithQuoteCache - передаем настройки кэша ответов провайдеров
+func (sb *Builder) WithQuoteCache(qs QuoteCacheSettings) *Builder {
+	sb.quoteSett = qs
+	return sb
+}
+
+// WithRouteSettings - передаем настройки кэша ответов Моисея и оценки маршрута без Моисея
+func (sb *Builder) WithRouteSettings(rs RouteSettings) *Builder {
+	sb.routeSett = rs
+	return sb
+}
+
+// WithAddressPlaceholder - передаем заглушку для диплинков, если адрес точки не удалось определить
+func (sb *Builder) WithAddressPlaceholder(placeholder string) *Builder {
+	sb.placeholder = placeholder
+	return sb
+}
+
+// WithMaxSurge - при выборе оптимального варианта избегаем вариантов с повышающим коэффициентом выше maxSurge;
+// 0 - не избегаем
+func (sb *Builder) WithMaxSurge(maxSurge float64) *Builder {
+	sb.maxSurge = maxSurge
+	return sb
+}
+
+// WithRanking - передаем стратегии выбора оптимального варианта: по умолчанию и по регионам
+func (sb *Builder) WithRanking(rs RankingSettings) *Builder {
+	sb.ranking = rs
+	return sb
+}
+
+// WithExperiment - передаем эксперимент, по вариантам которого распределяются запросы
+func (sb *Builder) WithExperiment(es ExperimentSettings) *Builder {
+	sb.experiment = es
+	return sb
+}
+
+// WithBatchSettings - передаем ограничения пакетных запросов; незаданные лимиты берутся по умолчанию,
+// лимит запросов к одному провайдеру по умолчанию не задан
+func (sb *Builder) WithBatchSettings(bs BatchSettings) *Builder {
+	sb.batch = bs
+	return sb
+}
+
 // Build - создаем сервис таксы со всеми переданными данными
 func (sb *Builder) Build() *Service {
+	breakerSett := sb.breakerSett
+	if breakerSett.IsEmpty() {
+		breakerSett = DefaultBreakerSettings
+	}
 	apisMap := make(map[string]APIDataGetter)
+	breakers := make(map[string]*circuitBreaker)
+	requesters := make(map[string]*httprequester.Requester)
 	for _, api := range sb.apis {
 		apisMap[api.APIName()] = api
+		breakers[api.APIName()] = newCircuitBreaker(breakerSett, sb.breakerStateCollector(api.APIName()))
+		if policy, ok := sb.policies[api.APIName()]; ok && sb.httpreq != nil {
+			requesters[api.APIName()] = sb.httpreq.WithPolicy(policy)
+		}
 	}
+	ranker, rankers := sb.ranking.rankers()
+	batch := sb.batch.WithDefaults(0)
 	s := Service{
 		apisMap:     apisMap,
+		breakers:    breakers,
+		requesters:  requesters,
+		quoteCache:  newQuoteCache(sb.quoteSett, sb.collector),
+		routeSett:   sb.routeSett,
+		routeCache:  newRouteCache(sb.routeSett),
+		placeholder: sb.placeholder,
+		maxSurge:    sb.maxSurge,
+		ranker:      ranker,
+		rankers:     rankers,
+		experiment:  newExperiment(sb.experiment),
+		batch:       batch,
+		batchLimit:  newBatchLimiter(batch),
 		prodCache:   sb.prodCache,
 		httpreq:     sb.httpreq,
 		distTimeSrv: sb.distTimeSrv,
 		addrSrv:     sb.addrSrv,
 		collector:   sb.collector,
 		Logger:      sb.logger,
+		now:         time.Now,
 	}
 	if s.IsOK() {
 		return &s
 	}
 	return nil
 }
+
+func (sb *Builder) breakerStateCollector(apiName string) func(BreakerState) {
+	coll := sb.collector
+	if coll == nil {
+		return nil
+	}
+	coll.SetProviderBreakerState(apiName, int(BreakerClosed))
+	return func(state BreakerState) {
+		coll.SetProviderBreakerState(apiName, int(state))
+	}
+}
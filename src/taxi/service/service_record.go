@@ -4,17 +4,20 @@ import (
 	"github.com/nburunova/taxi-backend-sample/src/product"
 )
 
-type serviceRecord struct {
+// Record - предложение провайдера в ответе сервиса
+type Record struct {
 	AvgEta       *int             `json:"avg_eta"`
 	Price        int              `json:"price"`
-	PriceRanges  *priceRanges     `json:"price_ranges"`
+	PriceRanges  *PriceRanges     `json:"price_ranges"`
 	Rating       *float32         `json:"rating"`
 	Operator     product.Operator `json:"operator"`
 	Eta          *int             `json:"eta"`
 	CurrencyCode *string          `json:"currency_code"`
+	Breakdown    *PriceBreakdown  `json:"price_breakdown,omitempty"`
 }
 
-func newServiceRecord(apiData APIData, prod product.Product) (serviceRecord, error) {
+// newServiceRecord - запись ответа по данным провайдера. onlyAPI - без ссылок на приложения и сторы
+func newServiceRecord(apiData APIData, prod product.Product, onlyAPI bool) (Record, error) {
 	var eta *int
 	if apiData.Eta > 0 {
 		etaMins := apiData.Eta
@@ -28,8 +31,14 @@ func newServiceRecord(apiData APIData, prod product.Product) (serviceRecord, err
 		rating = prod.Rating
 	}
 
-	operator, err := prod.GetOperator(apiData.DisplayName, apiData.TemplateVars)
-	return serviceRecord{
+	var operator product.Operator
+	var err error
+	if onlyAPI {
+		operator = prod.GetBareOperator(apiData.DisplayName)
+	} else {
+		operator, err = prod.GetOperator(apiData.DisplayName, apiData.TemplateVars)
+	}
+	return Record{
 		AvgEta:       prod.AvgEta,
 		Eta:          eta,
 		Price:        int(apiData.PriceMean),
@@ -37,23 +46,33 @@ func newServiceRecord(apiData APIData, prod product.Product) (serviceRecord, err
 		Rating:       rating,
 		CurrencyCode: prod.CurrencyCode,
 		Operator:     operator,
+		Breakdown:    newPriceBreakdown(apiData),
 	}, err
 }
 
-type byPrice []serviceRecord
+// surge - повышающий коэффициент записи; 1, если провайдер его не сообщил
+func (r Record) surge() float64 {
+	if r.Breakdown == nil || r.Breakdown.SurgeMultiplier == nil {
+		return 1
+	}
+	return *r.Breakdown.SurgeMultiplier
+}
+
+type byPrice []Record
 
 func (a byPrice) Len() int           { return len(a) }
 func (a byPrice) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byPrice) Less(i, j int) bool { return a[i].Price < a[j].Price }
 
-type priceRanges struct {
+// PriceRanges - диапазон цены, если провайдер вернул вилку вместо точной цены
+type PriceRanges struct {
 	Min *int `json:"min"`
 	Max *int `json:"max"`
 }
 
-func newPriceRanges(min int, max int) *priceRanges {
+func newPriceRanges(min int, max int) *PriceRanges {
 	if min > 0 && max > 0 {
-		prange := priceRanges{
+		prange := PriceRanges{
 			Min: &min,
 			Max: &max,
 		}
@@ -61,3 +80,40 @@ func newPriceRanges(min int, max int) *priceRanges {
 	}
 	return nil
 }
+
+// PriceBreakdown - из чего складывается цена по данным провайдера. Поля, которые провайдер не сообщил, пустые
+type PriceBreakdown struct {
+	SurgeMultiplier *float64 `json:"surge_multiplier"`
+	MinimumFare     *int     `json:"minimum_fare"`
+	CurrencyCode    *string  `json:"currency_code"`
+	Distance        *int     `json:"distance"`
+	Duration        *int     `json:"duration"`
+}
+
+func newPriceBreakdown(apiData APIData) *PriceBreakdown {
+	var b PriceBreakdown
+	if apiData.SurgeMultiplier > 0 {
+		surge := apiData.SurgeMultiplier
+		b.SurgeMultiplier = &surge
+	}
+	if apiData.MinimumFare > 0 {
+		minimum := apiData.MinimumFare
+		b.MinimumFare = &minimum
+	}
+	if apiData.Currency != "" {
+		currency := apiData.Currency
+		b.CurrencyCode = &currency
+	}
+	if apiData.Distance > 0 {
+		distance := apiData.Distance
+		b.Distance = &distance
+	}
+	if apiData.Duration > 0 {
+		duration := apiData.Duration
+		b.Duration = &duration
+	}
+	if b == (PriceBreakdown{}) {
+		return nil
+	}
+	return &b
+}
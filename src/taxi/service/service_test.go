@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/duration"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/httprequester"
 	"github.com/nburunova/taxi-backend-sample/src/product"
+	"github.com/nburunova/taxi-backend-sample/src/webapi"
 )
 
 var priceOff = 1.3
@@ -78,7 +83,7 @@ func TestResponseFields(t *testing.T) {
 		WithStatCollector(testCollector).
 		WithLogger(testLogger).
 		Build()
-	resp, err := service.Response(testContext, testTaxiRequestMoscow, priceOff)
+	resp, err := service.Response(testContext, fullMode(testTaxiRequestMoscow), priceOff)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(resp.Result.Optimal.Results))
 	//Required fields
@@ -403,6 +408,7 @@ func TestProductCacheErr(t *testing.T) {
 		Build()
 	_, err := service.Response(testContext, testTaxiRequestMoscow, priceOff)
 	assert.NotNil(t, err)
+	assert.Equal(t, ErrClassRegionNotServed, ClassOf(err))
 }
 
 func TestProductCacheEmpty(t *testing.T) {
@@ -427,6 +433,7 @@ func TestProductCacheEmpty(t *testing.T) {
 		Build()
 	_, err := service.Response(testContext, testTaxiRequestMoscow, priceOff)
 	assert.NotNil(t, err)
+	assert.Equal(t, ErrClassRegionNotServed, ClassOf(err))
 }
 
 func TestAPIGetterErrButHaveResponseFromAPI(t *testing.T) {
@@ -481,6 +488,7 @@ func TestAPIGetterErrAndNoResponseFromAPI(t *testing.T) {
 		Build()
 	_, err := service.Response(testContext, testTaxiRequestMoscow, priceOff)
 	assert.NotNil(t, err)
+	assert.Equal(t, ErrClassNoProviders, ClassOf(err))
 }
 
 func TestAPIGetterOKAndNoResponseFromAPI(t *testing.T) {
@@ -520,7 +528,7 @@ func TestDistanceTimeErr(t *testing.T) {
 }
 
 func TestParseReq(t *testing.T) {
-	payload := strings.NewReader("{\r\n    \"region_id\": 14, \r\n    \"point1\": { \r\n        \"lon\": 30.723449,\r\n        \"lat\": 46.441982\r\n    },\r\n    \"point2\": { \r\n        \"lon\": 30.759315,\r\n        \"lat\": 46.453352\r\n    },\r\n    \"only_api\": true \r\n}")
+	payload := strings.NewReader("{\r\n    \"region_id\": 14, \r\n    \"point1\": { \r\n        \"lon\": 30.723449,\r\n        \"lat\": 46.441982\r\n    },\r\n    \"point2\": { \r\n        \"lon\": 30.759315,\r\n        \"lat\": 46.453352\r\n    }\r\n}")
 	testReq, errTestReq := http.NewRequest("POST", "/taksa/api/1.0/route/calculate", payload)
 	if errTestReq != nil {
 		t.Fatal(errTestReq)
@@ -541,6 +549,51 @@ func TestParseReq(t *testing.T) {
 	assert.Equal(t, "40", req.Point2.LonStr)
 }
 
+func TestOnlyAPI(t *testing.T) {
+	payload := `{"region_id": 14, "point1": {"lon": 30.723449, "lat": 46.441982}, "point2": {"lon": 30.759315, "lat": 46.453352}, "only_api": true}`
+	testReq, _ := http.NewRequest("POST", "/taksa/api/1.0/route/calculate", strings.NewReader(payload))
+	service := getTestService()
+	req, err := service.ParseTaxiRequest(testContext, testReq)
+	assert.Nil(t, err)
+	assert.Equal(t, ModeOnlyAPI, req.Mode())
+	assert.Nil(t, service.EvaluateTaxiRequest(testContext, &req))
+	assert.Equal(t, "46.441982", req.Point1.LatStr, "WebAPI is not requested")
+	assert.Equal(t, "30.759315", req.Point2.LonStr)
+	assert.Equal(t, "", req.Point1.Address)
+
+	req = testTaxiRequestOnlyAPI
+	assert.Nil(t, service.EvaluateTaxiRequest(testContext, &req))
+	assert.Equal(t, "55.750376", req.Point1.LatStr, "WebAPI is not requested")
+	assert.Equal(t, "", req.Point2.Address)
+
+	prod := product.Product{
+		ProviderName:   "test1",
+		Title:          "test",
+		AppURLTemplate: strPointer("app://?from=%from.address%&to=%to.address%"),
+		AndroidAppURL:  strPointer("http://android.app"),
+		AndroidAppID:   strPointer("android app id"),
+		IosAppURL:      strPointer("http://ios.app"),
+		IosAppID:       strPointer("ios app id"),
+	}
+	service.prodCache = newMockProductCache(nil, prod)
+	service.distTimeSrv = newMockDistanceTimeService(errors.New("Moses is not requested"), 1000, 2000)
+	resp, err := service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Meta, "no route and no routing warning")
+	if assert.NotNil(t, resp.Result.Optimal) {
+		operator := resp.Result.Optimal.Results[0].Operator
+		assert.Nil(t, operator.URL)
+		assert.Nil(t, operator.StoreURLs)
+		assert.Equal(t, "test", *operator.Title)
+	}
+
+	req.OnlyAPI = false
+	assert.Equal(t, ModeFull, req.Mode())
+	resp, err = service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp.Result.Optimal.Results[0].Operator.URL)
+}
+
 func TestParseReqEmptyPointErr(t *testing.T) {
 	testReqData := Request{
 		RegionID: 1,
@@ -556,6 +609,7 @@ func TestParseReqEmptyPointErr(t *testing.T) {
 	service := getTestService()
 	_, err := service.ParseTaxiRequest(testContext, testReq)
 	assert.NotNil(t, err)
+	assert.Equal(t, ErrClassBadRequest, ClassOf(err))
 }
 
 func TestParseReqAddrErr(t *testing.T) {
@@ -579,5 +633,364 @@ func TestParseReqAddrErr(t *testing.T) {
 	service.addrSrv = addr
 	req, _ := service.ParseTaxiRequest(testContext, testReq)
 	err := service.EvaluateTaxiRequest(testContext, &req)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{WarningGeocodingPoint1, WarningGeocodingPoint2}, req.Warnings)
+	assert.Equal(t, "54.1111", req.Point1.LatStr)
+	assert.Equal(t, "65", req.Point2.LonStr)
+	assert.Equal(t, "", req.Point1.Address)
+}
+
+func TestParseReqAddrErrClientAddress(t *testing.T) {
+	b := bytes.NewBufferString(`{"region_id": 1, "point1": {"lat": 54.1111, "lon": 55.0, "address": "Вокзал"}, "point2": {"lat": 64.1111, "lon": 65.0}}`)
+	testReq, _ := http.NewRequest(http.MethodPost, "http://test.com", b)
+	service := getTestService()
+	service.addrSrv = newMockAddressService(webapi.ErrAddressNotFound, "")
+	req, _ := service.ParseTaxiRequest(testContext, testReq)
+	err := service.EvaluateTaxiRequest(testContext, &req)
+	assert.Nil(t, err)
+	assert.Equal(t, "Вокзал", req.Point1.Address)
+}
+
+func TestResponseWarnings(t *testing.T) {
+	prod := product.Product{
+		ProviderName:   "test1",
+		AppURLTemplate: strPointer("app://?from=%from.address%&to=%to.address%"),
+	}
+	apiData := APIData{
+		PriceMean: 100,
+		TemplateVars: map[string]string{
+			"%from.address%": "",
+			"%to.address%":   "address2",
+		},
+	}
+	service := NewBuilder().
+		WithAPIs([]APIDataGetter{newMockAPIDataGetter(nil, "test1", apiData)}).
+		WithProductCache(newMockProductCache(nil, prod)).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(newMockDistanceTimeService(ErrMosesEmptyResult, 0, 0)).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		WithAddressPlaceholder("point").
+		Build()
+	req := fullMode(testTaxiRequestMoscow)
+	req.Warnings = []string{WarningGeocodingPoint1}
+	resp, err := service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{WarningGeocodingPoint1, WarningRouting}, resp.Meta.Warnings)
+	assert.Nil(t, resp.Meta.Distance)
+	assert.Equal(t, "app://?from=point&to=address2", *resp.Result.Optimal.Results[0].Operator.URL)
+	assert.Equal(t, "", apiData.TemplateVars["%from.address%"])
+}
+
+func TestStreamResponse(t *testing.T) {
+	service := NewBuilder().
+		WithAPIs([]APIDataGetter{
+			newMockAPIDataGetter(nil, "test1", APIData{PriceMean: 100}),
+			newMockAPIDataGetter(nil, "test2", APIData{PriceMean: 200}),
+		}).
+		WithProductCache(newMockProductCache(nil, product.Product{ProviderName: "test1"}, product.Product{ProviderName: "test2"})).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		Build()
+	events := make([]StreamEvent, 0)
+	err := service.StreamResponse(testContext, fullMode(testTaxiRequestMoscow), priceOff, func(event StreamEvent) {
+		events = append(events, event)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(events))
+	providers := make([]string, 0)
+	for _, event := range events[:2] {
+		assert.Equal(t, EventRecords, event.Name)
+		providers = append(providers, event.Data.(ProviderRecords).Provider)
+	}
+	assert.ElementsMatch(t, []string{"test1", "test2"}, providers)
+	assert.Equal(t, EventMeta, events[2].Name)
+	assert.Equal(t, 1000, *events[2].Data.(*Meta).Distance)
+	assert.Equal(t, EventOptimal, events[3].Name)
+	assert.Equal(t, 1, len(events[3].Data.(*Response).Result.Optimal.Results))
+
+	service.apisMap = map[string]APIDataGetter{"test1": newMockAPIDataGetter(ErrInvalidPrice, "test1")}
+	events = events[:0]
+	err = service.StreamResponse(testContext, fullMode(testTaxiRequestMoscow), priceOff, func(event StreamEvent) {
+		events = append(events, event)
+	})
 	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(events))
+}
+
+func TestResponseProviders(t *testing.T) {
+	prods := []product.Product{
+		product.Product{ProviderName: "ok"},
+		product.Product{ProviderName: "timeout"},
+		product.Product{ProviderName: "http_error"},
+		product.Product{ProviderName: "invalid_price"},
+		product.Product{ProviderName: "filtered_out", Tariffs: []string{"comfort"}},
+	}
+	service := NewBuilder().
+		WithAPIs([]APIDataGetter{
+			newMockAPIDataGetter(nil, "ok", APIData{PriceMean: 100}),
+			newMockAPIDataGetter(errors.Wrap(errors.Wrap(httprequester.ErrContextDeadline, "url"), "timeout"), "timeout"),
+			newMockAPIDataGetter(errors.Wrap(httprequester.ErrStatusNotOK, "500"), "http_error"),
+			newMockAPIDataGetter(errors.Wrap(ErrInvalidPrice, "Price <= 0"), "invalid_price"),
+			newMockAPIDataGetter(nil, "filtered_out", APIData{PriceMean: 100, TariffName: "econom"}),
+		}).
+		WithProductCache(newMockProductCache(nil, prods...)).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		Build()
+
+	resp, err := service.Response(testContext, testTaxiRequestMoscow, priceOff)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Providers, "block is opt-in")
+
+	req := testTaxiRequestMoscow
+	req.WithProviders = true
+	resp, err = service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	statuses := make(map[string]string)
+	for _, status := range resp.Providers {
+		statuses[status.Name] = status.Status
+	}
+	assert.Equal(t, map[string]string{
+		"ok":            ProviderOK,
+		"timeout":       ProviderTimeout,
+		"http_error":    ProviderHTTPError,
+		"invalid_price": ProviderInvalidPrice,
+		"filtered_out":  ProviderFilteredOut,
+	}, statuses)
+
+	service.breakers["timeout"].open()
+	resp, err = service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, ProviderStatus{Name: "timeout", Status: ProviderCircuitOpen}, resp.Providers[4])
+}
+
+func TestParseReqWaypoints(t *testing.T) {
+	service := getTestService()
+	parse := func(req Request) (Request, error) {
+		b := new(bytes.Buffer)
+		json.NewEncoder(b).Encode(req)
+		testReq, _ := http.NewRequest(http.MethodPost, "http://test.com", b)
+		return service.ParseTaxiRequest(testContext, testReq)
+	}
+	req, err := parse(testTaxiRequestWaypoints)
+	assert.Nil(t, err)
+	assert.Equal(t, testTaxiRequestWaypoints.Waypoints[0].Lat, req.Waypoints[0].Lat)
+
+	empty := testTaxiRequestWaypoints
+	empty.Waypoints = []Point{testWaypoint, Point{Lat: 55.7}}
+	_, err = parse(empty)
+	assert.Equal(t, ErrWaypointEmpty, errors.Cause(err))
+	assert.Equal(t, ErrClassBadRequest, ClassOf(err))
+
+	tooMany := testTaxiRequestWaypoints
+	tooMany.Waypoints = make([]Point, MaxWaypoints+1)
+	for i := range tooMany.Waypoints {
+		tooMany.Waypoints[i] = testWaypoint
+	}
+	_, err = parse(tooMany)
+	assert.Equal(t, ErrTooManyWaypoints, errors.Cause(err))
+	assert.Equal(t, ErrClassBadRequest, ClassOf(err))
+}
+
+func TestEvaluateWaypoints(t *testing.T) {
+	service := getTestService()
+	req := fullMode(testTaxiRequestWaypoints)
+	req.Waypoints = []Point{testWaypoint}
+	assert.Nil(t, service.EvaluateTaxiRequest(testContext, &req))
+	assert.Equal(t, "test address", req.Waypoints[0].Address)
+	assert.Equal(t, testWaypoint.Address, testTaxiRequestWaypoints.Waypoints[0].Address, "request template is not modified")
+
+	service.addrSrv = newMockAddressService(errors.New("webapi err"), "")
+	req = fullMode(testTaxiRequestWaypoints)
+	req.Waypoints = []Point{testWaypoint}
+	assert.Nil(t, service.EvaluateTaxiRequest(testContext, &req))
+	assert.Equal(t, []string{WarningGeocodingPoint1, WarningGeocodingPoint2, "geocoding_waypoint1"}, req.Warnings)
+	assert.Equal(t, testWaypoint.LatStr, req.Waypoints[0].LatStr)
+}
+
+func TestResponseWaypoints(t *testing.T) {
+	withStops := product.Product{ProviderName: "stops"}
+	withStops.AppURLTemplate = strPointer("stops://order?via_lat=%waypoint1.lat%&via_lon=%waypoint1.lon%")
+	service := NewBuilder().
+		WithAPIs([]APIDataGetter{
+			mockWaypointsAPIDataGetter{newMockAPIDataGetter(nil, "stops", APIData{PriceMean: 200, TemplateVars: testTaxiRequestWaypoints.WaypointTemplateVars()})},
+			newMockAPIDataGetter(nil, "direct", APIData{PriceMean: 100}),
+		}).
+		WithProductCache(newMockProductCache(nil, withStops, product.Product{ProviderName: "direct"})).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		Build()
+
+	req := fullMode(testTaxiRequestWaypoints)
+	req.WithProviders = true
+	resp, err := service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Result.Else)
+	assert.Equal(t, 1, len(resp.Result.Optimal.Results))
+	assert.Equal(t, "stops://order?via_lat=55.755&via_lon=37.615", *resp.Result.Optimal.Results[0].Operator.URL)
+	statuses := make(map[string]string)
+	for _, status := range resp.Providers {
+		statuses[status.Name] = status.Status
+	}
+	assert.Equal(t, map[string]string{
+		"stops":  ProviderOK,
+		"direct": ProviderWaypointsUnsupported,
+	}, statuses)
+
+	req.Waypoints = nil
+	resp, err = service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(resp.Providers))
+	assert.Equal(t, ProviderOK, resp.Providers[0].Status)
+	assert.Equal(t, ProviderOK, resp.Providers[1].Status)
+}
+
+func TestParseReqPreOrder(t *testing.T) {
+	service := getTestService()
+	parse := func(now time.Time) (Request, error) {
+		service.now = func() time.Time { return now }
+		body, _ := os.Open("_test_jsons/request.preorder.json")
+		defer body.Close()
+		testReq, _ := http.NewRequest(http.MethodPost, "http://test.com", body)
+		return service.ParseTaxiRequest(testContext, testReq)
+	}
+	req, err := parse(testPickupTime.Add(-2 * time.Hour))
+	assert.Nil(t, err)
+	assert.True(t, req.IsScheduled())
+	assert.True(t, testPickupTime.Equal(*req.PickupTime))
+
+	_, err = parse(testPickupTime.Add(time.Minute))
+	assert.Equal(t, ErrPickupTimeInPast, errors.Cause(err))
+	assert.Equal(t, ErrClassBadRequest, ClassOf(err))
+
+	_, err = parse(testPickupTime.Add(-MaxPreOrderHorizon - time.Hour))
+	assert.Equal(t, ErrPickupTimeTooFar, errors.Cause(err))
+	assert.Equal(t, ErrClassBadRequest, ClassOf(err))
+}
+
+func TestResponsePreOrder(t *testing.T) {
+	service := NewBuilder().
+		WithAPIs([]APIDataGetter{
+			mockPreOrderAPIDataGetter{newMockAPIDataGetter(nil, "preorder", APIData{PriceMean: 300})},
+			newMockAPIDataGetter(nil, "now", APIData{PriceMean: 100, Eta: 5}),
+		}).
+		WithProductCache(newMockProductCache(nil, product.Product{ProviderName: "preorder"}, product.Product{ProviderName: "now"})).
+		WithRequester(testHTTPRequester).
+		WithDistanceTimeSrv(newMockDistanceTimeService(nil, 1000, 2000)).
+		WithAddressSrv(newMockAddressService(nil, "")).
+		WithStatCollector(testCollector).
+		WithLogger(testLogger).
+		WithQuoteCache(QuoteCacheSettings{TTL: duration.Milliseconds(time.Minute)}).
+		WithRouteSettings(RouteSettings{TTL: duration.Milliseconds(time.Minute)}).
+		Build()
+
+	req := testTaxiRequestScheduled
+	req.WithProviders = true
+	resp, err := service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Result.Else)
+	assert.Equal(t, 300, resp.Result.Optimal.Results[0].Price)
+	statuses := make(map[string]string)
+	for _, status := range resp.Providers {
+		statuses[status.Name] = status.Status
+	}
+	assert.Equal(t, map[string]string{
+		"preorder": ProviderOK,
+		"now":      ProviderPreOrderUnsupported,
+	}, statuses)
+
+	req.WithProviders = false
+	resp, err = service.Response(testContext, req, priceOff)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Providers)
+	if assert.NotNil(t, resp.Meta) && assert.Equal(t, 1, len(resp.Meta.Unsupported)) {
+		assert.Equal(t, "now", resp.Meta.Unsupported[0].Name, "unsupported providers are reported without with_providers")
+		assert.Equal(t, ProviderPreOrderUnsupported, resp.Meta.Unsupported[0].Status)
+	}
+
+	_, ok := service.quoteCache.get(testTaxiRequestMoscow, "preorder")
+	assert.False(t, ok, "pre-order quotes are not cached")
+	_, _, ok = service.routeCache.get(testTaxiRequestMoscow)
+	assert.False(t, ok, "pre-order routes are not cached")
+}
+
+func TestPriceBreakdown(t *testing.T) {
+	// ошибка про пустые ссылки на приложения здесь не важна
+	rec, _ := newServiceRecord(APIData{
+		PriceMean:       70,
+		SurgeMultiplier: 1.8,
+		MinimumFare:     20,
+		Currency:        "AED",
+		Distance:        7854,
+		Duration:        16,
+	}, product.Product{}, false)
+	data, _ := json.Marshal(rec.Breakdown)
+	assert.JSONEq(t, `{"surge_multiplier":1.8,"minimum_fare":20,"currency_code":"AED","distance":7854,"duration":16}`, string(data))
+	assert.Equal(t, 1.8, rec.surge())
+
+	rec, _ = newServiceRecord(APIData{PriceMean: 70}, product.Product{}, false)
+	assert.Nil(t, rec.Breakdown)
+	assert.Equal(t, 1.0, rec.surge())
+	data, _ = json.Marshal(rec)
+	assert.NotContains(t, string(data), "price_breakdown", "existing clients see no new field")
+}
+
+func surgedRecord(price int, surge float64, isOptimal bool) Record {
+	rec := Record{Price: price}
+	rec.Operator.IsOptimal = isOptimal
+	if surge > 0 {
+		rec.Breakdown = &PriceBreakdown{SurgeMultiplier: &surge}
+	}
+	return rec
+}
+
+func TestOptimalAvoidsSurge(t *testing.T) {
+	service := getTestService()
+	service.maxSurge = 1.5
+
+	optimal, elses := service.getOptimalElse(testTaxiRequestMoscow, []Record{
+		surgedRecord(100, 2, false),
+		surgedRecord(120, 0, false),
+		surgedRecord(500, 1, false),
+	}, false, priceOff)
+	assert.Equal(t, 120, optimal[0].Price)
+	assert.Equal(t, 2, len(elses))
+
+	// все варианты с повышающим коэффициентом - выбираем как обычно
+	optimal, _ = service.getOptimalElse(testTaxiRequestMoscow, []Record{
+		surgedRecord(100, 2, false),
+		surgedRecord(500, 3, false),
+	}, false, priceOff)
+	assert.Equal(t, 100, optimal[0].Price)
+
+	optimal, _ = service.getOptimalElse(testTaxiRequestMoscow, []Record{
+		surgedRecord(100, 2, true),
+		surgedRecord(120, 0, true),
+	}, true, priceOff)
+	assert.Equal(t, 120, optimal[0].Price)
+
+	// оптимальный продукт региона показываем и с повышающим коэффициентом
+	optimal, _ = service.getOptimalElse(testTaxiRequestMoscow, []Record{
+		surgedRecord(100, 2, true),
+		surgedRecord(120, 0, false),
+	}, true, priceOff)
+	assert.Equal(t, 100, optimal[0].Price)
+
+	service.maxSurge = 0
+	optimal, _ = service.getOptimalElse(testTaxiRequestMoscow, []Record{
+		surgedRecord(100, 2, true),
+		surgedRecord(120, 0, true),
+	}, true, priceOff)
+	assert.Equal(t, 100, optimal[0].Price)
 }
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// События потокового ответа сервиса
+const (
+	// EventRecords - результаты одного провайдера, приходят по мере ответа провайдеров
+	EventRecords = "records"
+	// EventMeta - расстояние, время и предупреждения, приходит после ответов всех провайдеров
+	EventMeta = "meta"
+	// EventOptimal - итоговый ответ с оптимальным выбором, последнее событие
+	EventOptimal = "optimal"
+	// EventError - ответ собрать не удалось, последнее событие
+	EventError = "error"
+)
+
+// StreamEvent - событие потокового ответа
+type StreamEvent struct {
+	Name string
+	Data interface{}
+}
+
+// ProviderRecords - данные события records
+type ProviderRecords struct {
+	Provider string   `json:"provider"`
+	Results  []Record `json:"results"`
+}
+
+// recordsNotifier - вызывается, когда готовы результаты провайдера. Вызовы не пересекаются по времени
+type recordsNotifier func(apiName string, records []Record)
+
+// StreamResponse - ответ с данными от провайдеров такси по частям: результаты каждого провайдера отдаются в emit
+// сразу после его ответа, затем отдаются meta и итоговый ответ с оптимальным выбором. emit не вызывается конкурентно
+func (s *Service) StreamResponse(ctx context.Context, req Request, priceCoeff float64, emit func(StreamEvent)) error {
+	var errRecords, errMeta error
+	var optimal, elses []Record
+	var statuses []ProviderStatus
+	var m Meta
+	var wg sync.WaitGroup
+	notify := func(apiName string, records []Record) {
+		emit(StreamEvent{Name: EventRecords, Data: ProviderRecords{Provider: apiName, Results: records}})
+	}
+	wg.Add(2)
+	go s.getServiceRecords(ctx, &wg, req, priceCoeff, &optimal, &elses, &statuses, &errRecords, notify)
+	go s.getMeta(ctx, &wg, req, &m, &errMeta)
+	wg.Wait()
+	if errRecords != nil {
+		s.Logger.ServiceWarningLogEntry(ctx, errRecords, "Empty data from providers", "provider")
+		s.collector.AddServiceError("providers_empty", req.Mode(), req.RegionID)
+		return errors.Wrap(errRecords, "Empty data from providers")
+	}
+	responseMeta := s.responseMeta(ctx, req, m, errMeta, statuses)
+	if responseMeta != nil {
+		emit(StreamEvent{Name: EventMeta, Data: responseMeta})
+	}
+	response := newResponse(responseMeta, optimal, elses)
+	if req.WithProviders {
+		response.Providers = statuses
+	}
+	emit(StreamEvent{Name: EventOptimal, Data: response})
+	return nil
+}
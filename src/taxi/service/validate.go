@@ -0,0 +1,150 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/errorswrapper"
+	"github.com/pkg/errors"
+)
+
+// Границы координат, как в схеме Point из openapi.json
+const (
+	// MaxLat - граница широты, градусы
+	MaxLat = 90
+	// MaxLon - граница долготы, градусы
+	MaxLon = 180
+)
+
+var (
+	// ErrCoordinateOutOfRange - широта или долгота вне допустимого диапазона
+	ErrCoordinateOutOfRange = errors.New("Coordinate is out of range")
+	// ErrUnknownField - в запросе есть поле, которого нет в контракте API
+	ErrUnknownField = errors.New("Unknown request field")
+	// ErrFieldType - значение поля запроса не того типа
+	ErrFieldType = errors.New("Invalid request field type")
+	// ErrFieldRequired - в запросе нет обязательного поля
+	ErrFieldRequired = errors.New("Required request field is missing")
+	// ErrFieldRange - значение или количество элементов поля запроса вне допустимого диапазона
+	ErrFieldRange = errors.New("Request field is out of range")
+)
+
+// FieldError - ошибка значения поля запроса. Field - путь к полю как в JSON запроса: point1.lat, waypoints[0].lon.
+// errors.Cause - исходная ошибка, например ErrTaxiReqEmpty
+type FieldError struct {
+	Field   string
+	Message string
+	Err     error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Field, e.Message)
+}
+
+// Cause - исходная ошибка, для errors.Cause
+func (e *FieldError) Cause() error {
+	return e.Err
+}
+
+func fieldError(field, message string, err error) error {
+	return &FieldError{Field: field, Message: message, Err: err}
+}
+
+// FieldErrors - все ошибки полей внутри err: по цепочке Cause и внутри составных ошибок
+func FieldErrors(err error) []*FieldError {
+	var found []*FieldError
+	for err != nil {
+		if fe, ok := err.(*FieldError); ok {
+			return append(found, fe)
+		}
+		if m, ok := err.(*errorswrapper.MultiError); ok {
+			for _, inner := range m.Errors() {
+				found = append(found, FieldErrors(inner)...)
+			}
+			return found
+		}
+		c, ok := err.(causer)
+		if !ok {
+			return found
+		}
+		err = c.Cause()
+	}
+	return found
+}
+
+// decodeRequest - проверяем JSON запроса по схеме Request из OpenAPISpec и разбираем его в taxiReq
+func (s *Service) decodeRequest(content []byte, taxiReq *Request) error {
+	return s.decodeJSON(content, openAPISchemas["Request"], requestCause, taxiReq)
+}
+
+// decodeJSON - проверяем JSON по схеме sc и разбираем его в v; cause - как для validateSchema
+func (s *Service) decodeJSON(content []byte, sc *openAPISchema, cause func(field string, err error) error, v interface{}) error {
+	var doc interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+	if err := validateSchema(sc, doc, s.now(), cause); err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
+
+// validateRequest - проверяем разобранный запрос по схеме Request из OpenAPISpec и возвращаем ошибки всех полей сразу.
+// Так проверяются и запросы gRPC API, которые приходят уже разобранными
+func (s *Service) validateRequest(taxiReq Request) error {
+	doc, err := requestDocument(taxiReq)
+	if err != nil {
+		return err
+	}
+	return validateSchema(openAPISchemas["Request"], doc, s.now(), requestCause)
+}
+
+// requestDocument - разобранный запрос в виде JSON документа. Нулевой регион и нулевые координаты
+// значат, что поле не передали (см. Point.IsEmpty), поэтому в документе их нет
+func requestDocument(taxiReq Request) (map[string]interface{}, error) {
+	content, err := json.Marshal(taxiReq)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if taxiReq.RegionID == 0 {
+		delete(doc, "region_id")
+	}
+	points := []interface{}{doc["point1"], doc["point2"]}
+	if waypoints, ok := doc["waypoints"].([]interface{}); ok {
+		points = append(points, waypoints...)
+	}
+	for _, p := range points {
+		point := p.(map[string]interface{})
+		for _, coord := range []string{"lat", "lon"} {
+			if point[coord] == float64(0) {
+				delete(point, coord)
+			}
+		}
+	}
+	return doc, nil
+}
+
+// requestCause - исходная ошибка нарушения схемы Request, по ней вызывающий код различает ошибки запроса
+func requestCause(field string, err error) error {
+	switch err {
+	case ErrFieldRequired:
+		if strings.HasPrefix(field, "waypoints[") {
+			return ErrWaypointEmpty
+		}
+		return ErrTaxiReqEmpty
+	case ErrFieldRange:
+		switch field {
+		case "region_id":
+			return ErrTaxiReqEmpty
+		case "waypoints":
+			return ErrTooManyWaypoints
+		}
+		return ErrCoordinateOutOfRange
+	}
+	return err
+}
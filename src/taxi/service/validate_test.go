@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReqValidation(t *testing.T) {
+	service := getTestService()
+	parse := func(payload string) (Request, error) {
+		testReq, _ := http.NewRequest(http.MethodPost, "http://test.com", strings.NewReader(payload))
+		return service.ParseTaxiRequest(testContext, testReq)
+	}
+	cases := []struct {
+		name    string
+		payload string
+		fields  []string
+		cause   error
+	}{
+		{"lat out of range", `{"region_id": 14, "point1": {"lat": 91, "lon": 30.7}, "point2": {"lat": 46.4, "lon": 30.7}}`, []string{"point1.lat"}, ErrCoordinateOutOfRange},
+		{"lon out of range", `{"region_id": 14, "point1": {"lat": 46.4, "lon": 30.7}, "point2": {"lat": 46.4, "lon": -180.5}}`, []string{"point2.lon"}, ErrCoordinateOutOfRange},
+		{"waypoint out of range", `{"region_id": 14, "point1": {"lat": 46.4, "lon": 30.7}, "point2": {"lat": 46.4, "lon": 30.7}, "waypoints": [{"lat": 46.4, "lon": 30.7}, {"lat": -95, "lon": 30.7}]}`, []string{"waypoints[1].lat"}, ErrCoordinateOutOfRange},
+		{"unknown field", `{"region_id": 14, "point1": {"lat": 46.4, "lon": 30.7}, "point2": {"lat": 46.4, "lon": 30.7}, "regionId": 1}`, []string{"regionId"}, ErrUnknownField},
+		{"unknown point field", `{"region_id": 14, "point1": {"lat": 46.4, "lon": 30.7, "alt": 1}, "point2": {"lat": 46.4, "lon": 30.7}}`, []string{"point1.alt"}, ErrUnknownField},
+		{"field type", `{"region_id": "14", "point1": {"lat": 46.4, "lon": 30.7}, "point2": {"lat": 46.4, "lon": 30.7}}`, []string{"region_id"}, ErrFieldType},
+		{"empty point", `{"region_id": 14, "point2": {"lat": 46.4, "lon": 30.7}}`, []string{"point1"}, ErrTaxiReqEmpty},
+		{"zero coordinate", `{"region_id": 14, "point1": {"lat": 46.4, "lon": 0}, "point2": {"lat": 46.4, "lon": 30.7}}`, []string{"point1.lon"}, ErrTaxiReqEmpty},
+		{"waypoint without lon", `{"region_id": 14, "point1": {"lat": 46.4, "lon": 30.7}, "point2": {"lat": 46.4, "lon": 30.7}, "waypoints": [{"lat": 46.4}]}`, []string{"waypoints[0].lon"}, ErrWaypointEmpty},
+		{"pickup time format", `{"region_id": 14, "point1": {"lat": 46.4, "lon": 30.7}, "point2": {"lat": 46.4, "lon": 30.7}, "pickup_time": "tomorrow"}`, []string{"pickup_time"}, ErrFieldType},
+	}
+	for _, c := range cases {
+		_, err := parse(c.payload)
+		if !assert.NotNil(t, err, c.name) {
+			continue
+		}
+		assert.Equal(t, ErrClassBadRequest, ClassOf(err), c.name)
+		assert.Equal(t, c.cause, errors.Cause(err), c.name)
+		var fields []string
+		for _, fe := range FieldErrors(err) {
+			fields = append(fields, fe.Field)
+		}
+		assert.Equal(t, c.fields, fields, c.name)
+	}
+
+	_, err := parse(`{"region_id": 0, "point1": {"lat": 100, "lon": 200}, "point2": {"lat": 46.4, "lon": 30.7}, "waypoints": [{"lat": 46.4}]}`)
+	assert.Equal(t, ErrClassBadRequest, ClassOf(err))
+	var messages []string
+	for _, fe := range FieldErrors(err) {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"point1.lat: must be in [-90, 90]",
+		"point1.lon: must be in [-180, 180]",
+		"region_id: must be >= 1",
+		"waypoints[0].lon: is required",
+	}, messages, "all invalid fields are reported at once")
+
+	req, err := parse(`{"region_id": 14, "point1": {"lat": -90, "lon": 180, "address": "Пушкина, 1"}, "point2": {"lat": 90, "lon": -180}}`)
+	assert.Nil(t, err, "range bounds are valid")
+	assert.Equal(t, "Пушкина, 1", req.Point1.Address)
+}
+
+// TestValidateMatchesOpenAPI - validateRequest отклоняет ровно то, что запрещает схема Request в openapi.json,
+// в том числе для разобранных запросов, как из gRPC API
+func TestValidateMatchesOpenAPI(t *testing.T) {
+	reqSchema, pointSchema := openAPISchemas["Request"], openAPISchemas["Point"]
+
+	service := getTestService()
+	service.now = func() time.Time { return testPickupTime.Add(-time.Hour) }
+	valid := func() Request {
+		return Request{RegionID: 14, Point1: Point{Lat: 46.44, Lon: 30.72}, Point2: Point{Lat: 46.45, Lon: 30.75}}
+	}
+	fields := func(req Request) []string {
+		var names []string
+		for _, fe := range FieldErrors(service.validateRequest(req)) {
+			names = append(names, fe.Field)
+		}
+		return names
+	}
+	assert.Nil(t, service.validateRequest(valid()))
+
+	assert.Equal(t, []string{"region_id", "point1", "point2"}, reqSchema.Required)
+	req := valid()
+	req.RegionID = 0
+	assert.Equal(t, []string{"region_id"}, fields(req), "required region_id")
+	req = valid()
+	req.Point1, req.Point2 = Point{}, Point{}
+	assert.Equal(t, []string{"point1.lat", "point1.lon", "point2.lat", "point2.lon"}, fields(req), "required points")
+	assert.Equal(t, []string{"lat", "lon"}, pointSchema.Required)
+	for _, c := range []struct {
+		point Point
+		field string
+	}{{Point{Lon: 30.72}, "point1.lat"}, {Point{Lat: 46.44}, "point1.lon"}} {
+		req := valid()
+		req.Point1 = c.point
+		assert.Equal(t, []string{c.field}, fields(req), "point without lat or lon")
+	}
+
+	regionMin := int(*reqSchema.Properties["region_id"].Minimum)
+	req = valid()
+	req.RegionID = regionMin
+	assert.Empty(t, fields(req))
+	req.RegionID = regionMin - 1
+	assert.Equal(t, []string{"region_id"}, fields(req))
+
+	for _, coord := range []string{"lat", "lon"} {
+		bounds := pointSchema.Properties[coord]
+		set := func(v float64) Request {
+			req := valid()
+			if coord == "lat" {
+				req.Point2.Lat = v
+			} else {
+				req.Point2.Lon = v
+			}
+			return req
+		}
+		assert.Empty(t, fields(set(*bounds.Minimum)), coord)
+		assert.Empty(t, fields(set(*bounds.Maximum)), coord)
+		assert.Equal(t, []string{"point2." + coord}, fields(set(*bounds.Minimum-0.001)), coord)
+		assert.Equal(t, []string{"point2." + coord}, fields(set(*bounds.Maximum+0.001)), coord)
+	}
+
+	maxItems := *reqSchema.Properties["waypoints"].MaxItems
+	req = valid()
+	for i := 0; i < maxItems; i++ {
+		req.Waypoints = append(req.Waypoints, Point{Lat: 46.44, Lon: 30.73})
+	}
+	assert.Empty(t, fields(req))
+	req.Waypoints = append(req.Waypoints, Point{Lat: 46.44, Lon: 30.73})
+	assert.Equal(t, []string{"waypoints"}, fields(req))
+
+	aheadHours := *reqSchema.Properties["pickup_time"].MaxAheadHours
+	now := service.now()
+	for _, c := range []struct {
+		pickup time.Time
+		fields []string
+	}{
+		{now.Add(time.Minute), nil},
+		{now.Add(time.Duration(aheadHours) * time.Hour), nil},
+		{now.Add(time.Duration(aheadHours)*time.Hour + time.Minute), []string{"pickup_time"}},
+		{now.Add(-time.Minute), []string{"pickup_time"}},
+	} {
+		req := valid()
+		pickup := c.pickup
+		req.PickupTime = &pickup
+		assert.Equal(t, c.fields, fields(req), fmt.Sprint(pickup.Sub(now)))
+	}
+}
@@ -0,0 +1,75 @@
+package taxi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/api"
+	"github.com/nburunova/taxi-backend-sample/src/infrastructure/log"
+	"github.com/nburunova/taxi-backend-sample/src/taxi/service"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrStreamUnsupported - сервис или соединение не поддерживают потоковый ответ
+	ErrStreamUnsupported = errors.New("Streaming is not supported")
+)
+
+// StreamService - сервис такси, который умеет отдавать ответ по частям
+type StreamService interface {
+	Service
+	StreamResponse(ctx context.Context, req service.Request, priceCoeff float64, emit func(service.StreamEvent)) error
+}
+
+// StreamHandler - хендлер запроса данных такси, который отдает результаты провайдеров
+// через Server-Sent Events по мере их готовности. Ошибки до начала потока отдаются как в Handler,
+// после начала потока - событием error с тем же статусом и кодом по классу ошибки
+func StreamHandler(srv Service, basicPriceCoeff float64, regPriceCoeff RegionPriceCoeff, waitTime time.Duration, w http.ResponseWriter, r *http.Request) {
+	streamSrv, okStream := srv.(StreamService)
+	flusher, okFlush := w.(http.Flusher)
+	if !okStream || !okFlush {
+		render.Render(w, r, errServerError(r.Context(), ErrStreamUnsupported))
+		return
+	}
+	ctxTaxi, cancelTaxi := context.WithTimeout(r.Context(), waitTime)
+	defer cancelTaxi()
+	taxiReq, taxiReqParseErr := srv.ParseTaxiRequest(ctxTaxi, r)
+	if taxiReqParseErr != nil {
+		render.Render(w, r, errInvalidRequest(r.Context(), taxiReqParseErr))
+		return
+	}
+	api.SetRequestMode(r.Context(), taxiReq.Mode())
+	ctxTaxi = context.WithValue(ctxTaxi, log.CtxKeyRegionID, taxiReq.RegionID)
+	errEval := srv.EvaluateTaxiRequest(ctxTaxi, &taxiReq)
+	if errEval != nil {
+		render.Render(w, r, errServerError(r.Context(), errEval))
+		return
+	}
+	coeff := regPriceCoeff.GetByRegionOrElse(taxiReq.RegionID, basicPriceCoeff)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	emit := func(event service.StreamEvent) {
+		writeEvent(r.Context(), w, event)
+		flusher.Flush()
+	}
+	if errResponse := streamSrv.StreamResponse(ctxTaxi, taxiReq, coeff, emit); errResponse != nil {
+		emit(service.StreamEvent{Name: service.EventError, Data: newErrResponse(r.Context(), errResponse, service.ErrClassInternal)})
+	}
+}
+
+// writeEvent - пишем событие в формате text/event-stream
+func writeEvent(ctx context.Context, w http.ResponseWriter, event service.StreamEvent) {
+	data, errMarshal := json.Marshal(event.Data)
+	if errMarshal != nil {
+		data, _ = json.Marshal(errServerError(ctx, errors.Wrap(errMarshal, "Cannot marshal event")))
+		event.Name = service.EventError
+	}
+	fmt.Fprintf(w, "event: %v\ndata: %s\n\n", event.Name, data)
+}
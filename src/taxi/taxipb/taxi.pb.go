@@ -0,0 +1,1778 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: taxi.proto
+
+package taxipb // import "github.com/nburunova/taxi-backend-sample/src/taxi/taxipb"
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import timestamp "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// Point - service.Point: координаты от клиента, область заполняет сервис
+type Point struct {
+	Lat float64 `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon float64 `protobuf:"fixed64,2,opt,name=lon,proto3" json:"lon,omitempty"`
+	// address - адрес точки, если WebAPI адрес не вернет
+	Address              string   `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Point) Reset()         { *m = Point{} }
+func (m *Point) String() string { return proto.CompactTextString(m) }
+func (*Point) ProtoMessage()    {}
+func (*Point) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{0}
+}
+func (m *Point) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Point.Unmarshal(m, b)
+}
+func (m *Point) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Point.Marshal(b, m, deterministic)
+}
+func (dst *Point) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Point.Merge(dst, src)
+}
+func (m *Point) XXX_Size() int {
+	return xxx_messageInfo_Point.Size(m)
+}
+func (m *Point) XXX_DiscardUnknown() {
+	xxx_messageInfo_Point.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Point proto.InternalMessageInfo
+
+func (m *Point) GetLat() float64 {
+	if m != nil {
+		return m.Lat
+	}
+	return 0
+}
+
+func (m *Point) GetLon() float64 {
+	if m != nil {
+		return m.Lon
+	}
+	return 0
+}
+
+func (m *Point) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+// CalculateRequest - service.Request
+type CalculateRequest struct {
+	RegionId int32  `protobuf:"varint,1,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	Point1   *Point `protobuf:"bytes,2,opt,name=point1,proto3" json:"point1,omitempty"`
+	Point2   *Point `protobuf:"bytes,3,opt,name=point2,proto3" json:"point2,omitempty"`
+	// waypoints - промежуточные остановки в порядке объезда
+	Waypoints []*Point `protobuf:"bytes,4,rep,name=waypoints,proto3" json:"waypoints,omitempty"`
+	// only_api - облегченный режим: без WebAPI и Моисея, только цены провайдеров
+	OnlyApi bool `protobuf:"varint,5,opt,name=only_api,json=onlyApi,proto3" json:"only_api,omitempty"`
+	// pickup_time - время подачи для предварительного заказа; пусто - поездка сейчас
+	PickupTime    *timestamp.Timestamp `protobuf:"bytes,6,opt,name=pickup_time,json=pickupTime,proto3" json:"pickup_time,omitempty"`
+	UserId        string               `protobuf:"bytes,7,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	WithProviders bool                 `protobuf:"varint,8,opt,name=with_providers,json=withProviders,proto3" json:"with_providers,omitempty"`
+	// client_id - идентификатор клиента для экспериментов, как заголовок X-Client-Id
+	ClientId             string   `protobuf:"bytes,9,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CalculateRequest) Reset()         { *m = CalculateRequest{} }
+func (m *CalculateRequest) String() string { return proto.CompactTextString(m) }
+func (*CalculateRequest) ProtoMessage()    {}
+func (*CalculateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{1}
+}
+func (m *CalculateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CalculateRequest.Unmarshal(m, b)
+}
+func (m *CalculateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CalculateRequest.Marshal(b, m, deterministic)
+}
+func (dst *CalculateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CalculateRequest.Merge(dst, src)
+}
+func (m *CalculateRequest) XXX_Size() int {
+	return xxx_messageInfo_CalculateRequest.Size(m)
+}
+func (m *CalculateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CalculateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CalculateRequest proto.InternalMessageInfo
+
+func (m *CalculateRequest) GetRegionId() int32 {
+	if m != nil {
+		return m.RegionId
+	}
+	return 0
+}
+
+func (m *CalculateRequest) GetPoint1() *Point {
+	if m != nil {
+		return m.Point1
+	}
+	return nil
+}
+
+func (m *CalculateRequest) GetPoint2() *Point {
+	if m != nil {
+		return m.Point2
+	}
+	return nil
+}
+
+func (m *CalculateRequest) GetWaypoints() []*Point {
+	if m != nil {
+		return m.Waypoints
+	}
+	return nil
+}
+
+func (m *CalculateRequest) GetOnlyApi() bool {
+	if m != nil {
+		return m.OnlyApi
+	}
+	return false
+}
+
+func (m *CalculateRequest) GetPickupTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.PickupTime
+	}
+	return nil
+}
+
+func (m *CalculateRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *CalculateRequest) GetWithProviders() bool {
+	if m != nil {
+		return m.WithProviders
+	}
+	return false
+}
+
+func (m *CalculateRequest) GetClientId() string {
+	if m != nil {
+		return m.ClientId
+	}
+	return ""
+}
+
+// CalculateResponse - service.Response
+type CalculateResponse struct {
+	Results              *Results          `protobuf:"bytes,1,opt,name=results,proto3" json:"results,omitempty"`
+	Meta                 *Meta             `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"`
+	Providers            []*ProviderStatus `protobuf:"bytes,3,rep,name=providers,proto3" json:"providers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *CalculateResponse) Reset()         { *m = CalculateResponse{} }
+func (m *CalculateResponse) String() string { return proto.CompactTextString(m) }
+func (*CalculateResponse) ProtoMessage()    {}
+func (*CalculateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{2}
+}
+func (m *CalculateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CalculateResponse.Unmarshal(m, b)
+}
+func (m *CalculateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CalculateResponse.Marshal(b, m, deterministic)
+}
+func (dst *CalculateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CalculateResponse.Merge(dst, src)
+}
+func (m *CalculateResponse) XXX_Size() int {
+	return xxx_messageInfo_CalculateResponse.Size(m)
+}
+func (m *CalculateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CalculateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CalculateResponse proto.InternalMessageInfo
+
+func (m *CalculateResponse) GetResults() *Results {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *CalculateResponse) GetMeta() *Meta {
+	if m != nil {
+		return m.Meta
+	}
+	return nil
+}
+
+func (m *CalculateResponse) GetProviders() []*ProviderStatus {
+	if m != nil {
+		return m.Providers
+	}
+	return nil
+}
+
+type Results struct {
+	Id                   int32        `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Optimal              *ResultBlock `protobuf:"bytes,2,opt,name=optimal,proto3" json:"optimal,omitempty"`
+	Else                 *ResultBlock `protobuf:"bytes,3,opt,name=else,proto3" json:"else,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Results) Reset()         { *m = Results{} }
+func (m *Results) String() string { return proto.CompactTextString(m) }
+func (*Results) ProtoMessage()    {}
+func (*Results) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{3}
+}
+func (m *Results) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Results.Unmarshal(m, b)
+}
+func (m *Results) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Results.Marshal(b, m, deterministic)
+}
+func (dst *Results) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Results.Merge(dst, src)
+}
+func (m *Results) XXX_Size() int {
+	return xxx_messageInfo_Results.Size(m)
+}
+func (m *Results) XXX_DiscardUnknown() {
+	xxx_messageInfo_Results.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Results proto.InternalMessageInfo
+
+func (m *Results) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Results) GetOptimal() *ResultBlock {
+	if m != nil {
+		return m.Optimal
+	}
+	return nil
+}
+
+func (m *Results) GetElse() *ResultBlock {
+	if m != nil {
+		return m.Else
+	}
+	return nil
+}
+
+type ResultBlock struct {
+	Title                string    `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Summary              string    `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"`
+	Results              []*Record `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ResultBlock) Reset()         { *m = ResultBlock{} }
+func (m *ResultBlock) String() string { return proto.CompactTextString(m) }
+func (*ResultBlock) ProtoMessage()    {}
+func (*ResultBlock) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{4}
+}
+func (m *ResultBlock) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResultBlock.Unmarshal(m, b)
+}
+func (m *ResultBlock) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResultBlock.Marshal(b, m, deterministic)
+}
+func (dst *ResultBlock) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResultBlock.Merge(dst, src)
+}
+func (m *ResultBlock) XXX_Size() int {
+	return xxx_messageInfo_ResultBlock.Size(m)
+}
+func (m *ResultBlock) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResultBlock.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResultBlock proto.InternalMessageInfo
+
+func (m *ResultBlock) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *ResultBlock) GetSummary() string {
+	if m != nil {
+		return m.Summary
+	}
+	return ""
+}
+
+func (m *ResultBlock) GetResults() []*Record {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// Record - запись ответа по одному тарифу провайдера. Необязательные числа - в обертках,
+// чтобы отличать 0 от отсутствия значения, как null в JSON
+type Record struct {
+	AvgEta               *Int32Value     `protobuf:"bytes,1,opt,name=avg_eta,json=avgEta,proto3" json:"avg_eta,omitempty"`
+	Price                int32           `protobuf:"varint,2,opt,name=price,proto3" json:"price,omitempty"`
+	PriceRanges          *PriceRanges    `protobuf:"bytes,3,opt,name=price_ranges,json=priceRanges,proto3" json:"price_ranges,omitempty"`
+	Rating               *FloatValue     `protobuf:"bytes,4,opt,name=rating,proto3" json:"rating,omitempty"`
+	Operator             *Operator       `protobuf:"bytes,5,opt,name=operator,proto3" json:"operator,omitempty"`
+	Eta                  *Int32Value     `protobuf:"bytes,6,opt,name=eta,proto3" json:"eta,omitempty"`
+	CurrencyCode         string          `protobuf:"bytes,7,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
+	PriceBreakdown       *PriceBreakdown `protobuf:"bytes,8,opt,name=price_breakdown,json=priceBreakdown,proto3" json:"price_breakdown,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Record) Reset()         { *m = Record{} }
+func (m *Record) String() string { return proto.CompactTextString(m) }
+func (*Record) ProtoMessage()    {}
+func (*Record) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{5}
+}
+func (m *Record) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Record.Unmarshal(m, b)
+}
+func (m *Record) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Record.Marshal(b, m, deterministic)
+}
+func (dst *Record) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Record.Merge(dst, src)
+}
+func (m *Record) XXX_Size() int {
+	return xxx_messageInfo_Record.Size(m)
+}
+func (m *Record) XXX_DiscardUnknown() {
+	xxx_messageInfo_Record.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Record proto.InternalMessageInfo
+
+func (m *Record) GetAvgEta() *Int32Value {
+	if m != nil {
+		return m.AvgEta
+	}
+	return nil
+}
+
+func (m *Record) GetPrice() int32 {
+	if m != nil {
+		return m.Price
+	}
+	return 0
+}
+
+func (m *Record) GetPriceRanges() *PriceRanges {
+	if m != nil {
+		return m.PriceRanges
+	}
+	return nil
+}
+
+func (m *Record) GetRating() *FloatValue {
+	if m != nil {
+		return m.Rating
+	}
+	return nil
+}
+
+func (m *Record) GetOperator() *Operator {
+	if m != nil {
+		return m.Operator
+	}
+	return nil
+}
+
+func (m *Record) GetEta() *Int32Value {
+	if m != nil {
+		return m.Eta
+	}
+	return nil
+}
+
+func (m *Record) GetCurrencyCode() string {
+	if m != nil {
+		return m.CurrencyCode
+	}
+	return ""
+}
+
+func (m *Record) GetPriceBreakdown() *PriceBreakdown {
+	if m != nil {
+		return m.PriceBreakdown
+	}
+	return nil
+}
+
+type Int32Value struct {
+	Value                int32    `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Int32Value) Reset()         { *m = Int32Value{} }
+func (m *Int32Value) String() string { return proto.CompactTextString(m) }
+func (*Int32Value) ProtoMessage()    {}
+func (*Int32Value) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{6}
+}
+func (m *Int32Value) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Int32Value.Unmarshal(m, b)
+}
+func (m *Int32Value) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Int32Value.Marshal(b, m, deterministic)
+}
+func (dst *Int32Value) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Int32Value.Merge(dst, src)
+}
+func (m *Int32Value) XXX_Size() int {
+	return xxx_messageInfo_Int32Value.Size(m)
+}
+func (m *Int32Value) XXX_DiscardUnknown() {
+	xxx_messageInfo_Int32Value.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Int32Value proto.InternalMessageInfo
+
+func (m *Int32Value) GetValue() int32 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type FloatValue struct {
+	Value                float32  `protobuf:"fixed32,1,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FloatValue) Reset()         { *m = FloatValue{} }
+func (m *FloatValue) String() string { return proto.CompactTextString(m) }
+func (*FloatValue) ProtoMessage()    {}
+func (*FloatValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{7}
+}
+func (m *FloatValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FloatValue.Unmarshal(m, b)
+}
+func (m *FloatValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FloatValue.Marshal(b, m, deterministic)
+}
+func (dst *FloatValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FloatValue.Merge(dst, src)
+}
+func (m *FloatValue) XXX_Size() int {
+	return xxx_messageInfo_FloatValue.Size(m)
+}
+func (m *FloatValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_FloatValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FloatValue proto.InternalMessageInfo
+
+func (m *FloatValue) GetValue() float32 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type PriceRanges struct {
+	Min                  *Int32Value `protobuf:"bytes,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max                  *Int32Value `protobuf:"bytes,2,opt,name=max,proto3" json:"max,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *PriceRanges) Reset()         { *m = PriceRanges{} }
+func (m *PriceRanges) String() string { return proto.CompactTextString(m) }
+func (*PriceRanges) ProtoMessage()    {}
+func (*PriceRanges) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{8}
+}
+func (m *PriceRanges) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PriceRanges.Unmarshal(m, b)
+}
+func (m *PriceRanges) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PriceRanges.Marshal(b, m, deterministic)
+}
+func (dst *PriceRanges) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PriceRanges.Merge(dst, src)
+}
+func (m *PriceRanges) XXX_Size() int {
+	return xxx_messageInfo_PriceRanges.Size(m)
+}
+func (m *PriceRanges) XXX_DiscardUnknown() {
+	xxx_messageInfo_PriceRanges.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PriceRanges proto.InternalMessageInfo
+
+func (m *PriceRanges) GetMin() *Int32Value {
+	if m != nil {
+		return m.Min
+	}
+	return nil
+}
+
+func (m *PriceRanges) GetMax() *Int32Value {
+	if m != nil {
+		return m.Max
+	}
+	return nil
+}
+
+type PriceBreakdown struct {
+	SurgeMultiplier      *FloatValue `protobuf:"bytes,1,opt,name=surge_multiplier,json=surgeMultiplier,proto3" json:"surge_multiplier,omitempty"`
+	MinimumFare          *Int32Value `protobuf:"bytes,2,opt,name=minimum_fare,json=minimumFare,proto3" json:"minimum_fare,omitempty"`
+	CurrencyCode         string      `protobuf:"bytes,3,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
+	Distance             *Int32Value `protobuf:"bytes,4,opt,name=distance,proto3" json:"distance,omitempty"`
+	Duration             *Int32Value `protobuf:"bytes,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *PriceBreakdown) Reset()         { *m = PriceBreakdown{} }
+func (m *PriceBreakdown) String() string { return proto.CompactTextString(m) }
+func (*PriceBreakdown) ProtoMessage()    {}
+func (*PriceBreakdown) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{9}
+}
+func (m *PriceBreakdown) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PriceBreakdown.Unmarshal(m, b)
+}
+func (m *PriceBreakdown) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PriceBreakdown.Marshal(b, m, deterministic)
+}
+func (dst *PriceBreakdown) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PriceBreakdown.Merge(dst, src)
+}
+func (m *PriceBreakdown) XXX_Size() int {
+	return xxx_messageInfo_PriceBreakdown.Size(m)
+}
+func (m *PriceBreakdown) XXX_DiscardUnknown() {
+	xxx_messageInfo_PriceBreakdown.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PriceBreakdown proto.InternalMessageInfo
+
+func (m *PriceBreakdown) GetSurgeMultiplier() *FloatValue {
+	if m != nil {
+		return m.SurgeMultiplier
+	}
+	return nil
+}
+
+func (m *PriceBreakdown) GetMinimumFare() *Int32Value {
+	if m != nil {
+		return m.MinimumFare
+	}
+	return nil
+}
+
+func (m *PriceBreakdown) GetCurrencyCode() string {
+	if m != nil {
+		return m.CurrencyCode
+	}
+	return ""
+}
+
+func (m *PriceBreakdown) GetDistance() *Int32Value {
+	if m != nil {
+		return m.Distance
+	}
+	return nil
+}
+
+func (m *PriceBreakdown) GetDuration() *Int32Value {
+	if m != nil {
+		return m.Duration
+	}
+	return nil
+}
+
+// Operator - product.Operator
+type Operator struct {
+	BranchId             string     `protobuf:"bytes,1,opt,name=branch_id,json=branchId,proto3" json:"branch_id,omitempty"`
+	Url                  string     `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Image                string     `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	Site                 *Link      `protobuf:"bytes,4,opt,name=site,proto3" json:"site,omitempty"`
+	BackgroundColor      string     `protobuf:"bytes,5,opt,name=background_color,json=backgroundColor,proto3" json:"background_color,omitempty"`
+	ShortTitle           string     `protobuf:"bytes,6,opt,name=short_title,json=shortTitle,proto3" json:"short_title,omitempty"`
+	StoreUrls            *StoreURLs `protobuf:"bytes,7,opt,name=store_urls,json=storeUrls,proto3" json:"store_urls,omitempty"`
+	Id                   int32      `protobuf:"varint,8,opt,name=id,proto3" json:"id,omitempty"`
+	TextColor            string     `protobuf:"bytes,9,opt,name=text_color,json=textColor,proto3" json:"text_color,omitempty"`
+	Title                string     `protobuf:"bytes,10,opt,name=title,proto3" json:"title,omitempty"`
+	OrgId                string     `protobuf:"bytes,11,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Phone                *Link      `protobuf:"bytes,12,opt,name=phone,proto3" json:"phone,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *Operator) Reset()         { *m = Operator{} }
+func (m *Operator) String() string { return proto.CompactTextString(m) }
+func (*Operator) ProtoMessage()    {}
+func (*Operator) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{10}
+}
+func (m *Operator) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Operator.Unmarshal(m, b)
+}
+func (m *Operator) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Operator.Marshal(b, m, deterministic)
+}
+func (dst *Operator) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Operator.Merge(dst, src)
+}
+func (m *Operator) XXX_Size() int {
+	return xxx_messageInfo_Operator.Size(m)
+}
+func (m *Operator) XXX_DiscardUnknown() {
+	xxx_messageInfo_Operator.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Operator proto.InternalMessageInfo
+
+func (m *Operator) GetBranchId() string {
+	if m != nil {
+		return m.BranchId
+	}
+	return ""
+}
+
+func (m *Operator) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Operator) GetImage() string {
+	if m != nil {
+		return m.Image
+	}
+	return ""
+}
+
+func (m *Operator) GetSite() *Link {
+	if m != nil {
+		return m.Site
+	}
+	return nil
+}
+
+func (m *Operator) GetBackgroundColor() string {
+	if m != nil {
+		return m.BackgroundColor
+	}
+	return ""
+}
+
+func (m *Operator) GetShortTitle() string {
+	if m != nil {
+		return m.ShortTitle
+	}
+	return ""
+}
+
+func (m *Operator) GetStoreUrls() *StoreURLs {
+	if m != nil {
+		return m.StoreUrls
+	}
+	return nil
+}
+
+func (m *Operator) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Operator) GetTextColor() string {
+	if m != nil {
+		return m.TextColor
+	}
+	return ""
+}
+
+func (m *Operator) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *Operator) GetOrgId() string {
+	if m != nil {
+		return m.OrgId
+	}
+	return ""
+}
+
+func (m *Operator) GetPhone() *Link {
+	if m != nil {
+		return m.Phone
+	}
+	return nil
+}
+
+type Link struct {
+	Value                string   `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Text                 string   `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Link) Reset()         { *m = Link{} }
+func (m *Link) String() string { return proto.CompactTextString(m) }
+func (*Link) ProtoMessage()    {}
+func (*Link) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{11}
+}
+func (m *Link) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Link.Unmarshal(m, b)
+}
+func (m *Link) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Link.Marshal(b, m, deterministic)
+}
+func (dst *Link) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Link.Merge(dst, src)
+}
+func (m *Link) XXX_Size() int {
+	return xxx_messageInfo_Link.Size(m)
+}
+func (m *Link) XXX_DiscardUnknown() {
+	xxx_messageInfo_Link.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Link proto.InternalMessageInfo
+
+func (m *Link) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *Link) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+type StoreURLs struct {
+	Ios                  *StoreURL `protobuf:"bytes,1,opt,name=ios,proto3" json:"ios,omitempty"`
+	Android              *StoreURL `protobuf:"bytes,2,opt,name=android,proto3" json:"android,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *StoreURLs) Reset()         { *m = StoreURLs{} }
+func (m *StoreURLs) String() string { return proto.CompactTextString(m) }
+func (*StoreURLs) ProtoMessage()    {}
+func (*StoreURLs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{12}
+}
+func (m *StoreURLs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StoreURLs.Unmarshal(m, b)
+}
+func (m *StoreURLs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StoreURLs.Marshal(b, m, deterministic)
+}
+func (dst *StoreURLs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StoreURLs.Merge(dst, src)
+}
+func (m *StoreURLs) XXX_Size() int {
+	return xxx_messageInfo_StoreURLs.Size(m)
+}
+func (m *StoreURLs) XXX_DiscardUnknown() {
+	xxx_messageInfo_StoreURLs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StoreURLs proto.InternalMessageInfo
+
+func (m *StoreURLs) GetIos() *StoreURL {
+	if m != nil {
+		return m.Ios
+	}
+	return nil
+}
+
+func (m *StoreURLs) GetAndroid() *StoreURL {
+	if m != nil {
+		return m.Android
+	}
+	return nil
+}
+
+type StoreURL struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StoreURL) Reset()         { *m = StoreURL{} }
+func (m *StoreURL) String() string { return proto.CompactTextString(m) }
+func (*StoreURL) ProtoMessage()    {}
+func (*StoreURL) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{13}
+}
+func (m *StoreURL) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StoreURL.Unmarshal(m, b)
+}
+func (m *StoreURL) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StoreURL.Marshal(b, m, deterministic)
+}
+func (dst *StoreURL) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StoreURL.Merge(dst, src)
+}
+func (m *StoreURL) XXX_Size() int {
+	return xxx_messageInfo_StoreURL.Size(m)
+}
+func (m *StoreURL) XXX_DiscardUnknown() {
+	xxx_messageInfo_StoreURL.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StoreURL proto.InternalMessageInfo
+
+func (m *StoreURL) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *StoreURL) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+type Meta struct {
+	Distance *Int32Value `protobuf:"bytes,1,opt,name=distance,proto3" json:"distance,omitempty"`
+	Time     *Int32Value `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	// source - откуда расстояние и время: routed, cached или estimated
+	Source     string   `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Warnings   []string `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Experiment string   `protobuf:"bytes,5,opt,name=experiment,proto3" json:"experiment,omitempty"`
+	Variant    string   `protobuf:"bytes,6,opt,name=variant,proto3" json:"variant,omitempty"`
+	// unsupported - провайдеры, которые не умеют считать такой запрос и не опрашивались
+	Unsupported          []*ProviderStatus `protobuf:"bytes,7,rep,name=unsupported,proto3" json:"unsupported,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Meta) Reset()         { *m = Meta{} }
+func (m *Meta) String() string { return proto.CompactTextString(m) }
+func (*Meta) ProtoMessage()    {}
+func (*Meta) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{14}
+}
+func (m *Meta) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Meta.Unmarshal(m, b)
+}
+func (m *Meta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Meta.Marshal(b, m, deterministic)
+}
+func (dst *Meta) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Meta.Merge(dst, src)
+}
+func (m *Meta) XXX_Size() int {
+	return xxx_messageInfo_Meta.Size(m)
+}
+func (m *Meta) XXX_DiscardUnknown() {
+	xxx_messageInfo_Meta.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Meta proto.InternalMessageInfo
+
+func (m *Meta) GetDistance() *Int32Value {
+	if m != nil {
+		return m.Distance
+	}
+	return nil
+}
+
+func (m *Meta) GetTime() *Int32Value {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *Meta) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *Meta) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+func (m *Meta) GetExperiment() string {
+	if m != nil {
+		return m.Experiment
+	}
+	return ""
+}
+
+func (m *Meta) GetVariant() string {
+	if m != nil {
+		return m.Variant
+	}
+	return ""
+}
+
+func (m *Meta) GetUnsupported() []*ProviderStatus {
+	if m != nil {
+		return m.Unsupported
+	}
+	return nil
+}
+
+type ProviderStatus struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status               string   `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	LatencyMs            int32    `protobuf:"varint,3,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProviderStatus) Reset()         { *m = ProviderStatus{} }
+func (m *ProviderStatus) String() string { return proto.CompactTextString(m) }
+func (*ProviderStatus) ProtoMessage()    {}
+func (*ProviderStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{15}
+}
+func (m *ProviderStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProviderStatus.Unmarshal(m, b)
+}
+func (m *ProviderStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProviderStatus.Marshal(b, m, deterministic)
+}
+func (dst *ProviderStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProviderStatus.Merge(dst, src)
+}
+func (m *ProviderStatus) XXX_Size() int {
+	return xxx_messageInfo_ProviderStatus.Size(m)
+}
+func (m *ProviderStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProviderStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProviderStatus proto.InternalMessageInfo
+
+func (m *ProviderStatus) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ProviderStatus) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ProviderStatus) GetLatencyMs() int32 {
+	if m != nil {
+		return m.LatencyMs
+	}
+	return 0
+}
+
+// CalculateEvent - service.StreamEvent: records, meta, optimal или error
+type CalculateEvent struct {
+	// Types that are valid to be assigned to Event:
+	//	*CalculateEvent_Records
+	//	*CalculateEvent_Meta
+	//	*CalculateEvent_Optimal
+	//	*CalculateEvent_Error
+	Event                isCalculateEvent_Event `protobuf_oneof:"event"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *CalculateEvent) Reset()         { *m = CalculateEvent{} }
+func (m *CalculateEvent) String() string { return proto.CompactTextString(m) }
+func (*CalculateEvent) ProtoMessage()    {}
+func (*CalculateEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{16}
+}
+func (m *CalculateEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CalculateEvent.Unmarshal(m, b)
+}
+func (m *CalculateEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CalculateEvent.Marshal(b, m, deterministic)
+}
+func (dst *CalculateEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CalculateEvent.Merge(dst, src)
+}
+func (m *CalculateEvent) XXX_Size() int {
+	return xxx_messageInfo_CalculateEvent.Size(m)
+}
+func (m *CalculateEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_CalculateEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CalculateEvent proto.InternalMessageInfo
+
+type isCalculateEvent_Event interface {
+	isCalculateEvent_Event()
+}
+
+type CalculateEvent_Records struct {
+	Records *ProviderRecords `protobuf:"bytes,1,opt,name=records,proto3,oneof"`
+}
+
+type CalculateEvent_Meta struct {
+	Meta *Meta `protobuf:"bytes,2,opt,name=meta,proto3,oneof"`
+}
+
+type CalculateEvent_Optimal struct {
+	Optimal *CalculateResponse `protobuf:"bytes,3,opt,name=optimal,proto3,oneof"`
+}
+
+type CalculateEvent_Error struct {
+	Error *Error `protobuf:"bytes,4,opt,name=error,proto3,oneof"`
+}
+
+func (*CalculateEvent_Records) isCalculateEvent_Event() {}
+
+func (*CalculateEvent_Meta) isCalculateEvent_Event() {}
+
+func (*CalculateEvent_Optimal) isCalculateEvent_Event() {}
+
+func (*CalculateEvent_Error) isCalculateEvent_Event() {}
+
+func (m *CalculateEvent) GetEvent() isCalculateEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *CalculateEvent) GetRecords() *ProviderRecords {
+	if x, ok := m.GetEvent().(*CalculateEvent_Records); ok {
+		return x.Records
+	}
+	return nil
+}
+
+func (m *CalculateEvent) GetMeta() *Meta {
+	if x, ok := m.GetEvent().(*CalculateEvent_Meta); ok {
+		return x.Meta
+	}
+	return nil
+}
+
+func (m *CalculateEvent) GetOptimal() *CalculateResponse {
+	if x, ok := m.GetEvent().(*CalculateEvent_Optimal); ok {
+		return x.Optimal
+	}
+	return nil
+}
+
+func (m *CalculateEvent) GetError() *Error {
+	if x, ok := m.GetEvent().(*CalculateEvent_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*CalculateEvent) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _CalculateEvent_OneofMarshaler, _CalculateEvent_OneofUnmarshaler, _CalculateEvent_OneofSizer, []interface{}{
+		(*CalculateEvent_Records)(nil),
+		(*CalculateEvent_Meta)(nil),
+		(*CalculateEvent_Optimal)(nil),
+		(*CalculateEvent_Error)(nil),
+	}
+}
+
+func _CalculateEvent_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*CalculateEvent)
+	// event
+	switch x := m.Event.(type) {
+	case *CalculateEvent_Records:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Records); err != nil {
+			return err
+		}
+	case *CalculateEvent_Meta:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Meta); err != nil {
+			return err
+		}
+	case *CalculateEvent_Optimal:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Optimal); err != nil {
+			return err
+		}
+	case *CalculateEvent_Error:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Error); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("CalculateEvent.Event has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _CalculateEvent_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*CalculateEvent)
+	switch tag {
+	case 1: // event.records
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ProviderRecords)
+		err := b.DecodeMessage(msg)
+		m.Event = &CalculateEvent_Records{msg}
+		return true, err
+	case 2: // event.meta
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Meta)
+		err := b.DecodeMessage(msg)
+		m.Event = &CalculateEvent_Meta{msg}
+		return true, err
+	case 3: // event.optimal
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(CalculateResponse)
+		err := b.DecodeMessage(msg)
+		m.Event = &CalculateEvent_Optimal{msg}
+		return true, err
+	case 4: // event.error
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Error)
+		err := b.DecodeMessage(msg)
+		m.Event = &CalculateEvent_Error{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _CalculateEvent_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*CalculateEvent)
+	// event
+	switch x := m.Event.(type) {
+	case *CalculateEvent_Records:
+		s := proto.Size(x.Records)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *CalculateEvent_Meta:
+		s := proto.Size(x.Meta)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *CalculateEvent_Optimal:
+		s := proto.Size(x.Optimal)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *CalculateEvent_Error:
+		s := proto.Size(x.Error)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type ProviderRecords struct {
+	Provider             string    `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Results              []*Record `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ProviderRecords) Reset()         { *m = ProviderRecords{} }
+func (m *ProviderRecords) String() string { return proto.CompactTextString(m) }
+func (*ProviderRecords) ProtoMessage()    {}
+func (*ProviderRecords) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{17}
+}
+func (m *ProviderRecords) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProviderRecords.Unmarshal(m, b)
+}
+func (m *ProviderRecords) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProviderRecords.Marshal(b, m, deterministic)
+}
+func (dst *ProviderRecords) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProviderRecords.Merge(dst, src)
+}
+func (m *ProviderRecords) XXX_Size() int {
+	return xxx_messageInfo_ProviderRecords.Size(m)
+}
+func (m *ProviderRecords) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProviderRecords.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProviderRecords proto.InternalMessageInfo
+
+func (m *ProviderRecords) GetProvider() string {
+	if m != nil {
+		return m.Provider
+	}
+	return ""
+}
+
+func (m *ProviderRecords) GetResults() []*Record {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// Error - ошибка как в ответах REST API: статус и стабильный код ошибки приложения
+type Error struct {
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Code   int64  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	// error - текст внутренней ошибки, только в режиме отладки
+	Error                string        `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Fields               []*FieldError `protobuf:"bytes,4,rep,name=fields,proto3" json:"fields,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Error) Reset()         { *m = Error{} }
+func (m *Error) String() string { return proto.CompactTextString(m) }
+func (*Error) ProtoMessage()    {}
+func (*Error) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{18}
+}
+func (m *Error) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Error.Unmarshal(m, b)
+}
+func (m *Error) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Error.Marshal(b, m, deterministic)
+}
+func (dst *Error) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Error.Merge(dst, src)
+}
+func (m *Error) XXX_Size() int {
+	return xxx_messageInfo_Error.Size(m)
+}
+func (m *Error) XXX_DiscardUnknown() {
+	xxx_messageInfo_Error.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Error proto.InternalMessageInfo
+
+func (m *Error) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Error) GetCode() int64 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *Error) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Error) GetFields() []*FieldError {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+// FieldError - ошибка поля запроса: путь к полю как в JSON запроса и описание
+type FieldError struct {
+	Field                string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldError) Reset()         { *m = FieldError{} }
+func (m *FieldError) String() string { return proto.CompactTextString(m) }
+func (*FieldError) ProtoMessage()    {}
+func (*FieldError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{19}
+}
+func (m *FieldError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldError.Unmarshal(m, b)
+}
+func (m *FieldError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldError.Marshal(b, m, deterministic)
+}
+func (dst *FieldError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldError.Merge(dst, src)
+}
+func (m *FieldError) XXX_Size() int {
+	return xxx_messageInfo_FieldError.Size(m)
+}
+func (m *FieldError) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldError proto.InternalMessageInfo
+
+func (m *FieldError) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *FieldError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type HealthRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HealthRequest) Reset()         { *m = HealthRequest{} }
+func (m *HealthRequest) String() string { return proto.CompactTextString(m) }
+func (*HealthRequest) ProtoMessage()    {}
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{20}
+}
+func (m *HealthRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HealthRequest.Unmarshal(m, b)
+}
+func (m *HealthRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HealthRequest.Marshal(b, m, deterministic)
+}
+func (dst *HealthRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HealthRequest.Merge(dst, src)
+}
+func (m *HealthRequest) XXX_Size() int {
+	return xxx_messageInfo_HealthRequest.Size(m)
+}
+func (m *HealthRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HealthRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HealthRequest proto.InternalMessageInfo
+
+type HealthResponse struct {
+	Ok                   bool     `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HealthResponse) Reset()         { *m = HealthResponse{} }
+func (m *HealthResponse) String() string { return proto.CompactTextString(m) }
+func (*HealthResponse) ProtoMessage()    {}
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_taxi_2cc3bf7c341561b1, []int{21}
+}
+func (m *HealthResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HealthResponse.Unmarshal(m, b)
+}
+func (m *HealthResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HealthResponse.Marshal(b, m, deterministic)
+}
+func (dst *HealthResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HealthResponse.Merge(dst, src)
+}
+func (m *HealthResponse) XXX_Size() int {
+	return xxx_messageInfo_HealthResponse.Size(m)
+}
+func (m *HealthResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_HealthResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HealthResponse proto.InternalMessageInfo
+
+func (m *HealthResponse) GetOk() bool {
+	if m != nil {
+		return m.Ok
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*Point)(nil), "taxa.v1.Point")
+	proto.RegisterType((*CalculateRequest)(nil), "taxa.v1.CalculateRequest")
+	proto.RegisterType((*CalculateResponse)(nil), "taxa.v1.CalculateResponse")
+	proto.RegisterType((*Results)(nil), "taxa.v1.Results")
+	proto.RegisterType((*ResultBlock)(nil), "taxa.v1.ResultBlock")
+	proto.RegisterType((*Record)(nil), "taxa.v1.Record")
+	proto.RegisterType((*Int32Value)(nil), "taxa.v1.Int32Value")
+	proto.RegisterType((*FloatValue)(nil), "taxa.v1.FloatValue")
+	proto.RegisterType((*PriceRanges)(nil), "taxa.v1.PriceRanges")
+	proto.RegisterType((*PriceBreakdown)(nil), "taxa.v1.PriceBreakdown")
+	proto.RegisterType((*Operator)(nil), "taxa.v1.Operator")
+	proto.RegisterType((*Link)(nil), "taxa.v1.Link")
+	proto.RegisterType((*StoreURLs)(nil), "taxa.v1.StoreURLs")
+	proto.RegisterType((*StoreURL)(nil), "taxa.v1.StoreURL")
+	proto.RegisterType((*Meta)(nil), "taxa.v1.Meta")
+	proto.RegisterType((*ProviderStatus)(nil), "taxa.v1.ProviderStatus")
+	proto.RegisterType((*CalculateEvent)(nil), "taxa.v1.CalculateEvent")
+	proto.RegisterType((*ProviderRecords)(nil), "taxa.v1.ProviderRecords")
+	proto.RegisterType((*Error)(nil), "taxa.v1.Error")
+	proto.RegisterType((*FieldError)(nil), "taxa.v1.FieldError")
+	proto.RegisterType((*HealthRequest)(nil), "taxa.v1.HealthRequest")
+	proto.RegisterType((*HealthResponse)(nil), "taxa.v1.HealthResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TaxiClient is the client API for Taxi service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TaxiClient interface {
+	// Calculate - то же, что POST /calculate
+	Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*CalculateResponse, error)
+	// CalculateStream - то же, что POST /calculate/stream: результаты провайдеров по мере ответа,
+	// затем meta и итоговый ответ с оптимальным выбором
+	CalculateStream(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (Taxi_CalculateStreamClient, error)
+	// Health - то же, что GET /healthcheck
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+type taxiClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTaxiClient(cc *grpc.ClientConn) TaxiClient {
+	return &taxiClient{cc}
+}
+
+func (c *taxiClient) Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*CalculateResponse, error) {
+	out := new(CalculateResponse)
+	err := c.cc.Invoke(ctx, "/taxa.v1.Taxi/Calculate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taxiClient) CalculateStream(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (Taxi_CalculateStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Taxi_serviceDesc.Streams[0], "/taxa.v1.Taxi/CalculateStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &taxiCalculateStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Taxi_CalculateStreamClient interface {
+	Recv() (*CalculateEvent, error)
+	grpc.ClientStream
+}
+
+type taxiCalculateStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *taxiCalculateStreamClient) Recv() (*CalculateEvent, error) {
+	m := new(CalculateEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *taxiClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, "/taxa.v1.Taxi/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TaxiServer is the server API for Taxi service.
+type TaxiServer interface {
+	// Calculate - то же, что POST /calculate
+	Calculate(context.Context, *CalculateRequest) (*CalculateResponse, error)
+	// CalculateStream - то же, что POST /calculate/stream: результаты провайдеров по мере ответа,
+	// затем meta и итоговый ответ с оптимальным выбором
+	CalculateStream(*CalculateRequest, Taxi_CalculateStreamServer) error
+	// Health - то же, что GET /healthcheck
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+}
+
+func RegisterTaxiServer(s *grpc.Server, srv TaxiServer) {
+	s.RegisterService(&_Taxi_serviceDesc, srv)
+}
+
+func _Taxi_Calculate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalculateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaxiServer).Calculate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/taxa.v1.Taxi/Calculate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaxiServer).Calculate(ctx, req.(*CalculateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Taxi_CalculateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CalculateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TaxiServer).CalculateStream(m, &taxiCalculateStreamServer{stream})
+}
+
+type Taxi_CalculateStreamServer interface {
+	Send(*CalculateEvent) error
+	grpc.ServerStream
+}
+
+type taxiCalculateStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *taxiCalculateStreamServer) Send(m *CalculateEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Taxi_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaxiServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/taxa.v1.Taxi/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaxiServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Taxi_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "taxa.v1.Taxi",
+	HandlerType: (*TaxiServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Calculate",
+			Handler:    _Taxi_Calculate_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _Taxi_Health_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CalculateStream",
+			Handler:       _Taxi_CalculateStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "taxi.proto",
+}
+
+func init() { proto.RegisterFile("taxi.proto", fileDescriptor_taxi_2cc3bf7c341561b1) }
+
+var fileDescriptor_taxi_2cc3bf7c341561b1 = []byte{
+	// 1433 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x57, 0xdd, 0x6e, 0x1b, 0x37,
+	0x16, 0x8e, 0xfe, 0x35, 0x47, 0xb6, 0xec, 0x70, 0xb3, 0xf1, 0xc4, 0x8b, 0xdd, 0x78, 0xc7, 0x48,
+	0xd6, 0xd9, 0x24, 0x72, 0xa2, 0xec, 0x66, 0x37, 0x6d, 0x51, 0xb4, 0x0e, 0x9c, 0xda, 0x40, 0x82,
+	0x06, 0x4c, 0x52, 0x14, 0x0d, 0x0a, 0x81, 0x9a, 0x61, 0x64, 0xc2, 0x33, 0xe4, 0x94, 0xe4, 0xc8,
+	0xf6, 0x23, 0xf4, 0xaa, 0xd7, 0x7d, 0xa9, 0x5e, 0xf5, 0x09, 0xfa, 0x02, 0x05, 0xfa, 0x04, 0x05,
+	0x39, 0xe4, 0x48, 0x8a, 0x1c, 0xb5, 0x37, 0xc6, 0x9c, 0xef, 0x7c, 0xe2, 0xf9, 0x25, 0xcf, 0x31,
+	0x80, 0x26, 0xe7, 0x6c, 0x90, 0x4b, 0xa1, 0x05, 0xea, 0x68, 0x72, 0x4e, 0x06, 0xd3, 0x87, 0xdb,
+	0x37, 0x27, 0x42, 0x4c, 0x52, 0xba, 0x6f, 0xe1, 0x71, 0xf1, 0x6e, 0x5f, 0xb3, 0x8c, 0x2a, 0x4d,
+	0xb2, 0xbc, 0x64, 0x46, 0x87, 0xd0, 0x7a, 0x29, 0x18, 0xd7, 0x68, 0x13, 0x1a, 0x29, 0xd1, 0x61,
+	0x6d, 0xa7, 0xb6, 0x57, 0xc3, 0xe6, 0xd3, 0x22, 0x82, 0x87, 0x75, 0x87, 0x08, 0x8e, 0x42, 0xe8,
+	0x90, 0x24, 0x91, 0x54, 0xa9, 0xb0, 0xb1, 0x53, 0xdb, 0x0b, 0xb0, 0x17, 0xa3, 0x5f, 0xea, 0xb0,
+	0xf9, 0x94, 0xa4, 0x71, 0x91, 0x12, 0x4d, 0x31, 0xfd, 0xae, 0xa0, 0x4a, 0xa3, 0xbf, 0x41, 0x20,
+	0xe9, 0x84, 0x09, 0x3e, 0x62, 0x89, 0x3d, 0xb8, 0x85, 0xbb, 0x25, 0x70, 0x9c, 0xa0, 0xdb, 0xd0,
+	0xce, 0x8d, 0xe1, 0x87, 0xd6, 0x40, 0x6f, 0xd8, 0x1f, 0x38, 0x9f, 0x07, 0xd6, 0x1f, 0xec, 0xb4,
+	0x15, 0x6f, 0x18, 0x36, 0x56, 0xf0, 0x86, 0xe8, 0x1e, 0x04, 0x67, 0xe4, 0xc2, 0x0a, 0x2a, 0x6c,
+	0xee, 0x34, 0x2e, 0xa1, 0xce, 0x08, 0xe8, 0x06, 0x74, 0x05, 0x4f, 0x2f, 0x46, 0x24, 0x67, 0x61,
+	0x6b, 0xa7, 0xb6, 0xd7, 0xc5, 0x1d, 0x23, 0x7f, 0x9e, 0x33, 0xf4, 0x31, 0xf4, 0x72, 0x16, 0x9f,
+	0x16, 0xf9, 0xc8, 0xe4, 0x2a, 0x6c, 0x5b, 0xab, 0xdb, 0x83, 0x32, 0x91, 0x03, 0x9f, 0xc8, 0xc1,
+	0x6b, 0x9f, 0x48, 0x0c, 0x25, 0xdd, 0x00, 0x68, 0x0b, 0x3a, 0x85, 0xa2, 0xd2, 0x04, 0xdc, 0xb1,
+	0x19, 0x6a, 0x1b, 0xf1, 0x38, 0x41, 0xb7, 0xa0, 0x7f, 0xc6, 0xf4, 0xc9, 0x28, 0x97, 0x62, 0xca,
+	0x12, 0x2a, 0x55, 0xd8, 0xb5, 0x66, 0xd7, 0x0d, 0xfa, 0xd2, 0x83, 0x26, 0x65, 0x71, 0xca, 0x28,
+	0xd7, 0xe6, 0x84, 0xc0, 0x9e, 0xd0, 0x2d, 0x81, 0xe3, 0x24, 0xfa, 0xb1, 0x06, 0x57, 0xe7, 0x92,
+	0xac, 0x72, 0xc1, 0x15, 0x45, 0xff, 0x86, 0x8e, 0xa4, 0xaa, 0x48, 0xb5, 0xb2, 0x39, 0xee, 0x0d,
+	0x37, 0xab, 0xb0, 0x71, 0x89, 0x63, 0x4f, 0x40, 0xff, 0x84, 0x66, 0x46, 0x35, 0x71, 0x29, 0x5f,
+	0xaf, 0x88, 0x2f, 0xa8, 0x26, 0xd8, 0xaa, 0xd0, 0x7f, 0x21, 0x98, 0xf9, 0xd8, 0xb0, 0x79, 0xdc,
+	0x9a, 0xe5, 0xd1, 0x69, 0x5e, 0x69, 0xa2, 0x0b, 0x85, 0x67, 0xcc, 0x48, 0x41, 0xc7, 0x59, 0x43,
+	0x7d, 0xa8, 0x57, 0xf5, 0xae, 0xb3, 0x04, 0x0d, 0xa0, 0x23, 0x72, 0xcd, 0x32, 0x92, 0x3a, 0xbb,
+	0xd7, 0xde, 0x73, 0xf0, 0x20, 0x15, 0xf1, 0x29, 0xf6, 0x24, 0xb4, 0x07, 0x4d, 0x9a, 0x2a, 0x1a,
+	0x36, 0x56, 0x90, 0x2d, 0x23, 0x3a, 0x81, 0xde, 0x1c, 0x88, 0xae, 0x41, 0x4b, 0x33, 0x9d, 0x52,
+	0x6b, 0x3b, 0xc0, 0xa5, 0x60, 0x9a, 0x56, 0x15, 0x59, 0x46, 0xe4, 0x85, 0x35, 0x1f, 0x60, 0x2f,
+	0xa2, 0x3b, 0xb3, 0xcc, 0x95, 0x81, 0x6e, 0xcc, 0xd9, 0x8a, 0x85, 0x4c, 0xaa, 0xc4, 0x45, 0xbf,
+	0xd6, 0xa1, 0x5d, 0x62, 0xe8, 0x1e, 0x74, 0xc8, 0x74, 0x32, 0x32, 0x69, 0x2c, 0xf3, 0xfd, 0x97,
+	0xea, 0x57, 0xc7, 0x5c, 0x3f, 0x1a, 0x7e, 0x45, 0xd2, 0x82, 0xe2, 0x36, 0x99, 0x4e, 0x0e, 0x35,
+	0x31, 0x3e, 0xe5, 0x92, 0xc5, 0xd4, 0xda, 0x6e, 0xe1, 0x52, 0x40, 0xff, 0x83, 0x35, 0xfb, 0x31,
+	0x92, 0x84, 0x4f, 0xa8, 0x5a, 0x0a, 0xf5, 0xa5, 0x51, 0x62, 0xab, 0xc3, 0xbd, 0x7c, 0x26, 0xa0,
+	0xbb, 0xd0, 0x96, 0x44, 0x33, 0x3e, 0x09, 0x9b, 0xef, 0xd9, 0x7e, 0x96, 0x0a, 0xa2, 0x9d, 0xed,
+	0x92, 0x82, 0xee, 0x43, 0x57, 0xe4, 0x54, 0x12, 0x2d, 0xa4, 0x6d, 0xf2, 0xde, 0xf0, 0x6a, 0x45,
+	0xff, 0xd2, 0x29, 0x70, 0x45, 0x41, 0xb7, 0xa0, 0x61, 0x82, 0x6a, 0x7f, 0x38, 0x28, 0xa3, 0x47,
+	0xbb, 0xb0, 0x1e, 0x17, 0x52, 0x52, 0x1e, 0x5f, 0x8c, 0x62, 0x91, 0x50, 0xd7, 0xe8, 0x6b, 0x1e,
+	0x7c, 0x2a, 0x12, 0x8a, 0x3e, 0x83, 0x8d, 0x32, 0xc0, 0xb1, 0xa4, 0xe4, 0x34, 0x11, 0x67, 0xdc,
+	0xf6, 0xfb, 0x62, 0x2f, 0xb1, 0x98, 0x1e, 0x78, 0x35, 0xee, 0xe7, 0x0b, 0x72, 0x14, 0x01, 0xcc,
+	0x2c, 0x9b, 0x34, 0x4e, 0xcd, 0x87, 0x6b, 0xab, 0x52, 0x30, 0x9c, 0x59, 0xd8, 0x8b, 0x9c, 0xba,
+	0xe7, 0xbc, 0x85, 0xde, 0x5c, 0x36, 0x4d, 0x90, 0x19, 0xe3, 0xab, 0x2a, 0x67, 0xf4, 0x96, 0x46,
+	0xce, 0xc3, 0xfa, 0x2a, 0x1a, 0x39, 0x8f, 0x7e, 0xa8, 0x43, 0x7f, 0x31, 0x0e, 0xf4, 0x29, 0x6c,
+	0xaa, 0x42, 0x4e, 0xe8, 0x28, 0x2b, 0x52, 0xcd, 0xf2, 0x94, 0x51, 0xb9, 0x64, 0x6d, 0xae, 0x56,
+	0x1b, 0x96, 0xfc, 0xa2, 0xe2, 0xa2, 0xc7, 0xb0, 0x96, 0x31, 0xce, 0xb2, 0x22, 0x1b, 0xbd, 0x23,
+	0x92, 0xae, 0x72, 0xa1, 0xe7, 0x88, 0xcf, 0x88, 0xa4, 0xcb, 0x65, 0x69, 0x5c, 0x52, 0x96, 0x7d,
+	0xe8, 0x26, 0x4c, 0x69, 0xc2, 0x63, 0x1a, 0x36, 0x3f, 0x7c, 0x70, 0x45, 0xb2, 0x3f, 0x28, 0x4c,
+	0x3b, 0x09, 0x1e, 0xb6, 0x56, 0xfd, 0xc0, 0x91, 0xa2, 0xdf, 0xea, 0xd0, 0xf5, 0xbd, 0x65, 0x5e,
+	0xb3, 0xb1, 0x24, 0x3c, 0x3e, 0xf1, 0x03, 0x20, 0xc0, 0xdd, 0x12, 0x38, 0x4e, 0xcc, 0x78, 0x29,
+	0x64, 0xea, 0xee, 0xa4, 0xf9, 0x34, 0x05, 0x64, 0x19, 0x99, 0x78, 0xd7, 0x4b, 0xc1, 0xbc, 0x59,
+	0x8a, 0x69, 0xef, 0xef, 0xec, 0xcd, 0x7a, 0xce, 0xf8, 0x29, 0xb6, 0x2a, 0x74, 0x07, 0x36, 0xc7,
+	0x24, 0x3e, 0x9d, 0x48, 0x51, 0xf0, 0x64, 0x14, 0x8b, 0xd4, 0x35, 0x7c, 0x80, 0x37, 0x66, 0xf8,
+	0x53, 0x03, 0xa3, 0x9b, 0xd0, 0x53, 0x27, 0x42, 0xea, 0x51, 0xf9, 0x52, 0xb4, 0x2d, 0x0b, 0x2c,
+	0xf4, 0xda, 0x20, 0xe8, 0x21, 0x80, 0xd2, 0x42, 0xd2, 0x51, 0x21, 0x53, 0x65, 0x7b, 0xbb, 0x37,
+	0x44, 0x95, 0xd1, 0x57, 0x46, 0xf5, 0x06, 0x3f, 0x57, 0x38, 0xb0, 0xac, 0x37, 0x32, 0xf5, 0x0f,
+	0x5e, 0xb7, 0x7a, 0xf0, 0xfe, 0x0e, 0xa0, 0xe9, 0xb9, 0x76, 0x8e, 0x94, 0xaf, 0x78, 0x60, 0x90,
+	0xd2, 0x85, 0xea, 0x99, 0x82, 0xf9, 0x67, 0xea, 0xaf, 0xd0, 0x16, 0x72, 0x62, 0x12, 0xd5, 0x2b,
+	0x61, 0x21, 0x27, 0xc7, 0x09, 0xda, 0x85, 0x56, 0x7e, 0x22, 0x38, 0x0d, 0xd7, 0x2e, 0x0b, 0xbf,
+	0xd4, 0x45, 0x0f, 0xa0, 0x69, 0xc4, 0xc5, 0x1b, 0x10, 0xb8, 0x1b, 0x80, 0x10, 0x34, 0x8d, 0x71,
+	0x97, 0x69, 0xfb, 0x1d, 0x7d, 0x0b, 0x41, 0x15, 0x0a, 0xda, 0x85, 0x06, 0x13, 0x7e, 0x7a, 0x5c,
+	0x5d, 0x8a, 0x15, 0x1b, 0x2d, 0xba, 0x0b, 0x1d, 0xc2, 0x13, 0x29, 0x58, 0x12, 0xd6, 0x3f, 0x44,
+	0xf4, 0x8c, 0xe8, 0x1e, 0x74, 0x3d, 0x38, 0x37, 0x0e, 0x02, 0x9b, 0x9d, 0xa5, 0xba, 0x47, 0xdf,
+	0xd7, 0xa1, 0x69, 0x26, 0xd0, 0x42, 0x7b, 0xd6, 0xfe, 0x4c, 0x7b, 0xfe, 0x0b, 0x9a, 0x76, 0x48,
+	0xaf, 0xb8, 0x24, 0x96, 0x80, 0xae, 0x43, 0x5b, 0x89, 0x42, 0xc6, 0xbe, 0xb7, 0x9c, 0x84, 0xb6,
+	0xa1, 0x7b, 0x46, 0x24, 0x67, 0x7c, 0x52, 0x2e, 0x0d, 0x01, 0xae, 0x64, 0xf4, 0x0f, 0x00, 0x7a,
+	0x9e, 0x53, 0xc9, 0x32, 0xca, 0xb5, 0xeb, 0xa7, 0x39, 0xc4, 0x0c, 0x96, 0x29, 0x91, 0x8c, 0x70,
+	0xed, 0xda, 0xc8, 0x8b, 0xe8, 0x09, 0xf4, 0x0a, 0xae, 0x8a, 0x3c, 0x17, 0x52, 0x53, 0xb3, 0x09,
+	0xac, 0x9c, 0xa2, 0xf3, 0xdc, 0xe8, 0x2d, 0xf4, 0x17, 0xd5, 0xa6, 0x7c, 0x9c, 0x64, 0xbe, 0xa6,
+	0xf6, 0xdb, 0x86, 0x63, 0xb5, 0x2e, 0x8d, 0x4e, 0x32, 0x9d, 0x97, 0x12, 0x6d, 0xdf, 0x80, 0xac,
+	0x9c, 0x2a, 0x2d, 0x1c, 0x38, 0xe4, 0x85, 0x8a, 0x7e, 0xae, 0x41, 0xbf, 0x5a, 0x20, 0x0e, 0xa7,
+	0x26, 0x88, 0xff, 0x98, 0x19, 0x68, 0xe6, 0x9a, 0xaf, 0x7f, 0xb8, 0xe4, 0x66, 0x39, 0xf7, 0xd4,
+	0xd1, 0x15, 0xec, 0xa9, 0x68, 0x77, 0xc5, 0x1e, 0x71, 0x74, 0xc5, 0x6d, 0x12, 0x8f, 0x67, 0x73,
+	0xbf, 0xe1, 0x96, 0x28, 0xcf, 0x5b, 0xda, 0x62, 0xcc, 0xe1, 0x8e, 0x8c, 0x6e, 0x43, 0x8b, 0x4a,
+	0x29, 0xa4, 0xbb, 0xf1, 0xb3, 0x2d, 0xee, 0xd0, 0xa0, 0x47, 0x57, 0x70, 0xa9, 0x3e, 0xe8, 0x40,
+	0x8b, 0x9a, 0x18, 0xa2, 0xaf, 0x61, 0xe3, 0x3d, 0x5f, 0x4d, 0x5d, 0xfd, 0x6e, 0xe2, 0x1f, 0x1e,
+	0x2f, 0xcf, 0x8f, 0xfd, 0xfa, 0x1f, 0x8c, 0xfd, 0x29, 0xb4, 0xac, 0xd1, 0xb9, 0x84, 0xd7, 0x16,
+	0x12, 0x8e, 0xa0, 0x69, 0x1f, 0x5b, 0x93, 0x88, 0x06, 0xb6, 0xdf, 0xe6, 0x16, 0x96, 0xfe, 0xbb,
+	0x67, 0xcc, 0x0a, 0x66, 0x72, 0xbf, 0x63, 0x34, 0x4d, 0xfc, 0x72, 0x3a, 0x37, 0x0d, 0x0c, 0x6c,
+	0xcd, 0x60, 0x47, 0x89, 0x3e, 0x01, 0x98, 0xa1, 0xe6, 0x40, 0x8b, 0xfb, 0x6b, 0x6d, 0x05, 0xd3,
+	0x7e, 0x19, 0x55, 0x8a, 0x4c, 0x4a, 0xeb, 0x01, 0xf6, 0x62, 0xb4, 0x01, 0xeb, 0x47, 0x94, 0xa4,
+	0xfa, 0xc4, 0x2d, 0xe2, 0xd1, 0x0e, 0xf4, 0x3d, 0xe0, 0x96, 0xc6, 0x3e, 0xd4, 0xc5, 0xa9, 0x3d,
+	0xaf, 0x8b, 0xeb, 0xe2, 0x74, 0xf8, 0x53, 0x0d, 0x9a, 0xaf, 0xc9, 0x39, 0x43, 0x07, 0x10, 0x54,
+	0xc5, 0x41, 0x37, 0x2e, 0x2b, 0x98, 0x3d, 0x72, 0x7b, 0x45, 0x2d, 0xd1, 0x17, 0xb0, 0x51, 0x81,
+	0xaf, 0xb4, 0xa4, 0x24, 0x5b, 0x75, 0xd2, 0xd6, 0xb2, 0xca, 0xb6, 0xe6, 0x83, 0x1a, 0x7a, 0x02,
+	0xed, 0xd2, 0x6f, 0x74, 0xbd, 0x22, 0x2d, 0x44, 0xb6, 0xbd, 0xb5, 0x84, 0x97, 0x3e, 0x1c, 0x7c,
+	0xf4, 0xcd, 0xff, 0x27, 0x4c, 0x9f, 0x14, 0xe3, 0x41, 0x2c, 0xb2, 0x7d, 0x3e, 0x2e, 0x64, 0xc1,
+	0xc5, 0x94, 0xec, 0x9b, 0x7f, 0x92, 0xee, 0x9b, 0xa1, 0x40, 0x79, 0x72, 0x5f, 0x91, 0x2c, 0x4f,
+	0xe9, 0xbe, 0x92, 0xb1, 0xc5, 0xed, 0x9f, 0x7c, 0x3c, 0x6e, 0xdb, 0x25, 0xff, 0xd1, 0xef, 0x03,
+	0x00, 0x7c, 0x7d, 0x0f, 0xa4, 0x52, 0x0d, 0x00, 0x00,
+}